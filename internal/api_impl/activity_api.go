@@ -2,14 +2,22 @@ package api_impl
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"net/url"
+	"ping-badge-be/internal/geo"
+	"ping-badge-be/internal/listquery"
 	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
 	"ping-badge-be/internal/repository"
 	"ping-badge-be/internal/service"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type ActivityAPI struct {
@@ -17,6 +25,9 @@ type ActivityAPI struct {
 	organizationRepo     *repository.OrganizationRepository
 	badgeRepo            repository.BadgeRepository
 	participationService service.ActivityParticipationService
+	orgAdminService      service.OrganizationAdminService
+	templateService      service.ActivityTemplateService
+	coHostService        service.ActivityCoHostService
 }
 
 func NewActivityAPI(
@@ -24,26 +35,116 @@ func NewActivityAPI(
 	organizationRepo *repository.OrganizationRepository,
 	badgeRepo repository.BadgeRepository,
 	participationService service.ActivityParticipationService,
+	orgAdminService service.OrganizationAdminService,
+	templateService service.ActivityTemplateService,
+	coHostService service.ActivityCoHostService,
 ) *ActivityAPI {
 	return &ActivityAPI{
 		service:              service,
 		organizationRepo:     organizationRepo,
 		badgeRepo:            badgeRepo,
 		participationService: participationService,
+		orgAdminService:      orgAdminService,
+		templateService:      templateService,
+		coHostService:        coHostService,
 	}
 }
 
+// Dates are RFC 3339 timestamps with an offset, e.g. 2025-06-01T09:00:00+07:00.
+// Timezone is the IANA zone the activity is scheduled in and defaults to UTC.
+// RecurrenceRule is an RRULE such as "FREQ=WEEKLY;BYDAY=TU;COUNT=10" repeating
+// the first session given by the start and end dates.
 type CreateActivityRequest struct {
-	ActivityName string `json:"activity_name" binding:"required"`
-	Description  string `json:"description"`
-	StartDate    string `json:"start_date"`
+	ActivityName         string   `json:"activity_name" binding:"required"`
+	Description          string   `json:"description"`
+	StartDate            string   `json:"start_date"`
+	EndDate              string   `json:"end_date"`
+	Timezone             string   `json:"timezone"`
+	Location             string   `json:"location"`
+	BadgeDefID           string   `json:"badge_def_id"`
+	RecurrenceRule       string   `json:"recurrence_rule"`
+	RecurrenceExceptions []string `json:"recurrence_exceptions"`
+	RequiredSessions     *int     `json:"required_sessions"`
+	Capacity             *int     `json:"capacity"`
+	RegistrationOpensAt  string   `json:"registration_opens_at"`
+	RegistrationClosesAt string   `json:"registration_closes_at"`
+	Latitude             *float64 `json:"latitude"`
+	Longitude            *float64 `json:"longitude"`
+	GeofenceRadiusMeters *int     `json:"geofence_radius_meters"`
+	GeofencePolicy       string   `json:"geofence_policy"`
+	Category             string   `json:"category"`
+	Tags                 []string `json:"tags"`
+}
+
+// UpdateActivityRequest is a partial update: omitted fields are unchanged. An
+// empty badge_def_id, category or registration time clears it and a zero
+// capacity, required_sessions or geofence_radius_meters removes the limit.
+type UpdateActivityRequest struct {
+	ActivityName         *string   `json:"activity_name"`
+	Description          *string   `json:"description"`
+	StartDate            *string   `json:"start_date"`
+	EndDate              *string   `json:"end_date"`
+	Timezone             *string   `json:"timezone"`
+	Location             *string   `json:"location"`
+	BadgeDefID           *string   `json:"badge_def_id"`
+	RecurrenceRule       *string   `json:"recurrence_rule"`
+	RecurrenceExceptions *[]string `json:"recurrence_exceptions"`
+	RequiredSessions     *int      `json:"required_sessions"`
+	Capacity             *int      `json:"capacity"`
+	RegistrationOpensAt  *string   `json:"registration_opens_at"`
+	RegistrationClosesAt *string   `json:"registration_closes_at"`
+	Latitude             *float64  `json:"latitude"`
+	Longitude            *float64  `json:"longitude"`
+	GeofenceRadiusMeters *int      `json:"geofence_radius_meters"`
+	GeofencePolicy       *string   `json:"geofence_policy"`
+	Category             *string   `json:"category"`
+	Tags                 *[]string `json:"tags"`
+}
+
+// CancelActivityRequest cancels an activity. WithdrawParticipants also
+// withdraws everyone who has not checked in yet.
+type CancelActivityRequest struct {
+	Reason               string `json:"reason"`
+	WithdrawParticipants bool   `json:"withdraw_participants"`
+}
+
+// CoHostRequest adds or updates a co-host. permissions lists
+// manage_participations and edit_activity; badge_def_id is a badge of the
+// co-host issued alongside the activity's badge.
+type CoHostRequest struct {
+	OrgID       string   `json:"org_id" binding:"required"`
+	Permissions []string `json:"permissions"`
+	BadgeDefID  string   `json:"badge_def_id"`
+}
+
+type SaveTemplateRequest struct {
+	TemplateName string `json:"template_name" binding:"required"`
+}
+
+// NewScheduleRequest places an activity created from a template or cloned
+// from another. end_date defaults to start_date plus the source's length.
+type NewScheduleRequest struct {
+	StartDate    string `json:"start_date" binding:"required"`
 	EndDate      string `json:"end_date"`
-	Location     string `json:"location"`
-	BadgeDefID   string `json:"badge_def_id"`
+	ActivityName string `json:"activity_name"`
+}
+
+// RescheduleActivityRequest moves an activity; omitted fields are unchanged.
+type RescheduleActivityRequest struct {
+	StartDate *string `json:"start_date"`
+	EndDate   *string `json:"end_date"`
+	Timezone  *string `json:"timezone"`
+	Reason    string  `json:"reason"`
 }
 
+// ListActivities lists activities, or with ?user_id= those the user joined.
+// Discovery filters: q (full-text search), category, tags (comma-separated,
+// all required), from/to, when=upcoming|past, has_badge=true|false,
+// verified=true, status=active|cancelled|archived (archived activities are
+// hidden otherwise), and lat/lng with radius_km (default 25) for nearby
+// activities. sort is relevance, date, -date or distance, or any order
+// filter[...] and sort allow under repository.ActivityListSchema.
 func (api *ActivityAPI) ListActivities(c *gin.Context) {
-	orgID := c.Query("org_id")
 	userID := c.Query("user_id")
 
 	// If user_id is provided, return activities that the user has participated in
@@ -54,54 +155,41 @@ func (api *ActivityAPI) ListActivities(c *gin.Context) {
 			return
 		}
 
-		page := c.DefaultQuery("page", "1")
-		limit := c.DefaultQuery("limit", "10")
-		pageInt := 1
-		limitInt := 10
-		if p, err := strconv.Atoi(page); err == nil && p > 0 {
-			pageInt = p
+		page, ok := parsePagination(c)
+		if !ok {
+			return
 		}
-		if l, err := strconv.Atoi(limit); err == nil && l > 0 && l <= 100 {
-			limitInt = l
+		criteria, ok := parseListQuery(c, withoutActivitySort(c.Request.URL.Query()), repository.ActivityListSchema)
+		if !ok {
+			return
 		}
-		offset := (pageInt - 1) * limitInt
-
-		activities, err := api.service.ListActivitiesByUser(context.Background(), userUUID, offset, limitInt)
+		activities, total, err := api.service.ListActivitiesByUser(context.Background(), userUUID, criteria, page)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user activities"})
 			return
 		}
-		c.JSON(http.StatusOK, activities)
+		writePage(c, page, activities, total, activityCursor)
 		return
 	}
 
-	// Original logic for listing all activities by organization
-	var orgUUID *uuid.UUID
-	if orgID != "" {
-		parsed, err := uuid.Parse(orgID)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
-			return
-		}
-		orgUUID = &parsed
-	}
-	page := c.DefaultQuery("page", "1")
-	limit := c.DefaultQuery("limit", "10")
-	pageInt := 1
-	limitInt := 10
-	if p, err := strconv.Atoi(page); err == nil && p > 0 {
-		pageInt = p
+	filter, ok := parseActivityFilter(c)
+	if !ok {
+		return
 	}
-	if l, err := strconv.Atoi(limit); err == nil && l > 0 && l <= 100 {
-		limitInt = l
+	page, ok := parsePagination(c)
+	if !ok {
+		return
 	}
-	offset := (pageInt - 1) * limitInt
-	activities, err := api.service.ListActivities(context.Background(), orgUUID, offset, limitInt)
+	activities, total, err := api.service.ListActivities(context.Background(), filter, page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch activities"})
 		return
 	}
-	c.JSON(http.StatusOK, activities)
+	writePage(c, page, activities, total, activityCursor)
+}
+
+func activityCursor(activity model.Activity) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: activity.CreatedAt, ID: activity.ActivityID}
 }
 
 func (api *ActivityAPI) GetActivity(c *gin.Context) {
@@ -132,7 +220,13 @@ func (api *ActivityAPI) GetActivity(c *gin.Context) {
 	// Fetch Participations
 	var participations []model.ActivityParticipation
 	if api.participationService != nil {
-		participations, _ = api.participationService.ListParticipations(context.Background(), &activity.ActivityID, nil, nil, 0, 100)
+		participations, _, _ = api.participationService.ListParticipations(context.Background(), repository.ParticipationFilter{ActivityID: &activity.ActivityID}, pagination.Params{Limit: 100})
+	}
+
+	// Fetch Co-hosts
+	var coHosts []model.ActivityCoHost
+	if api.coHostService != nil {
+		coHosts, _ = api.coHostService.ListCoHosts(context.Background(), activity.ActivityID)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -140,6 +234,7 @@ func (api *ActivityAPI) GetActivity(c *gin.Context) {
 		"organization":   organization,
 		"badge":          badge,
 		"participations": participations,
+		"co_hosts":       coHosts,
 	})
 }
 
@@ -150,6 +245,9 @@ func (api *ActivityAPI) CreateActivity(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
 		return
 	}
+	if !requireOrgStaff(c, api.orgAdminService, orgUUID) {
+		return
+	}
 	var req CreateActivityRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -161,9 +259,66 @@ func (api *ActivityAPI) CreateActivity(c *gin.Context) {
 		ActivityName: req.ActivityName,
 		Description:  &req.Description,
 		Location:     &req.Location,
-		// You may want to parse StartDate, EndDate, BadgeDefID as needed
+		Timezone:     req.Timezone,
+	}
+	if req.RecurrenceRule != "" {
+		activity.RecurrenceRule = &req.RecurrenceRule
+	}
+	if activity.RecurrenceExceptions, err = parseActivityTimes(req.RecurrenceExceptions); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recurrence_exceptions, expected RFC 3339"})
+		return
+	}
+	if req.RequiredSessions != nil && *req.RequiredSessions > 0 {
+		activity.RequiredSessions = req.RequiredSessions
+	}
+	activity.Capacity = req.Capacity
+	activity.Latitude = req.Latitude
+	activity.Longitude = req.Longitude
+	if req.GeofenceRadiusMeters != nil && *req.GeofenceRadiusMeters > 0 {
+		activity.GeofenceRadiusMeters = req.GeofenceRadiusMeters
+	}
+	activity.GeofencePolicy = req.GeofencePolicy
+	if req.Category != "" {
+		activity.Category = &req.Category
+	}
+	activity.Tags = req.Tags
+	if req.RegistrationOpensAt != "" {
+		if activity.RegistrationOpensAt, err = parseActivityTime(req.RegistrationOpensAt); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid registration_opens_at, expected RFC 3339"})
+			return
+		}
+	}
+	if req.RegistrationClosesAt != "" {
+		if activity.RegistrationClosesAt, err = parseActivityTime(req.RegistrationClosesAt); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid registration_closes_at, expected RFC 3339"})
+			return
+		}
+	}
+	if req.StartDate != "" {
+		if activity.StartDate, err = parseActivityTime(req.StartDate); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date, expected RFC 3339"})
+			return
+		}
+	}
+	if req.EndDate != "" {
+		if activity.EndDate, err = parseActivityTime(req.EndDate); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date, expected RFC 3339"})
+			return
+		}
+	}
+	if req.BadgeDefID != "" {
+		badgeDefID, err := uuid.Parse(req.BadgeDefID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid badge ID"})
+			return
+		}
+		activity.BadgeDefID = &badgeDefID
 	}
 	err = api.service.CreateActivity(context.Background(), activity)
+	if isActivityValidationError(err) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create activity"})
 		return
@@ -171,25 +326,87 @@ func (api *ActivityAPI) CreateActivity(c *gin.Context) {
 	c.JSON(http.StatusCreated, activity)
 }
 
+// UpdateActivity serves both PUT and PATCH with partial update semantics.
 func (api *ActivityAPI) UpdateActivity(c *gin.Context) {
-	activityID := c.Param("id")
-	id, err := uuid.Parse(activityID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity ID"})
+	existing, _, ok := api.staffActivity(c, c.Param("id"), model.CoHostEditActivity)
+	if !ok {
 		return
 	}
-	var req CreateActivityRequest
+	id := existing.ActivityID
+	var err error
+	var req UpdateActivityRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	updates := map[string]interface{}{
-		"activity_name": req.ActivityName,
-		"description":   req.Description,
-		"location":      req.Location,
-		// Add other fields as needed
+	patch := service.ActivityPatch{
+		ActivityName:         req.ActivityName,
+		Description:          req.Description,
+		Location:             req.Location,
+		Timezone:             req.Timezone,
+		RecurrenceRule:       req.RecurrenceRule,
+		RequiredSessions:     req.RequiredSessions,
+		Capacity:             req.Capacity,
+		Latitude:             req.Latitude,
+		Longitude:            req.Longitude,
+		GeofenceRadiusMeters: req.GeofenceRadiusMeters,
+		GeofencePolicy:       req.GeofencePolicy,
+		Category:             req.Category,
+		Tags:                 req.Tags,
+	}
+	if patch.RegistrationOpensAt, err = parseOptionalActivityTime(req.RegistrationOpensAt); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid registration_opens_at, expected RFC 3339"})
+		return
+	}
+	if patch.RegistrationClosesAt, err = parseOptionalActivityTime(req.RegistrationClosesAt); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid registration_closes_at, expected RFC 3339"})
+		return
+	}
+	if req.RecurrenceExceptions != nil {
+		exceptions, err := parseActivityTimes(*req.RecurrenceExceptions)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recurrence_exceptions, expected RFC 3339"})
+			return
+		}
+		patch.RecurrenceExceptions = &exceptions
+	}
+	if req.StartDate != nil {
+		if patch.StartDate, err = parseActivityTime(*req.StartDate); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date, expected RFC 3339"})
+			return
+		}
+	}
+	if req.EndDate != nil {
+		if patch.EndDate, err = parseActivityTime(*req.EndDate); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date, expected RFC 3339"})
+			return
+		}
+	}
+	if req.BadgeDefID != nil {
+		if *req.BadgeDefID == "" {
+			patch.ClearBadge = true
+		} else {
+			badgeDefID, err := uuid.Parse(*req.BadgeDefID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid badge ID"})
+				return
+			}
+			patch.BadgeDefID = &badgeDefID
+		}
+	}
+	activity, err := api.service.UpdateActivity(context.Background(), id, patch)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+		return
+	}
+	if isActivityStateConflict(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if isActivityValidationError(err) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-	activity, err := api.service.UpdateActivity(context.Background(), id, updates)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update activity"})
 		return
@@ -197,17 +414,597 @@ func (api *ActivityAPI) UpdateActivity(c *gin.Context) {
 	c.JSON(http.StatusOK, activity)
 }
 
+// DeleteActivity is reserved to staff of the host organization.
 func (api *ActivityAPI) DeleteActivity(c *gin.Context) {
-	activityID := c.Param("id")
-	id, err := uuid.Parse(activityID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity ID"})
+	activity, _, ok := api.staffActivity(c, c.Param("id"), "")
+	if !ok {
+		return
+	}
+	err := api.service.DeleteActivity(context.Background(), activity.ActivityID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+		return
+	}
+	if errors.Is(err, service.ErrActivityHasCompletions) || errors.Is(err, service.ErrActivityHasParticipants) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 		return
 	}
-	err = api.service.DeleteActivity(context.Background(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete activity"})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Activity deleted successfully"})
 }
+
+// CancelActivity cancels the activity and notifies its participants.
+func (api *ActivityAPI) CancelActivity(c *gin.Context) {
+	activity, userID, ok := api.staffActivity(c, c.Param("activity_id"), model.CoHostEditActivity)
+	if !ok {
+		return
+	}
+	var req CancelActivityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	cancelled, err := api.service.CancelActivity(context.Background(), activity.ActivityID, strings.TrimSpace(req.Reason), req.WithdrawParticipants, service.UserActor(service.ActorStaff, userID))
+	if isActivityStateConflict(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel activity"})
+		return
+	}
+	c.JSON(http.StatusOK, cancelled)
+}
+
+// RescheduleActivity moves the activity and notifies its participants.
+func (api *ActivityAPI) RescheduleActivity(c *gin.Context) {
+	activity, _, ok := api.staffActivity(c, c.Param("activity_id"), model.CoHostEditActivity)
+	if !ok {
+		return
+	}
+	var req RescheduleActivityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	patch := service.ActivityPatch{Timezone: req.Timezone}
+	var err error
+	if req.StartDate != nil {
+		if patch.StartDate, err = parseActivityTime(*req.StartDate); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date, expected RFC 3339"})
+			return
+		}
+	}
+	if req.EndDate != nil {
+		if patch.EndDate, err = parseActivityTime(*req.EndDate); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date, expected RFC 3339"})
+			return
+		}
+	}
+	rescheduled, err := api.service.RescheduleActivity(context.Background(), activity.ActivityID, patch, strings.TrimSpace(req.Reason))
+	if isActivityValidationError(err) || errors.Is(err, service.ErrNoScheduleChange) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if isActivityStateConflict(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reschedule activity"})
+		return
+	}
+	c.JSON(http.StatusOK, rescheduled)
+}
+
+// ArchiveActivity hides the activity from listings, keeping its history.
+func (api *ActivityAPI) ArchiveActivity(c *gin.Context) {
+	activity, _, ok := api.staffActivity(c, c.Param("activity_id"), "")
+	if !ok {
+		return
+	}
+	archived, err := api.service.ArchiveActivity(context.Background(), activity.ActivityID)
+	if errors.Is(err, service.ErrActivityNotEnded) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to archive activity"})
+		return
+	}
+	c.JSON(http.StatusOK, archived)
+}
+
+// SaveAsTemplate stores the activity's settings as a template of its
+// organization.
+func (api *ActivityAPI) SaveAsTemplate(c *gin.Context) {
+	activity, userID, ok := api.staffActivity(c, c.Param("activity_id"), "")
+	if !ok {
+		return
+	}
+	var req SaveTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	template, err := api.templateService.SaveAsTemplate(context.Background(), activity.ActivityID, req.TemplateName, userID)
+	if errors.Is(err, service.ErrTemplateNameRequired) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save template"})
+		return
+	}
+	c.JSON(http.StatusCreated, template)
+}
+
+// CloneActivity copies the activity to new dates.
+func (api *ActivityAPI) CloneActivity(c *gin.Context) {
+	activity, _, ok := api.staffActivity(c, c.Param("activity_id"), "")
+	if !ok {
+		return
+	}
+	schedule, ok := bindNewSchedule(c)
+	if !ok {
+		return
+	}
+	clone, err := api.templateService.CloneActivity(context.Background(), activity.ActivityID, schedule)
+	if isActivityValidationError(err) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clone activity"})
+		return
+	}
+	c.JSON(http.StatusCreated, clone)
+}
+
+func (api *ActivityAPI) ListCoHosts(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity ID"})
+		return
+	}
+	coHosts, err := api.coHostService.ListCoHosts(context.Background(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list co-hosts"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"co_hosts": coHosts})
+}
+
+// SaveCoHost adds or updates a co-host. Only the host's staff manage
+// co-hosts, and choosing the co-host's badge also needs staff rights there.
+// The co-host is pending until its staff accept, unless the caller is staff
+// of both organizations.
+func (api *ActivityAPI) SaveCoHost(c *gin.Context) {
+	activity, userID, ok := api.staffActivity(c, c.Param("activity_id"), "")
+	if !ok {
+		return
+	}
+	var req CoHostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	orgID, err := uuid.Parse(req.OrgID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+	host := &model.ActivityCoHost{
+		ActivityID:  activity.ActivityID,
+		OrgID:       orgID,
+		Permissions: req.Permissions,
+		AddedBy:     &userID,
+	}
+	if req.BadgeDefID != "" {
+		badgeDefID, err := uuid.Parse(req.BadgeDefID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid badge ID"})
+			return
+		}
+		if !requireOrgStaff(c, api.orgAdminService, orgID) {
+			return
+		}
+		host.BadgeDefID = &badgeDefID
+	}
+	if staff, err := api.orgAdminService.IsStaff(context.Background(), orgID, userID); err == nil && staff {
+		now := time.Now()
+		host.AcceptedAt = &now
+	}
+	err = api.coHostService.SaveCoHost(context.Background(), host)
+	switch {
+	case errors.Is(err, service.ErrCoHostIsHost),
+		errors.Is(err, service.ErrCoHostNotFound),
+		errors.Is(err, service.ErrInvalidCoHostPermission),
+		isActivityValidationError(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save co-host"})
+		return
+	}
+	c.JSON(http.StatusOK, host)
+}
+
+// RemoveCoHost removes a co-host. The host's staff may remove any co-host and
+// a co-host's staff may withdraw their own organization.
+func (api *ActivityAPI) RemoveCoHost(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity ID"})
+		return
+	}
+	orgID, err := uuid.Parse(c.Param("org_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	activity, err := api.service.GetActivity(context.Background(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+		return
+	}
+	if staff, err := api.orgAdminService.IsStaff(context.Background(), orgID, userID); err != nil || !staff {
+		if !requireActivityStaff(c, api.orgAdminService, activity, "") {
+			return
+		}
+	}
+	err = api.coHostService.RemoveCoHost(context.Background(), id, orgID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Co-host not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove co-host"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Co-host removed successfully"})
+}
+
+// AcceptCoHost lets the co-host's staff agree to co-host the activity.
+func (api *ActivityAPI) AcceptCoHost(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("activity_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity ID"})
+		return
+	}
+	orgID, err := uuid.Parse(c.Param("org_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+	if !requireOrgStaff(c, api.orgAdminService, orgID) {
+		return
+	}
+	host, err := api.coHostService.AcceptCoHost(context.Background(), id, orgID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Co-host not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept co-host"})
+		return
+	}
+	c.JSON(http.StatusOK, host)
+}
+
+// ListCoHostInvitations lists the activities waiting for the organization to
+// accept co-hosting them.
+func (api *ActivityAPI) ListCoHostInvitations(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+	if !requireOrgStaff(c, api.orgAdminService, orgID) {
+		return
+	}
+	invitations, err := api.coHostService.ListInvitations(context.Background(), orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list co-host invitations"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+}
+
+func (api *ActivityAPI) ListTemplates(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+	if !requireOrgStaff(c, api.orgAdminService, orgID) {
+		return
+	}
+	templates, err := api.templateService.ListTemplates(context.Background(), orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list templates"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"templates": templates})
+}
+
+func (api *ActivityAPI) GetTemplate(c *gin.Context) {
+	template, ok := api.staffTemplate(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+func (api *ActivityAPI) DeleteTemplate(c *gin.Context) {
+	template, ok := api.staffTemplate(c)
+	if !ok {
+		return
+	}
+	if err := api.templateService.DeleteTemplate(context.Background(), template.TemplateID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete template"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Template deleted successfully"})
+}
+
+// CreateFromTemplate creates an activity from the template at new dates.
+func (api *ActivityAPI) CreateFromTemplate(c *gin.Context) {
+	template, ok := api.staffTemplate(c)
+	if !ok {
+		return
+	}
+	schedule, ok := bindNewSchedule(c)
+	if !ok {
+		return
+	}
+	activity, err := api.templateService.CreateFromTemplate(context.Background(), template.TemplateID, schedule)
+	if isActivityValidationError(err) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create activity"})
+		return
+	}
+	c.JSON(http.StatusCreated, activity)
+}
+
+// staffTemplate loads the template named by the id parameter and checks that
+// the caller is staff of its organization.
+func (api *ActivityAPI) staffTemplate(c *gin.Context) (*model.ActivityTemplate, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return nil, false
+	}
+	template, err := api.templateService.GetTemplate(context.Background(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+		return nil, false
+	}
+	if !requireOrgStaff(c, api.orgAdminService, template.OrgID) {
+		return nil, false
+	}
+	return template, true
+}
+
+func bindNewSchedule(c *gin.Context) (service.NewSchedule, bool) {
+	var req NewScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return service.NewSchedule{}, false
+	}
+	start, err := parseActivityTime(req.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date, expected RFC 3339"})
+		return service.NewSchedule{}, false
+	}
+	schedule := service.NewSchedule{StartDate: *start, ActivityName: strings.TrimSpace(req.ActivityName)}
+	if req.EndDate != "" {
+		if schedule.EndDate, err = parseActivityTime(req.EndDate); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date, expected RFC 3339"})
+			return service.NewSchedule{}, false
+		}
+	}
+	return schedule, true
+}
+
+// staffActivity loads the activity and checks that the caller is staff of its
+// host, or of a co-host granted permission, writing the error response
+// otherwise. An empty permission admits host staff only.
+func (api *ActivityAPI) staffActivity(c *gin.Context, rawID, permission string) (*model.Activity, uuid.UUID, bool) {
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity ID"})
+		return nil, uuid.Nil, false
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return nil, uuid.Nil, false
+	}
+	activity, err := api.service.GetActivity(context.Background(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+		return nil, uuid.Nil, false
+	}
+	if !requireActivityStaff(c, api.orgAdminService, activity, permission) {
+		return nil, uuid.Nil, false
+	}
+	return activity, userID, true
+}
+
+// defaultSearchRadiusKm is the "near me" radius when radius_km is not given.
+const defaultSearchRadiusKm = 25
+
+func parseActivityFilter(c *gin.Context) (repository.ActivityFilter, bool) {
+	var filter repository.ActivityFilter
+	fail := func(msg string) (repository.ActivityFilter, bool) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return filter, false
+	}
+	if orgID := c.Query("org_id"); orgID != "" {
+		parsed, err := uuid.Parse(orgID)
+		if err != nil {
+			return fail("Invalid organization ID")
+		}
+		filter.OrgID = &parsed
+	}
+	if from := c.Query("from"); from != "" {
+		parsed, err := parseActivityTime(from)
+		if err != nil {
+			return fail("Invalid from date, expected RFC 3339")
+		}
+		filter.From = parsed
+	}
+	if to := c.Query("to"); to != "" {
+		parsed, err := parseActivityTime(to)
+		if err != nil {
+			return fail("Invalid to date, expected RFC 3339")
+		}
+		filter.To = parsed
+	}
+	filter.Query = strings.TrimSpace(c.Query("q"))
+	filter.Category = strings.TrimSpace(c.Query("category"))
+	if tags := c.Query("tags"); tags != "" {
+		for _, tag := range strings.Split(tags, ",") {
+			if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+				filter.Tags = append(filter.Tags, tag)
+			}
+		}
+	}
+	switch c.Query("when") {
+	case "":
+	case "upcoming":
+		upcoming := true
+		filter.Upcoming = &upcoming
+	case "past":
+		upcoming := false
+		filter.Upcoming = &upcoming
+	default:
+		return fail("when must be upcoming or past")
+	}
+	if hasBadge := c.Query("has_badge"); hasBadge != "" {
+		parsed, err := strconv.ParseBool(hasBadge)
+		if err != nil {
+			return fail("has_badge must be true or false")
+		}
+		filter.HasBadge = &parsed
+	}
+	filter.VerifiedOnly = c.Query("verified") == "true"
+
+	lat, lng := c.Query("lat"), c.Query("lng")
+	if lat != "" || lng != "" {
+		latitude, errLat := strconv.ParseFloat(lat, 64)
+		longitude, errLng := strconv.ParseFloat(lng, 64)
+		point := geo.Point{Latitude: latitude, Longitude: longitude}
+		if errLat != nil || errLng != nil || !point.Valid() {
+			return fail("Invalid lat or lng")
+		}
+		radiusKm := float64(defaultSearchRadiusKm)
+		if radius := c.Query("radius_km"); radius != "" {
+			parsed, err := strconv.ParseFloat(radius, 64)
+			if err != nil || parsed <= 0 || parsed > 20000 {
+				return fail("Invalid radius_km")
+			}
+			radiusKm = parsed
+		}
+		filter.Near = &point
+		filter.RadiusMeters = radiusKm * 1000
+	}
+
+	switch status := strings.ToUpper(c.Query("status")); status {
+	case "", model.ActivityActive, model.ActivityCancelled, model.ActivityArchived:
+		filter.Status = status
+	default:
+		return fail("status must be active, cancelled or archived")
+	}
+
+	switch sort := c.Query("sort"); sort {
+	case "", repository.SortRelevance, repository.SortDate, repository.SortDateDesc:
+		filter.Sort = sort
+	case repository.SortDistance:
+		if filter.Near == nil {
+			return fail("sort=distance needs lat and lng")
+		}
+		filter.Sort = sort
+	}
+	criteria, err := listquery.Parse(withoutActivitySort(c.Request.URL.Query()), repository.ActivityListSchema)
+	if err != nil {
+		return fail(err.Error())
+	}
+	filter.Criteria = criteria
+	return filter, true
+}
+
+// withoutActivitySort drops the activity sort keywords from query, leaving
+// generic sort orders to listquery.
+func withoutActivitySort(query url.Values) url.Values {
+	switch query.Get("sort") {
+	case repository.SortRelevance, repository.SortDate, repository.SortDateDesc, repository.SortDistance:
+		query.Del("sort")
+	}
+	return query
+}
+
+func parseActivityTime(value string) (*time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	t = t.UTC()
+	return &t, nil
+}
+
+// parseOptionalActivityTime parses a patch value; an empty string yields the
+// zero time, which clears the field.
+func parseOptionalActivityTime(value *string) (*time.Time, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if *value == "" {
+		return &time.Time{}, nil
+	}
+	return parseActivityTime(*value)
+}
+
+func parseActivityTimes(values []string) ([]time.Time, error) {
+	times := make([]time.Time, 0, len(values))
+	for _, value := range values {
+		t, err := parseActivityTime(value)
+		if err != nil {
+			return nil, err
+		}
+		times = append(times, *t)
+	}
+	return times, nil
+}
+
+func isActivityValidationError(err error) bool {
+	return errors.Is(err, service.ErrInvalidTimezone) ||
+		errors.Is(err, service.ErrInvalidRecurrence) ||
+		errors.Is(err, service.ErrInvalidRequiredCount) ||
+		errors.Is(err, service.ErrInvalidCapacity) ||
+		errors.Is(err, service.ErrInvalidRegistration) ||
+		errors.Is(err, service.ErrInvalidCoordinates) ||
+		errors.Is(err, service.ErrInvalidGeofence) ||
+		errors.Is(err, service.ErrInvalidGeofencePolicy) ||
+		errors.Is(err, service.ErrInvalidCategory) ||
+		errors.Is(err, service.ErrInvalidTags) ||
+		errors.Is(err, service.ErrEndBeforeStart) ||
+		errors.Is(err, service.ErrBadgeNotFound) ||
+		errors.Is(err, service.ErrBadgeNotInOrganization) ||
+		errors.Is(err, service.ErrBadgeInactive)
+}
+
+func isActivityStateConflict(err error) bool {
+	return errors.Is(err, service.ErrActivityCancelled) ||
+		errors.Is(err, service.ErrActivityArchived)
+}
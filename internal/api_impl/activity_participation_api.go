@@ -1,9 +1,17 @@
 package api_impl
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"ping-badge-be/internal/constant"
+	"ping-badge-be/internal/listquery"
 	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
+	"ping-badge-be/internal/repository"
+	"ping-badge-be/internal/roster"
 	"ping-badge-be/internal/service"
 	"strconv"
 	"strings"
@@ -11,16 +19,26 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type ActivityParticipationAPI struct {
-	service service.ActivityParticipationService
+	service         service.ActivityParticipationService
+	activityService service.ActivityService
+	orgAdminService service.OrganizationAdminService
+	evidenceService service.EvidenceService
+	storageService  service.StorageService
+	rosterService   service.RosterService
+	bulkService     service.BulkStatusService
 }
 
-func NewActivityParticipationAPI(service service.ActivityParticipationService) *ActivityParticipationAPI {
-	return &ActivityParticipationAPI{service: service}
+func NewActivityParticipationAPI(service service.ActivityParticipationService, activityService service.ActivityService, orgAdminService service.OrganizationAdminService, evidenceService service.EvidenceService, storageService service.StorageService, rosterService service.RosterService, bulkService service.BulkStatusService) *ActivityParticipationAPI {
+	return &ActivityParticipationAPI{service: service, activityService: activityService, orgAdminService: orgAdminService, evidenceService: evidenceService, storageService: storageService, rosterService: rosterService, bulkService: bulkService}
 }
 
+// maxRosterUploadBytes caps the size of an imported roster file.
+const maxRosterUploadBytes = 5 << 20
+
 type CreateParticipationRequest struct {
 	ActivityID              string  `json:"activity_id" binding:"required"`
 	UserID                  string  `json:"user_id" binding:"required"`
@@ -29,48 +47,115 @@ type CreateParticipationRequest struct {
 	IssuedBadgeID           *string `json:"issued_badge_id"`
 }
 
+// ListParticipations returns a page of participations. They can be filtered
+// by activity, user, host organization, a comma-separated set of statuses,
+// creation date (from, to) and has_evidence, sorted with sort=created_at,
+// -created_at, status or -status, and embed user and activity summaries with
+// include=user,activity.
 func (api *ActivityParticipationAPI) ListParticipations(c *gin.Context) {
-	activityID := c.Query("activity_id")
-	userID := c.Query("user_id")
-	// status := c.Query("status")
+	filter, ok := parseParticipationFilter(c)
+	if !ok {
+		return
+	}
+	var includeUsers, includeActivities bool
+	for _, name := range strings.Split(c.Query("include"), ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case "user":
+			includeUsers = true
+		case "activity":
+			includeActivities = true
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "include must list user and/or activity"})
+			return
+		}
+	}
 
-	var activityUUID, userUUID *uuid.UUID
-	var statusPtr *string
+	page, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+	participations, total, err := api.service.ListParticipations(context.Background(), filter, page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch participations"})
+		return
+	}
+	if includeUsers || includeActivities {
+		if err := api.service.AttachSummaries(context.Background(), participations, includeUsers, includeActivities); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch participations"})
+			return
+		}
+	}
+	writePage(c, page, participations, total, func(p model.ActivityParticipation) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: p.CreatedAt, ID: p.ParticipationID}
+	})
+}
 
-	if activityID != "" {
+// parseParticipationFilter reads the participation listing filters from the
+// query string. It writes a 400 response and returns false for invalid values.
+func parseParticipationFilter(c *gin.Context) (repository.ParticipationFilter, bool) {
+	var filter repository.ParticipationFilter
+	fail := func(msg string) (repository.ParticipationFilter, bool) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return filter, false
+	}
+	if activityID := c.Query("activity_id"); activityID != "" {
 		parsed, err := uuid.Parse(activityID)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity ID"})
-			return
+			return fail("Invalid activity ID")
 		}
-		activityUUID = &parsed
+		filter.ActivityID = &parsed
 	}
-	if userID != "" {
+	if userID := c.Query("user_id"); userID != "" {
 		parsed, err := uuid.Parse(userID)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
-			return
+			return fail("Invalid user ID")
 		}
-		userUUID = &parsed
+		filter.UserID = &parsed
 	}
-
-	page := c.DefaultQuery("page", "1")
-	limit := c.DefaultQuery("limit", "10")
-	pageInt := 1
-	limitInt := 10
-	if p, err := strconv.Atoi(page); err == nil && p > 0 {
-		pageInt = p
+	if orgID := c.Query("org_id"); orgID != "" {
+		parsed, err := uuid.Parse(orgID)
+		if err != nil {
+			return fail("Invalid organization ID")
+		}
+		filter.OrgID = &parsed
+	}
+	if statuses := c.Query("status"); statuses != "" {
+		for _, status := range strings.Split(statuses, ",") {
+			status = strings.ToUpper(strings.TrimSpace(status))
+			if !service.IsParticipationStatus(status) {
+				return fail(service.ErrInvalidStatus.Error() + ": " + status)
+			}
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+	if from := c.Query("from"); from != "" {
+		parsed, err := parseActivityTime(from)
+		if err != nil {
+			return fail("Invalid from date, expected RFC 3339")
+		}
+		filter.CreatedFrom = parsed
+	}
+	if to := c.Query("to"); to != "" {
+		parsed, err := parseActivityTime(to)
+		if err != nil {
+			return fail("Invalid to date, expected RFC 3339")
+		}
+		filter.CreatedTo = parsed
 	}
-	if l, err := strconv.Atoi(limit); err == nil && l > 0 && l <= 100 {
-		limitInt = l
+	if hasEvidence := c.Query("has_evidence"); hasEvidence != "" {
+		parsed, err := strconv.ParseBool(hasEvidence)
+		if err != nil {
+			return fail("has_evidence must be true or false")
+		}
+		filter.HasEvidence = &parsed
 	}
-	offset := (pageInt - 1) * limitInt
-	participations, err := api.service.ListParticipations(context.Background(), activityUUID, userUUID, statusPtr, offset, limitInt)
+	criteria, err := listquery.Parse(c.Request.URL.Query(), repository.ParticipationListSchema)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch participations"})
-		return
+		return fail(err.Error())
 	}
-	c.JSON(http.StatusOK, participations)
+	filter.Criteria = criteria
+	return filter, true
 }
 
 func (api *ActivityParticipationAPI) GetParticipation(c *gin.Context) {
@@ -99,6 +184,17 @@ func (api *ActivityParticipationAPI) CreateParticipation(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity ID"})
 		return
 	}
+	// Registering someone else is reserved for the activity's organization staff;
+	// participants join through JoinActivity.
+	activity, err := api.activityService.GetActivity(context.Background(), activityID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+		return
+	}
+	if !requireActivityStaff(c, api.orgAdminService, activity, model.CoHostManageParticipations) {
+		return
+	}
+	staffID, _ := currentUserID(c)
 	userID, err := uuid.Parse(req.UserID)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
@@ -116,123 +212,201 @@ func (api *ActivityParticipationAPI) CreateParticipation(c *gin.Context) {
 	participation := &model.ActivityParticipation{
 		ActivityID:              activityID,
 		UserID:                  userID,
-		Status:                  req.Status,
+		Status:                  strings.ToUpper(req.Status),
 		ProofOfParticipationURL: req.ProofOfParticipationURL,
 		IssuedBadgeID:           issuedBadgeID,
 		CreatedAt:               time.Now(),
 	}
-	if err := api.service.CreateParticipation(context.Background(), participation); err != nil {
+	err = api.service.CreateParticipation(context.Background(), participation, service.UserActor(service.ActorStaff, staffID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+		return
+	}
+	if isRegistrationConflict(err) || errors.Is(err, service.ErrIllegalTransition) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create participation"})
 		return
 	}
 	c.JSON(http.StatusCreated, participation)
 }
 
-func (api *ActivityParticipationAPI) UpdateParticipation(c *gin.Context) {
-	id := c.Param("id")
-	participationID, err := uuid.Parse(id)
+// JoinActivity registers the authenticated user for the activity.
+func (api *ActivityParticipationAPI) JoinActivity(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	activityID, err := uuid.Parse(c.Param("activity_id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid participation ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity ID"})
 		return
 	}
-	var updates map[string]interface{}
-	if err := c.ShouldBindJSON(&updates); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	participation, err := api.service.JoinActivity(context.Background(), activityID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+		return
+	}
+	if isRegistrationConflict(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join activity"})
+		return
+	}
+	c.JSON(http.StatusCreated, participation)
+}
+
+// LeaveActivity withdraws the authenticated user from the activity.
+func (api *ActivityParticipationAPI) LeaveActivity(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
-	participation, err := api.service.UpdateParticipation(context.Background(), participationID, updates)
+	// The DELETE route shares its wildcard with DELETE /activities/:id.
+	activityID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update participation"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity ID"})
+		return
+	}
+	participation, err := api.service.LeaveActivity(context.Background(), activityID, userID)
+	if errors.Is(err, service.ErrNotJoined) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrIllegalTransition) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to leave activity"})
 		return
 	}
 	c.JSON(http.StatusOK, participation)
 }
 
-func (api *ActivityParticipationAPI) DeleteParticipation(c *gin.Context) {
-	id := c.Param("id")
-	participationID, err := uuid.Parse(id)
+// WithdrawParticipation withdraws the participant; their place goes to the
+// next person on the waitlist. Only the participant or organization staff may
+// withdraw.
+func (api *ActivityParticipationAPI) WithdrawParticipation(c *gin.Context) {
+	participationID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid participation ID"})
 		return
 	}
-	if err := api.service.DeleteParticipation(context.Background(), participationID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete participation"})
+	participation, err := api.service.GetParticipation(context.Background(), participationID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Participation not found"})
+		return
+	}
+	actor, ok := api.participationActor(c, participation)
+	if !ok {
+		return
+	}
+	participation, err = api.service.WithdrawParticipation(context.Background(), participationID, actor)
+	if errors.Is(err, service.ErrIllegalTransition) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusNoContent, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to withdraw participation"})
+		return
+	}
+	c.JSON(http.StatusOK, participation)
 }
 
 type UploadEvidenceRequest struct {
-	ProofOfParticipationURL string `json:"proof_of_participation_url" binding:"required"`
+	ProofOfParticipationURL string  `json:"proof_of_participation_url" binding:"required"`
+	Note                    *string `json:"note"`
 }
 
+// UploadEvidence adds an evidence item to the participation and submits it
+// to the organization's review queue. It takes either JSON with a link, or a
+// multipart form with the file in "file" and an optional "note".
 func (api *ActivityParticipationAPI) UploadEvidence(c *gin.Context) {
-	participationIDStr := c.Param("id")
-	userIDStr := c.Query("user_id")
-	activityIDStr := c.Query("activity_id")
+	participationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid participation ID"})
+		return
+	}
 
-	var participationID *uuid.UUID
-	if participationIDStr != "" {
-		parsed, err := uuid.Parse(participationIDStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid participation ID"})
+	multipart := strings.HasPrefix(c.ContentType(), "multipart/form-data")
+	var req UploadEvidenceRequest
+	if !multipart {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		participationID = &parsed
 	}
-	var userID *uuid.UUID
-	if userIDStr != "" {
-		parsed, err := uuid.Parse(userIDStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
-			return
-		}
-		userID = &parsed
+
+	participation, err := api.service.GetParticipation(context.Background(), participationID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Participation not found"})
+		return
 	}
-	var activityID *uuid.UUID
-	if activityIDStr != "" {
-		parsed, err := uuid.Parse(activityIDStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity ID"})
+	actor, ok := api.participationActor(c, participation)
+	if !ok {
+		return
+	}
+
+	var evidence *model.ParticipationEvidence
+	var updated *model.ActivityParticipation
+	if multipart {
+		object, ok := storeUpload(c, api.storageService, model.ObjectEvidence, "file")
+		if !ok {
 			return
 		}
-		activityID = &parsed
+		var note *string
+		if value := strings.TrimSpace(c.PostForm("note")); value != "" {
+			note = &value
+		}
+		evidence, updated, err = api.evidenceService.SubmitEvidenceFile(context.Background(), participationID, object, note, actor)
+	} else {
+		evidence, updated, err = api.evidenceService.SubmitEvidence(context.Background(), participationID, req.ProofOfParticipationURL, req.Note, actor)
 	}
-
-	var req UploadEvidenceRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if errors.Is(err, service.ErrInvalidEvidenceURL) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if errors.Is(err, service.ErrIllegalTransition) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload evidence"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"evidence":      evidence,
+		"participation": updated,
+	})
+}
 
-	// Find participation by filters
-	var participation *model.ActivityParticipation
-	var err error
-	if participationID != nil {
-		participation, err = api.service.GetParticipation(context.Background(), *participationID)
-	} else {
-		var status = "NOT_COMPLETED"
-		participations, err := api.service.ListParticipations(context.Background(), activityID, userID, &status, 1, 1)
-		if err != nil || len(participations) == 0 {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Participation not found"})
-			return
-		}
-		participation = &participations[0]
+// ListEvidence returns the participation's evidence items with their review
+// comments.
+func (api *ActivityParticipationAPI) ListEvidence(c *gin.Context) {
+	participationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid participation ID"})
+		return
 	}
-	if err != nil || participation == nil {
+	participation, err := api.service.GetParticipation(context.Background(), participationID)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Participation not found"})
 		return
 	}
-
-	updates := map[string]interface{}{
-		"proof_of_participation_url": req.ProofOfParticipationURL,
+	if _, ok := api.participationActor(c, participation); !ok {
+		return
 	}
-	updated, err := api.service.UpdateParticipation(context.Background(), participation.ParticipationID, updates)
+	evidence, err := api.evidenceService.ListEvidence(context.Background(), participationID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload evidence"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch evidence"})
 		return
 	}
-	c.JSON(http.StatusOK, updated)
+	c.JSON(http.StatusOK, evidence)
 }
 
 type UpdateParticipationStatusRequest struct {
@@ -240,6 +414,8 @@ type UpdateParticipationStatusRequest struct {
 	Status                  string  `json:"status" binding:"required"`
 }
 
+// UpdateParticipationStatus moves the participation to a new status. The
+// change must be allowed for the caller by the participation state machine.
 func (api *ActivityParticipationAPI) UpdateParticipationStatus(c *gin.Context) {
 	id := c.Param("id")
 	participationID, err := uuid.Parse(id)
@@ -253,13 +429,37 @@ func (api *ActivityParticipationAPI) UpdateParticipationStatus(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	status := strings.ToUpper(req.Status)
+	if !service.IsParticipationStatus(status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": service.ErrInvalidStatus.Error()})
+		return
+	}
+
+	current, err := api.service.GetParticipation(context.Background(), participationID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Participation not found"})
+		return
+	}
+	actor, ok := api.participationActor(c, current)
+	if !ok {
+		return
+	}
 
 	participation, err := api.service.UpdateParticipationWithBadgeCreation(
 		context.Background(),
 		participationID,
 		req.ProofOfParticipationURL,
-		strings.ToUpper(req.Status),
+		status,
+		actor,
 	)
+	if errors.Is(err, service.ErrInvalidEvidenceURL) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrIllegalTransition) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update participation status"})
 		return
@@ -268,6 +468,245 @@ func (api *ActivityParticipationAPI) UpdateParticipationStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"message":       "Participation updated successfully",
 		"participation": participation,
-		"badge_created": req.Status == "completed" || req.Status == "COMPLETED",
+		"badge_created": status == model.ParticipationCompleted && current.Status != model.ParticipationCompleted && participation.IssuedBadgeID != nil,
 	})
 }
+
+// ListStatusHistory returns the participation's status transitions. It is
+// visible to the participant and organization staff.
+func (api *ActivityParticipationAPI) ListStatusHistory(c *gin.Context) {
+	participationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid participation ID"})
+		return
+	}
+	participation, err := api.service.GetParticipation(context.Background(), participationID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Participation not found"})
+		return
+	}
+	if _, ok := api.participationActor(c, participation); !ok {
+		return
+	}
+	transitions, err := api.service.ListTransitions(context.Background(), participationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch status history"})
+		return
+	}
+	c.JSON(http.StatusOK, transitions)
+}
+
+// ExportRoster downloads the activity's participants with their statuses as
+// CSV (the default) or, with ?format=xlsx, as a spreadsheet.
+func (api *ActivityParticipationAPI) ExportRoster(c *gin.Context) {
+	activity, ok := api.rosterActivity(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	format := c.DefaultQuery("format", roster.FormatCSV)
+	if format != roster.FormatCSV && format != roster.FormatXLSX {
+		c.JSON(http.StatusBadRequest, gin.H{"error": roster.ErrUnknownFormat.Error()})
+		return
+	}
+	rows, err := api.rosterService.Export(context.Background(), activity.ActivityID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export participants"})
+		return
+	}
+	var buf bytes.Buffer
+	if err := roster.Write(&buf, format, rows); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export participants"})
+		return
+	}
+	filename := fmt.Sprintf("participants-%s.%s", activity.ActivityID, format)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(http.StatusOK, roster.ContentType(format), buf.Bytes())
+}
+
+// ImportRoster registers the users listed in an uploaded CSV or XLSX file
+// (multipart field "file") by their email column. The format follows the
+// file extension unless ?format= is given. With dry_run=true nothing is
+// saved; the report shows what would happen.
+func (api *ActivityParticipationAPI) ImportRoster(c *gin.Context) {
+	activity, ok := api.rosterActivity(c, c.Param("activity_id"))
+	if !ok {
+		return
+	}
+	staffID, _ := currentUserID(c)
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A file is required in the \"file\" field"})
+		return
+	}
+	if header.Size > maxRosterUploadBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": service.ErrFileTooLarge.Error()})
+		return
+	}
+	format := c.Query("format")
+	if format == "" {
+		format = roster.FormatOf(header.Filename)
+	}
+	dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", c.PostForm("dry_run")))
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read upload"})
+		return
+	}
+	defer file.Close()
+	rows, err := roster.Read(file, format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := api.rosterService.Import(context.Background(), activity.ActivityID, rows, dryRun, service.UserActor(service.ActorStaff, staffID))
+	switch {
+	case errors.Is(err, service.ErrRosterEmpty),
+		errors.Is(err, service.ErrRosterNoEmailColumn),
+		errors.Is(err, service.ErrRosterTooLarge):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case isRegistrationConflict(err):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import participants"})
+		return
+	}
+	status := http.StatusOK
+	if !dryRun && report.Registered+report.Waitlisted > 0 {
+		status = http.StatusCreated
+	}
+	c.JSON(status, report)
+}
+
+type BulkUpdateStatusRequest struct {
+	ParticipationIDs []string `json:"participation_ids"`
+	FromStatus       string   `json:"from_status"`
+	Status           string   `json:"status" binding:"required"`
+	Reason           string   `json:"reason"`
+}
+
+// BulkUpdateStatus moves many participations of the activity to one status,
+// selected by participation_ids or as all of those in from_status. Small
+// batches are applied at once and answered with a per-item report; larger
+// ones are queued and answered with 202 and a bulk operation to poll.
+func (api *ActivityParticipationAPI) BulkUpdateStatus(c *gin.Context) {
+	activity, ok := api.rosterActivity(c, c.Param("activity_id"))
+	if !ok {
+		return
+	}
+	staffID, _ := currentUserID(c)
+	var req BulkUpdateStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ids := make([]uuid.UUID, 0, len(req.ParticipationIDs))
+	for _, raw := range req.ParticipationIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid participation ID: " + raw})
+			return
+		}
+		ids = append(ids, id)
+	}
+
+	report, operation, err := api.bulkService.UpdateStatuses(context.Background(), activity.ActivityID, service.BulkStatusRequest{
+		ParticipationIDs: ids,
+		FromStatus:       strings.ToUpper(req.FromStatus),
+		Status:           strings.ToUpper(req.Status),
+		Reason:           req.Reason,
+	}, service.UserActor(service.ActorStaff, staffID))
+	switch {
+	case errors.Is(err, service.ErrInvalidStatus),
+		errors.Is(err, service.ErrBulkNoSelection),
+		errors.Is(err, service.ErrBulkEmpty),
+		errors.Is(err, service.ErrBulkTooLarge):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update participation statuses"})
+		return
+	}
+	if operation != nil {
+		c.JSON(http.StatusAccepted, operation)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetBulkOperation reports the progress and per-item results of a queued
+// bulk status update to staff of its activity.
+func (api *ActivityParticipationAPI) GetBulkOperation(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid operation ID"})
+		return
+	}
+	operation, err := api.bulkService.GetOperation(context.Background(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bulk operation not found"})
+		return
+	}
+	if _, ok := api.rosterActivity(c, operation.ActivityID.String()); !ok {
+		return
+	}
+	c.JSON(http.StatusOK, operation)
+}
+
+// rosterActivity loads the activity for a roster export or import, or a bulk
+// status update, and checks that the caller manages its participations.
+func (api *ActivityParticipationAPI) rosterActivity(c *gin.Context, rawID string) (*model.Activity, bool) {
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity ID"})
+		return nil, false
+	}
+	activity, err := api.activityService.GetActivity(context.Background(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+		return nil, false
+	}
+	if !requireActivityStaff(c, api.orgAdminService, activity, model.CoHostManageParticipations) {
+		return nil, false
+	}
+	return activity, true
+}
+
+// participationActor resolves how the authenticated user acts on the
+// participation: as staff of the activity's host or of a co-host managing its
+// participations (or platform admin), or as the participant. It writes a 401,
+// 403 or 404 response and returns false for anyone else.
+func (api *ActivityParticipationAPI) participationActor(c *gin.Context, participation *model.ActivityParticipation) (service.Actor, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return service.Actor{}, false
+	}
+	if c.GetString("user_role") == constant.RoleAdmin {
+		return service.UserActor(service.ActorStaff, userID), true
+	}
+	activity, err := api.activityService.GetActivity(context.Background(), participation.ActivityID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+		return service.Actor{}, false
+	}
+	if staff, err := api.orgAdminService.IsActivityStaff(context.Background(), activity, userID, model.CoHostManageParticipations); err == nil && staff {
+		return service.UserActor(service.ActorStaff, userID), true
+	}
+	if participation.UserID == userID {
+		return service.UserActor(service.ActorParticipant, userID), true
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+	return service.Actor{}, false
+}
+
+func isRegistrationConflict(err error) bool {
+	return errors.Is(err, service.ErrRegistrationNotOpen) ||
+		errors.Is(err, service.ErrRegistrationClosed) ||
+		errors.Is(err, service.ErrAlreadyJoined) ||
+		errors.Is(err, service.ErrActivityEnded) ||
+		errors.Is(err, service.ErrActivityCancelled) ||
+		errors.Is(err, service.ErrActivityArchived)
+}
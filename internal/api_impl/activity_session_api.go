@@ -0,0 +1,90 @@
+package api_impl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"ping-badge-be/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ActivitySessionAPI struct {
+	activityService      service.ActivityService
+	participationService service.ActivityParticipationService
+}
+
+func NewActivitySessionAPI(activityService service.ActivityService, participationService service.ActivityParticipationService) *ActivitySessionAPI {
+	return &ActivitySessionAPI{activityService: activityService, participationService: participationService}
+}
+
+type RecordAttendanceRequest struct {
+	ParticipationID string `json:"participation_id" binding:"required"`
+}
+
+func (api *ActivitySessionAPI) ListSessions(c *gin.Context) {
+	activityID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity ID"})
+		return
+	}
+	sessions, err := api.activityService.ListSessions(context.Background(), activityID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+func (api *ActivitySessionAPI) ListAttendance(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+	attendance, err := api.participationService.ListAttendance(context.Background(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch attendance"})
+		return
+	}
+	c.JSON(http.StatusOK, attendance)
+}
+
+func (api *ActivitySessionAPI) RecordAttendance(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+	var req RecordAttendanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	participationID, err := uuid.Parse(req.ParticipationID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid participation ID"})
+		return
+	}
+	attendance, participation, err := api.participationService.RecordAttendance(context.Background(), sessionID, participationID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session or participation not found"})
+		return
+	case errors.Is(err, service.ErrSessionCancelled):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrParticipationNotInSession):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record attendance"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"attendance":    attendance,
+		"participation": participation,
+	})
+}
@@ -93,12 +93,13 @@ func (api *AuthAPI) UpdateProfile(c *gin.Context) {
 		ProfilePictureURL string `json:"profile_picture_url"`
 		Bio               string `json:"bio"`
 		PrivacySetting    string `json:"privacy_setting"`
+		Locale            string `json:"locale" binding:"omitempty,oneof=en vi"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	user, err := api.Service.UpdateProfile(context.Background(), userID, req.Username, req.FullName, req.ProfilePictureURL, req.Bio, req.PrivacySetting)
+	user, err := api.Service.UpdateProfile(context.Background(), userID, req.Username, req.FullName, req.ProfilePictureURL, req.Bio, req.PrivacySetting, req.Locale)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
 		return
@@ -0,0 +1,82 @@
+package api_impl
+
+import (
+	"context"
+	"net/http"
+	"ping-badge-be/internal/constant"
+	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// currentUserID returns the authenticated user's ID set by AuthMiddleware. It
+// writes a 401 response and returns false when the request is unauthenticated.
+func currentUserID(c *gin.Context) (uuid.UUID, bool) {
+	value, exists := c.Get("user_id")
+	userID, ok := value.(uuid.UUID)
+	if !exists || !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// requireOrgStaff checks that the authenticated user is an owner or admin of
+// the organization; platform admins always pass. It writes a 401 or 403
+// response and returns false otherwise.
+func requireOrgStaff(c *gin.Context, orgAdminService service.OrganizationAdminService, orgID uuid.UUID) bool {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return false
+	}
+	if c.GetString("user_role") == constant.RoleAdmin {
+		return true
+	}
+	staff, err := orgAdminService.IsStaff(context.Background(), orgID, userID)
+	if err != nil || !staff {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		return false
+	}
+	return true
+}
+
+// requireActivityStaff checks that the authenticated user is staff of the
+// activity's host organization, or of a co-host granted permission; platform
+// admins always pass. An empty permission admits host staff only. It writes a
+// 401 or 403 response and returns false otherwise.
+func requireActivityStaff(c *gin.Context, orgAdminService service.OrganizationAdminService, activity *model.Activity, permission string) bool {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return false
+	}
+	if c.GetString("user_role") == constant.RoleAdmin {
+		return true
+	}
+	staff, err := orgAdminService.IsActivityStaff(context.Background(), activity, userID, permission)
+	if err != nil || !staff {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		return false
+	}
+	return true
+}
+
+// requireReviewer checks that the authenticated user may review evidence for
+// the organization: its staff, its reviewers and platform admins. It writes a
+// 401 or 403 response and returns false otherwise.
+func requireReviewer(c *gin.Context, orgAdminService service.OrganizationAdminService, orgID uuid.UUID) bool {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return false
+	}
+	if c.GetString("user_role") == constant.RoleAdmin {
+		return true
+	}
+	reviewer, err := orgAdminService.CanReview(context.Background(), orgID, userID)
+	if err != nil || !reviewer {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		return false
+	}
+	return true
+}
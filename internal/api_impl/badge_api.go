@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"ping-badge-be/internal/badgeimage"
 	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
+	"ping-badge-be/internal/repository"
 	"ping-badge-be/internal/service"
-	"strconv"
+	"ping-badge-be/internal/storage"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -14,20 +17,23 @@ import (
 )
 
 type BadgeAPI struct {
-	service service.BadgeService
+	service         service.BadgeService
+	imageService    service.BadgeImageService
+	orgAdminService service.OrganizationAdminService
 }
 
-func NewBadgeAPI(service service.BadgeService) *BadgeAPI {
-	return &BadgeAPI{service: service}
+func NewBadgeAPI(service service.BadgeService, imageService service.BadgeImageService, orgAdminService service.OrganizationAdminService) *BadgeAPI {
+	return &BadgeAPI{service: service, imageService: imageService, orgAdminService: orgAdminService}
 }
 
+// CreateBadgeRequest is sent as a multipart form with the artwork in the
+// "image" field, or as JSON when the image is left unchanged.
 type CreateBadgeRequest struct {
-	BadgeName   string                 `json:"badge_name" binding:"required"`
-	Description string                 `json:"description"`
-	ImageURL    string                 `json:"image_url" binding:"required"`
-	Criteria    string                 `json:"criteria"`
-	BadgeType   string                 `json:"badge_type" binding:"required"`
-	RuleConfig  map[string]interface{} `json:"rule_config"`
+	BadgeName   string                 `json:"badge_name" form:"badge_name" binding:"required"`
+	Description string                 `json:"description" form:"description"`
+	Criteria    string                 `json:"criteria" form:"criteria"`
+	BadgeType   string                 `json:"badge_type" form:"badge_type" binding:"required"`
+	RuleConfig  map[string]interface{} `json:"rule_config" form:"-"`
 }
 
 func (api *BadgeAPI) ListBadges(c *gin.Context) {
@@ -42,12 +48,22 @@ func (api *BadgeAPI) ListBadges(c *gin.Context) {
 			return
 		}
 
-		issuedBadges, err := api.service.ListIssuedBadgesByUser(context.Background(), userUUID)
+		page, ok := parsePagination(c)
+		if !ok {
+			return
+		}
+		criteria, ok := parseListQuery(c, c.Request.URL.Query(), repository.IssuedBadgeListSchema)
+		if !ok {
+			return
+		}
+		issuedBadges, total, err := api.service.ListIssuedBadgesPage(context.Background(), userUUID, criteria, page)
 		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user badges"})
 			return
 		}
-		c.JSON(http.StatusOK, issuedBadges)
+		writePage(c, page, issuedBadges, total, func(badge model.IssuedBadge) pagination.Cursor {
+			return pagination.Cursor{CreatedAt: badge.IssueDate, ID: badge.IssuedBadgeID}
+		})
 		return
 	}
 
@@ -61,23 +77,22 @@ func (api *BadgeAPI) ListBadges(c *gin.Context) {
 		}
 		orgUUID = &parsed
 	}
-	page := c.DefaultQuery("page", "1")
-	limit := c.DefaultQuery("limit", "10")
-	pageInt := 1
-	limitInt := 10
-	if p, err := strconv.Atoi(page); err == nil && p > 0 {
-		pageInt = p
+	page, ok := parsePagination(c)
+	if !ok {
+		return
 	}
-	if l, err := strconv.Atoi(limit); err == nil && l > 0 && l <= 100 {
-		limitInt = l
+	criteria, ok := parseListQuery(c, c.Request.URL.Query(), repository.BadgeListSchema)
+	if !ok {
+		return
 	}
-	offset := (pageInt - 1) * limitInt
-	badges, err := api.service.ListBadges(context.Background(), orgUUID, offset, limitInt)
+	badges, total, err := api.service.ListBadges(context.Background(), orgUUID, criteria, page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch badges"})
 		return
 	}
-	c.JSON(http.StatusOK, badges)
+	writePage(c, page, badges, total, func(badge model.Badge) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: badge.CreatedAt, ID: badge.BadgeDefID}
+	})
 }
 
 func (api *BadgeAPI) GetBadge(c *gin.Context) {
@@ -102,8 +117,11 @@ func (api *BadgeAPI) CreateBadge(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
 		return
 	}
+	if !requireOrgStaff(c, api.orgAdminService, orgUUID) {
+		return
+	}
 	var req CreateBadgeRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := c.ShouldBind(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -112,12 +130,14 @@ func (api *BadgeAPI) CreateBadge(c *gin.Context) {
 		OrgID:       orgUUID,
 		BadgeName:   req.BadgeName,
 		Description: &req.Description,
-		ImageURL:    req.ImageURL,
 		Criteria:    &req.Criteria,
 		BadgeType:   req.BadgeType,
 		// RuleConfig:  req.RuleConfig,
 		IsActive: true,
 	}
+	if !api.uploadImage(c, badge) {
+		return
+	}
 	err = api.service.CreateBadge(context.Background(), badge)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create badge"})
@@ -134,7 +154,7 @@ func (api *BadgeAPI) UpdateBadge(c *gin.Context) {
 		return
 	}
 	var req CreateBadgeRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := c.ShouldBind(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -143,9 +163,14 @@ func (api *BadgeAPI) UpdateBadge(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Badge not found"})
 		return
 	}
+	if !requireOrgStaff(c, api.orgAdminService, badge.OrgID) {
+		return
+	}
+	if _, err := c.FormFile("image"); err == nil && !api.uploadImage(c, badge) {
+		return
+	}
 	badge.BadgeName = req.BadgeName
 	badge.Description = &req.Description
-	badge.ImageURL = req.ImageURL
 	badge.Criteria = &req.Criteria
 	badge.BadgeType = req.BadgeType
 	//badge.RuleConfig = req.RuleConfig
@@ -157,6 +182,49 @@ func (api *BadgeAPI) UpdateBadge(c *gin.Context) {
 	c.JSON(http.StatusOK, badge)
 }
 
+// uploadImage stores the multipart "image" and points the badge at it,
+// writing the error response if that fails.
+func (api *BadgeAPI) uploadImage(c *gin.Context, badge *model.Badge) bool {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return false
+	}
+	header, err := c.FormFile("image")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A PNG or SVG badge image is required in the \"image\" field"})
+		return false
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read upload"})
+		return false
+	}
+	defer file.Close()
+
+	image, err := api.imageService.Upload(context.Background(), userID, header.Filename, file)
+	switch {
+	case errors.Is(err, service.ErrFileTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrUnsupportedFileType), errors.Is(err, badgeimage.ErrUnsupportedFormat):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": badgeimage.ErrUnsupportedFormat.Error()})
+	case errors.Is(err, service.ErrEmptyFile),
+		errors.Is(err, badgeimage.ErrInvalidImage),
+		errors.Is(err, badgeimage.ErrDimensions),
+		errors.Is(err, badgeimage.ErrAspectRatio):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, storage.ErrInfected):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": storage.ErrInfected.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store badge image"})
+	default:
+		badge.ImageURL = image.URL
+		badge.ImageObjectID = &image.ObjectID
+		badge.Thumbnails = image.Thumbnails
+		return true
+	}
+	return false
+}
+
 func (api *BadgeAPI) DeleteBadge(c *gin.Context) {
 	badgeID := c.Param("id")
 	id, err := uuid.Parse(badgeID)
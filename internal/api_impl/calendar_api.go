@@ -0,0 +1,119 @@
+package api_impl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"ping-badge-be/internal/service"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const calendarContentType = "text/calendar; charset=utf-8"
+
+type CalendarAPI struct {
+	service service.CalendarService
+	baseURL string
+}
+
+func NewCalendarAPI(service service.CalendarService, baseURL string) *CalendarAPI {
+	return &CalendarAPI{service: service, baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+// OrganizationFeed serves the organization's activities as a subscribable
+// iCalendar feed.
+func (api *CalendarAPI) OrganizationFeed(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+	feed, err := api.service.OrganizationFeed(context.Background(), orgID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build calendar"})
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=300")
+	c.Data(http.StatusOK, calendarContentType, feed)
+}
+
+// UserFeed serves the activities a user has joined. The token in the URL is
+// the only credential, so calendar apps can subscribe without logging in.
+func (api *CalendarAPI) UserFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+	feed, err := api.service.UserFeed(context.Background(), token)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build calendar"})
+		return
+	}
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Data(http.StatusOK, calendarContentType, feed)
+}
+
+// ActivityEvent serves a single activity as an .ics attachment.
+func (api *CalendarAPI) ActivityEvent(c *gin.Context) {
+	activityID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity ID"})
+		return
+	}
+	event, err := api.service.ActivityEvent(context.Background(), activityID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build calendar"})
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="activity-`+activityID.String()+`.ics"`)
+	c.Data(http.StatusOK, calendarContentType, event)
+}
+
+// GetFeedURL returns the authenticated user's private feed URL.
+func (api *CalendarAPI) GetFeedURL(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	token, err := api.service.FeedToken(context.Background(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create calendar feed"})
+		return
+	}
+	c.JSON(http.StatusOK, api.feedURLs(token))
+}
+
+// ResetFeedURL replaces the user's feed token, revoking the old URL.
+func (api *CalendarAPI) ResetFeedURL(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	token, err := api.service.ResetFeedToken(context.Background(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset calendar feed"})
+		return
+	}
+	c.JSON(http.StatusOK, api.feedURLs(token))
+}
+
+func (api *CalendarAPI) feedURLs(token string) gin.H {
+	url := api.baseURL + service.CalendarFeedPath(token)
+	webcal := url
+	if i := strings.Index(url, "://"); i >= 0 {
+		webcal = "webcal" + url[i:]
+	}
+	return gin.H{"url": url, "webcal_url": webcal}
+}
@@ -0,0 +1,268 @@
+package api_impl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"ping-badge-be/internal/checkin"
+	"ping-badge-be/internal/geo"
+	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CheckInAPI struct {
+	service              service.CheckInService
+	activityService      service.ActivityService
+	participationService service.ActivityParticipationService
+	orgAdminService      service.OrganizationAdminService
+}
+
+func NewCheckInAPI(service service.CheckInService, activityService service.ActivityService, participationService service.ActivityParticipationService, orgAdminService service.OrganizationAdminService) *CheckInAPI {
+	return &CheckInAPI{
+		service:              service,
+		activityService:      activityService,
+		participationService: participationService,
+		orgAdminService:      orgAdminService,
+	}
+}
+
+// CheckInRequest carries either the door code typed or scanned by a
+// participant, or a participant's personal pass scanned by staff. Latitude
+// and Longitude are the device location, required by geofenced activities.
+type CheckInRequest struct {
+	Code      string   `json:"code"`
+	Pass      string   `json:"pass"`
+	SessionID string   `json:"session_id"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+}
+
+type ReviewCheckInRequest struct {
+	Accept *bool `json:"accept" binding:"required"`
+}
+
+// GetCheckInCode returns the current rotating door code for organization
+// staff to display. Pass ?session_id= for a session's own code.
+func (api *CheckInAPI) GetCheckInCode(c *gin.Context) {
+	activityID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity ID"})
+		return
+	}
+	sessionID, ok := optionalSessionID(c, c.Query("session_id"))
+	if !ok {
+		return
+	}
+	activity, err := api.activityService.GetActivity(context.Background(), activityID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+		return
+	}
+	if !requireActivityStaff(c, api.orgAdminService, activity, model.CoHostManageParticipations) {
+		return
+	}
+	code, err := api.service.CurrentCode(context.Background(), activityID, sessionID)
+	if err != nil {
+		writeCheckInError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, code)
+}
+
+// CheckIn checks a participant in. With a code, the authenticated user checks
+// themselves in; with a pass, organization staff check in its holder.
+func (api *CheckInAPI) CheckIn(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	activityID, err := uuid.Parse(c.Param("activity_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity ID"})
+		return
+	}
+	var req CheckInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if (req.Code == "") == (req.Pass == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Provide either a code or a pass"})
+		return
+	}
+	sessionID, ok := optionalSessionID(c, req.SessionID)
+	if !ok {
+		return
+	}
+	var location *geo.Point
+	if req.Latitude != nil || req.Longitude != nil {
+		if req.Latitude == nil || req.Longitude == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Provide both latitude and longitude"})
+			return
+		}
+		location = &geo.Point{Latitude: *req.Latitude, Longitude: *req.Longitude}
+		if !location.Valid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid latitude or longitude"})
+			return
+		}
+	}
+
+	if req.Code != "" {
+		participation, record, err := api.service.CheckInWithCode(context.Background(), activityID, sessionID, userID, req.Code, location)
+		if err != nil {
+			writeCheckInError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"participation": participation, "check_in": record})
+		return
+	}
+
+	activity, err := api.activityService.GetActivity(context.Background(), activityID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+		return
+	}
+	if !requireActivityStaff(c, api.orgAdminService, activity, model.CoHostManageParticipations) {
+		return
+	}
+	participation, record, err := api.service.CheckInWithPass(context.Background(), activityID, sessionID, req.Pass, service.UserActor(service.ActorStaff, userID), location)
+	if err != nil {
+		writeCheckInError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"participation": participation, "check_in": record})
+}
+
+// ListCheckIns returns the activity's check-in log to organization staff.
+// ?flagged=true lists only flagged check-ins awaiting review.
+func (api *CheckInAPI) ListCheckIns(c *gin.Context) {
+	activityID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity ID"})
+		return
+	}
+	activity, err := api.activityService.GetActivity(context.Background(), activityID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+		return
+	}
+	if !requireActivityStaff(c, api.orgAdminService, activity, model.CoHostManageParticipations) {
+		return
+	}
+	records, err := api.service.ListCheckIns(context.Background(), activityID, c.Query("flagged") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch check-ins"})
+		return
+	}
+	c.JSON(http.StatusOK, records)
+}
+
+// ReviewCheckIn lets organization staff accept a flagged check-in, or reject
+// it and mark the participant as a no-show.
+func (api *CheckInAPI) ReviewCheckIn(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	checkInID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid check-in ID"})
+		return
+	}
+	var req ReviewCheckInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	record, err := api.service.GetCheckIn(context.Background(), checkInID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Check-in not found"})
+		return
+	}
+	activity, err := api.activityService.GetActivity(context.Background(), record.ActivityID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+		return
+	}
+	if !requireActivityStaff(c, api.orgAdminService, activity, model.CoHostManageParticipations) {
+		return
+	}
+	participation, err := api.service.ReviewCheckIn(context.Background(), checkInID, *req.Accept, service.UserActor(service.ActorStaff, userID))
+	if err != nil {
+		writeCheckInError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, participation)
+}
+
+// GetPass returns a short-lived personal pass for the participant to show as
+// a QR code.
+func (api *CheckInAPI) GetPass(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	participationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid participation ID"})
+		return
+	}
+	participation, err := api.participationService.GetParticipation(context.Background(), participationID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Participation not found"})
+		return
+	}
+	if participation.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		return
+	}
+	pass, err := api.service.IssuePass(context.Background(), participationID)
+	if err != nil {
+		writeCheckInError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, pass)
+}
+
+func optionalSessionID(c *gin.Context, value string) (*uuid.UUID, bool) {
+	if value == "" {
+		return nil, true
+	}
+	parsed, err := uuid.Parse(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return nil, false
+	}
+	return &parsed, true
+}
+
+func writeCheckInError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Activity, session or participation not found"})
+	case errors.Is(err, service.ErrInvalidCheckInCode),
+		errors.Is(err, service.ErrLocationRequired),
+		errors.Is(err, service.ErrOutsideGeofence),
+		errors.Is(err, checkin.ErrInvalidPass),
+		errors.Is(err, checkin.ErrExpiredPass):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrSessionNotInActivity),
+		errors.Is(err, service.ErrPassNotForActivity),
+		errors.Is(err, service.ErrParticipationNotInSession):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotJoined):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrIllegalTransition),
+		errors.Is(err, service.ErrSessionCancelled),
+		errors.Is(err, service.ErrActivityCancelled),
+		errors.Is(err, service.ErrActivityArchived),
+		errors.Is(err, service.ErrCheckInNotFlagged):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check in"})
+	}
+}
@@ -0,0 +1,70 @@
+package api_impl
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"ping-badge-be/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type EmailAPI struct {
+	service       service.EmailService
+	webhookSecret string
+}
+
+func NewEmailAPI(service service.EmailService, webhookSecret string) *EmailAPI {
+	return &EmailAPI{service: service, webhookSecret: webhookSecret}
+}
+
+type EmailBounceRequest struct {
+	Email     string `json:"email" binding:"required,email"`
+	Type      string `json:"type" binding:"required,oneof=bounce hard_bounce soft_bounce complaint"`
+	MessageID string `json:"message_id"`
+}
+
+// Unsubscribe handles the signed link included in every email.
+func (api *EmailAPI) Unsubscribe(c *gin.Context) {
+	userID, err := uuid.Parse(c.Query("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	category := c.DefaultQuery("category", service.UnsubscribeAll)
+	err = api.service.Unsubscribe(context.Background(), userID, category, c.Query("token"))
+	switch {
+	case errors.Is(err, service.ErrInvalidUnsubscribeToken):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrUnknownNotificationType):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unsubscribe"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "You have been unsubscribed"})
+}
+
+// HandleBounce receives bounce and complaint notifications from the mail
+// provider. Requests must carry the shared secret in X-Webhook-Secret.
+func (api *EmailAPI) HandleBounce(c *gin.Context) {
+	secret := c.GetHeader("X-Webhook-Secret")
+	if api.webhookSecret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(api.webhookSecret)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid webhook secret"})
+		return
+	}
+	var req EmailBounceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := api.service.HandleBounce(context.Background(), req.Email, req.Type, req.MessageID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record bounce"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Bounce recorded"})
+}
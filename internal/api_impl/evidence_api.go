@@ -0,0 +1,239 @@
+package api_impl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
+	"ping-badge-be/internal/repository"
+	"ping-badge-be/internal/service"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type EvidenceAPI struct {
+	service         service.EvidenceService
+	orgAdminService service.OrganizationAdminService
+	storageService  service.StorageService
+}
+
+func NewEvidenceAPI(service service.EvidenceService, orgAdminService service.OrganizationAdminService, storageService service.StorageService) *EvidenceAPI {
+	return &EvidenceAPI{service: service, orgAdminService: orgAdminService, storageService: storageService}
+}
+
+type ReviewEvidenceRequest struct {
+	Decision string `json:"decision" binding:"required"`
+	Comment  string `json:"comment"`
+}
+
+type EvidenceCommentRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// GetEvidence returns an evidence item with its comments to the participant
+// and the organization's reviewers.
+func (api *EvidenceAPI) GetEvidence(c *gin.Context) {
+	evidence, ok := api.loadEvidence(c)
+	if !ok {
+		return
+	}
+	if !api.canSee(c, evidence) {
+		return
+	}
+	c.JSON(http.StatusOK, evidence)
+}
+
+// GetEvidenceFile returns a short-lived signed download URL for an uploaded
+// evidence file. ?redirect=true redirects to it instead.
+func (api *EvidenceAPI) GetEvidenceFile(c *gin.Context) {
+	evidence, ok := api.loadEvidence(c)
+	if !ok {
+		return
+	}
+	if !api.canSee(c, evidence) {
+		return
+	}
+	if evidence.ObjectID == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Evidence has no uploaded file"})
+		return
+	}
+	object, err := api.storageService.GetObject(context.Background(), *evidence.ObjectID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+	url, err := api.storageService.SignedURL(context.Background(), object, downloadURLTTL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign file URL"})
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	if c.Query("redirect") == "true" {
+		c.Redirect(http.StatusFound, url)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"url":        url,
+		"expires_at": time.Now().Add(downloadURLTTL),
+		"file":       object,
+	})
+}
+
+// ReviewEvidence approves, rejects or requests changes to a pending evidence
+// item. Approval completes the participation and issues its badge.
+func (api *EvidenceAPI) ReviewEvidence(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req ReviewEvidenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	evidence, ok := api.loadEvidence(c)
+	if !ok {
+		return
+	}
+	if !requireReviewer(c, api.orgAdminService, evidence.OrgID) {
+		return
+	}
+	reviewed, participation, err := api.service.ReviewEvidence(context.Background(), evidence.EvidenceID, strings.ToLower(req.Decision), strings.TrimSpace(req.Comment), service.UserActor(service.ActorStaff, userID))
+	switch {
+	case errors.Is(err, service.ErrInvalidDecision), errors.Is(err, service.ErrCommentRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrEvidenceAlreadyReviewed), errors.Is(err, service.ErrIllegalTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to review evidence"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"evidence":      reviewed,
+		"participation": participation,
+	})
+}
+
+// AddComment adds a comment to an evidence item, from the participant or one
+// of the organization's reviewers.
+func (api *EvidenceAPI) AddComment(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req EvidenceCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Comment body is required"})
+		return
+	}
+	evidence, ok := api.loadEvidence(c)
+	if !ok {
+		return
+	}
+	if !api.canSee(c, evidence) {
+		return
+	}
+	comment, err := api.service.AddComment(context.Background(), evidence.EvidenceID, userID, body)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add comment"})
+		return
+	}
+	c.JSON(http.StatusCreated, comment)
+}
+
+// ReviewQueue lists the organization's evidence for reviewers, oldest first.
+// It shows pending items unless ?status= names another status or "all", and
+// can be narrowed with ?activity_id= and ?user_id=.
+func (api *EvidenceAPI) ReviewQueue(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+	if !requireReviewer(c, api.orgAdminService, orgID) {
+		return
+	}
+
+	filter := repository.EvidenceFilter{OrgID: orgID, Status: model.EvidencePending}
+	switch status := strings.ToUpper(c.Query("status")); status {
+	case "":
+	case "ALL":
+		filter.Status = ""
+	case model.EvidencePending, model.EvidenceApproved, model.EvidenceRejected, model.EvidenceChangesRequested:
+		filter.Status = status
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
+	if activityID := c.Query("activity_id"); activityID != "" {
+		parsed, err := uuid.Parse(activityID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity ID"})
+			return
+		}
+		filter.ActivityID = &parsed
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		parsed, err := uuid.Parse(userID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+		filter.UserID = &parsed
+	}
+
+	page, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+	items, total, err := api.service.ReviewQueue(context.Background(), filter, page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch review queue"})
+		return
+	}
+	writePage(c, page, items, total, func(item model.ParticipationEvidence) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: item.SubmittedAt, ID: item.EvidenceID}
+	})
+}
+
+func (api *EvidenceAPI) loadEvidence(c *gin.Context) (*model.ParticipationEvidence, bool) {
+	evidenceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid evidence ID"})
+		return nil, false
+	}
+	evidence, err := api.service.GetEvidence(context.Background(), evidenceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Evidence not found"})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch evidence"})
+		return nil, false
+	}
+	return evidence, true
+}
+
+// canSee lets the participant through and otherwise requires a reviewer.
+func (api *EvidenceAPI) canSee(c *gin.Context, evidence *model.ParticipationEvidence) bool {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return false
+	}
+	if evidence.UserID == userID {
+		return true
+	}
+	return requireReviewer(c, api.orgAdminService, evidence.OrgID)
+}
@@ -0,0 +1,81 @@
+package api_impl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
+	"ping-badge-be/internal/repository"
+	"ping-badge-be/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type JobAPI struct {
+	service service.JobService
+}
+
+func NewJobAPI(service service.JobService) *JobAPI {
+	return &JobAPI{service: service}
+}
+
+func (api *JobAPI) ListJobs(c *gin.Context) {
+	var statusPtr, jobTypePtr *string
+	if status := c.Query("status"); status != "" {
+		statusPtr = &status
+	}
+	if jobType := c.Query("job_type"); jobType != "" {
+		jobTypePtr = &jobType
+	}
+
+	page, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+	jobs, total, err := api.service.ListJobs(context.Background(), statusPtr, jobTypePtr, page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch jobs"})
+		return
+	}
+	writePage(c, page, jobs, total, func(job model.Job) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: job.CreatedAt, ID: job.JobID}
+	})
+}
+
+func (api *JobAPI) GetJob(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+	job, err := api.service.GetJob(context.Background(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+func (api *JobAPI) RetryJob(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+	job, err := api.service.RetryJob(context.Background(), id)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	case errors.Is(err, repository.ErrJobNotRetryable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retry job"})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
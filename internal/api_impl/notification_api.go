@@ -0,0 +1,138 @@
+package api_impl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
+	"ping-badge-be/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type NotificationAPI struct {
+	service service.NotificationService
+}
+
+func NewNotificationAPI(service service.NotificationService) *NotificationAPI {
+	return &NotificationAPI{service: service}
+}
+
+type UpdateNotificationPreferencesRequest struct {
+	Preferences []struct {
+		Type         string `json:"type" binding:"required"`
+		InAppEnabled *bool  `json:"in_app_enabled"`
+		EmailEnabled *bool  `json:"email_enabled"`
+	} `json:"preferences" binding:"required,dive"`
+}
+
+func (api *NotificationAPI) ListNotifications(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	unreadOnly := c.Query("unread_only") == "true"
+
+	page, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+	notifications, total, err := api.service.ListNotifications(context.Background(), userID, unreadOnly, page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+		return
+	}
+	writePage(c, page, notifications, total, func(notification model.Notification) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: notification.CreatedAt, ID: notification.NotificationID}
+	})
+}
+
+func (api *NotificationAPI) GetUnreadCount(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	unread, err := api.service.CountUnread(context.Background(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count notifications"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"unread_count": unread})
+}
+
+func (api *NotificationAPI) MarkRead(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+	err = api.service.MarkRead(context.Background(), userID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notification as read"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+}
+
+func (api *NotificationAPI) MarkAllRead(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	updated, err := api.service.MarkAllRead(context.Background(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notifications as read"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"updated": updated})
+}
+
+func (api *NotificationAPI) GetPreferences(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	preferences, err := api.service.GetPreferences(context.Background(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notification preferences"})
+		return
+	}
+	c.JSON(http.StatusOK, preferences)
+}
+
+func (api *NotificationAPI) UpdatePreferences(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req UpdateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	updates := make([]service.NotificationPreferenceUpdate, 0, len(req.Preferences))
+	for _, p := range req.Preferences {
+		updates = append(updates, service.NotificationPreferenceUpdate{Type: p.Type, InAppEnabled: p.InAppEnabled, EmailEnabled: p.EmailEnabled})
+	}
+	updated, err := api.service.UpdatePreferences(context.Background(), userID, updates)
+	if errors.Is(err, service.ErrUnknownNotificationType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification preferences"})
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
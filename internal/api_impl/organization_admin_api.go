@@ -2,11 +2,11 @@ package api_impl
 
 import (
 	"context"
+	"errors"
 	"net/http"
-	"ping-badge-be/internal/constant"
 	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
 	"ping-badge-be/internal/service"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -20,89 +20,106 @@ func NewOrganizationAdminAPI(service service.OrganizationAdminService) *Organiza
 	return &OrganizationAdminAPI{service: service}
 }
 
+// CreateOrganizationAdminRequest adds a member to the organization in the
+// path. role is owner, admin or reviewer.
 type CreateOrganizationAdminRequest struct {
-	OrgID  string `json:"org_id" binding:"required"`
 	UserID string `json:"user_id" binding:"required"`
 	Role   string `json:"role" binding:"required"`
 }
 
+// CreateAdmin lets the organization's staff add a member to it.
 func (api *OrganizationAdminAPI) CreateAdmin(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+	if !requireOrgStaff(c, api.service, orgID) {
+		return
+	}
 	var req CreateOrganizationAdminRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	orgID, _ := uuid.Parse(req.OrgID)
-	userID, _ := uuid.Parse(req.UserID)
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
 	admin := &model.OrganizationAdmin{
 		AdminID: uuid.New(),
 		OrgID:   orgID,
 		UserID:  userID,
 		Role:    req.Role,
 	}
-	if err := api.service.CreateAdmin(context.Background(), admin); err != nil {
+	err = api.service.CreateAdmin(context.Background(), admin)
+	if errors.Is(err, service.ErrInvalidOrgRole) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create admin"})
 		return
 	}
 	c.JSON(http.StatusCreated, admin)
+}
 
-	page := c.DefaultQuery("page", strconv.Itoa(constant.DefaultPage))
-	limit := c.DefaultQuery("limit", strconv.Itoa(constant.DefaultLimit))
-	pageInt, _ := strconv.Atoi(page)
-	limitInt, _ := strconv.Atoi(limit)
-	if pageInt < 1 {
-		pageInt = constant.DefaultPage
-	}
-	if limitInt < 1 || limitInt > constant.MaxLimit {
-		limitInt = constant.DefaultLimit
-	}
-	offset := (pageInt - 1) * limitInt
-	admins, err := api.service.ListAdmins(context.Background(), offset, limitInt)
+// ListAdmins lists the organization's admins to its staff.
+func (api *OrganizationAdminAPI) ListAdmins(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+	if !requireOrgStaff(c, api.service, orgID) {
+		return
+	}
+	page, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+	admins, total, err := api.service.ListAdmins(context.Background(), orgID, page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch admins"})
 		return
 	}
-	c.JSON(http.StatusOK, admins)
+	writePage(c, page, admins, total, func(admin model.OrganizationAdmin) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: admin.CreatedAt, ID: admin.AdminID}
+	})
 }
 
 func (api *OrganizationAdminAPI) GetAdmin(c *gin.Context) {
-	adminID := c.Param("id")
-	id, err := uuid.Parse(adminID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admin ID"})
-		return
-	}
-	admin, err := api.service.GetAdmin(context.Background(), id)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Admin not found"})
+	admin, ok := api.orgAdmin(c)
+	if !ok {
 		return
 	}
 	c.JSON(http.StatusOK, admin)
 }
 
+// UpdateOrganizationAdminRequest changes a member's role: owner, admin or
+// reviewer.
+type UpdateOrganizationAdminRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
 func (api *OrganizationAdminAPI) UpdateAdmin(c *gin.Context) {
-	adminID := c.Param("id")
-	id, err := uuid.Parse(adminID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admin ID"})
+	admin, ok := api.orgAdmin(c)
+	if !ok {
 		return
 	}
-	var req CreateOrganizationAdminRequest
+	var req UpdateOrganizationAdminRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	admin, err := api.service.GetAdmin(context.Background(), id)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Admin not found"})
+	admin.Role = req.Role
+	err := api.service.UpdateAdmin(context.Background(), admin)
+	if errors.Is(err, service.ErrInvalidOrgRole) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	orgID, _ := uuid.Parse(req.OrgID)
-	userID, _ := uuid.Parse(req.UserID)
-	admin.OrgID = orgID
-	admin.UserID = userID
-	admin.Role = req.Role
-	if err := api.service.UpdateAdmin(context.Background(), admin); err != nil {
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update admin"})
 		return
 	}
@@ -110,15 +127,38 @@ func (api *OrganizationAdminAPI) UpdateAdmin(c *gin.Context) {
 }
 
 func (api *OrganizationAdminAPI) DeleteAdmin(c *gin.Context) {
-	adminID := c.Param("id")
-	id, err := uuid.Parse(adminID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admin ID"})
+	admin, ok := api.orgAdmin(c)
+	if !ok {
 		return
 	}
-	if err := api.service.DeleteAdmin(context.Background(), id); err != nil {
+	if err := api.service.DeleteAdmin(context.Background(), admin.AdminID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete admin"})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Admin deleted successfully"})
 }
+
+// orgAdmin loads the member named by :admin_id after checking that the caller
+// is staff of the organization in :id and that the member belongs to it,
+// writing the error response otherwise.
+func (api *OrganizationAdminAPI) orgAdmin(c *gin.Context) (*model.OrganizationAdmin, bool) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return nil, false
+	}
+	id, err := uuid.Parse(c.Param("admin_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admin ID"})
+		return nil, false
+	}
+	if !requireOrgStaff(c, api.service, orgID) {
+		return nil, false
+	}
+	admin, err := api.service.GetAdmin(context.Background(), id)
+	if err != nil || admin.OrgID != orgID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Admin not found"})
+		return nil, false
+	}
+	return admin, true
+}
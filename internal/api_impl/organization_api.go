@@ -5,6 +5,8 @@ import (
 	"net/http"
 
 	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
+	"ping-badge-be/internal/repository"
 	"ping-badge-be/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -12,11 +14,13 @@ import (
 )
 
 type OrganizationAPI struct {
-	service *service.OrganizationService
+	service         *service.OrganizationService
+	orgAdminService service.OrganizationAdminService
+	storageService  service.StorageService
 }
 
-func NewOrganizationAPI(service *service.OrganizationService) *OrganizationAPI {
-	return &OrganizationAPI{service: service}
+func NewOrganizationAPI(service *service.OrganizationService, orgAdminService service.OrganizationAdminService, storageService service.StorageService) *OrganizationAPI {
+	return &OrganizationAPI{service: service, orgAdminService: orgAdminService, storageService: storageService}
 }
 
 type CreateOrganizationRequest struct {
@@ -58,14 +62,22 @@ func (api *OrganizationAPI) ListOrganizations(c *gin.Context) {
 		}
 		userID = &parsed
 	}
-	offset := 0
-	limit := 10
-	orgs, err := api.service.ListOrganizations(context.Background(), offset, limit, userID)
+	page, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+	criteria, ok := parseListQuery(c, c.Request.URL.Query(), repository.OrganizationListSchema)
+	if !ok {
+		return
+	}
+	orgs, total, err := api.service.ListOrganizations(context.Background(), criteria, page, userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch organizations"})
 		return
 	}
-	c.JSON(http.StatusOK, orgs)
+	writePage(c, page, orgs, total, func(org model.Organization) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: org.CreatedAt, ID: org.OrgID}
+	})
 }
 
 // AddAdmin endpoint stub for router
@@ -117,6 +129,35 @@ func (api *OrganizationAPI) UpdateOrganization(c *gin.Context) {
 	c.JSON(http.StatusOK, org)
 }
 
+// UploadLogo stores the multipart "file" as the organization's logo and
+// points OrgLogoURL at it.
+func (api *OrganizationAPI) UploadLogo(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+	if !requireOrgStaff(c, api.orgAdminService, id) {
+		return
+	}
+	org, err := api.service.GetOrganization(context.Background(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+		return
+	}
+	object, ok := storeUpload(c, api.storageService, model.ObjectOrgLogo, "file")
+	if !ok {
+		return
+	}
+	logoURL := api.storageService.PublicURL(object)
+	org.OrgLogoURL = &logoURL
+	if err := api.service.UpdateOrganization(context.Background(), org); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update organization"})
+		return
+	}
+	c.JSON(http.StatusOK, org)
+}
+
 func (api *OrganizationAPI) DeleteOrganization(c *gin.Context) {
 	orgID := c.Param("id")
 	id, err := uuid.Parse(orgID)
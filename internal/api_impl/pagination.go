@@ -0,0 +1,45 @@
+package api_impl
+
+import (
+	"net/http"
+	"net/url"
+	"ping-badge-be/internal/listquery"
+	"ping-badge-be/internal/pagination"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parsePagination reads page and limit, or cursor for keyset paging. It
+// writes a 400 response and returns false for a malformed cursor.
+func parsePagination(c *gin.Context) (pagination.Params, bool) {
+	params, err := pagination.FromQuery(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return pagination.Params{}, false
+	}
+	return params, true
+}
+
+// parseListQuery reads the filter[...] and sort parameters schema allows
+// from query. It writes a 400 response and returns false when they are
+// invalid.
+func parseListQuery(c *gin.Context, query url.Values, schema listquery.Schema) (listquery.Query, bool) {
+	criteria, err := listquery.Parse(query, schema)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return listquery.Query{}, false
+	}
+	return criteria, true
+}
+
+// writePage responds with the page envelope, a Link header to the
+// neighbouring pages and the total in X-Total-Count.
+func writePage[T any](c *gin.Context, params pagination.Params, items []T, total int64, cursorOf func(T) pagination.Cursor) {
+	page := pagination.NewPage(items, total, params, cursorOf)
+	if links := page.LinkHeader(c.Request.URL); links != "" {
+		c.Header("Link", links)
+	}
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	c.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,143 @@
+package api_impl
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/service"
+	"ping-badge-be/internal/storage"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// downloadURLTTL is how long signed download URLs stay valid.
+	downloadURLTTL = 15 * time.Minute
+	// publicCacheSeconds is how long clients may cache a public file redirect,
+	// kept below downloadURLTTL so cached redirects never point at an expired
+	// URL.
+	publicCacheSeconds = 600
+)
+
+type StorageAPI struct {
+	service service.StorageService
+	local   *storage.LocalStorage
+}
+
+// NewStorageAPI serves stored files. local is the local backend whose signed
+// URLs ServeLocal answers; it is nil when another backend serves downloads.
+func NewStorageAPI(service service.StorageService, local *storage.LocalStorage) *StorageAPI {
+	return &StorageAPI{service: service, local: local}
+}
+
+// GetFile redirects to a signed URL for a public file such as a badge image
+// or organization logo.
+func (api *StorageAPI) GetFile(c *gin.Context) {
+	objectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file ID"})
+		return
+	}
+	object, err := api.service.GetObject(context.Background(), objectID)
+	if err != nil || !object.Public {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+	// Public objects never change, so their hash is a stable validator.
+	etag := `"` + object.SHA256 + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age="+strconv.Itoa(publicCacheSeconds))
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	url, err := api.service.SignedURL(context.Background(), object, downloadURLTTL)
+	if err != nil {
+		c.Header("Cache-Control", "no-store")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign file URL"})
+		return
+	}
+	c.Redirect(http.StatusFound, url)
+}
+
+// ServeLocal streams a file from the local backend to holders of a valid
+// signed URL.
+func (api *StorageAPI) ServeLocal(c *gin.Context) {
+	if api.local == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if err := api.local.VerifySignature(key, c.Query("expires"), c.Query("signature")); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	body, err := api.local.Get(context.Background(), key)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+	defer body.Close()
+
+	contentType := "application/octet-stream"
+	if object, err := api.service.GetObjectByKey(context.Background(), key); err == nil {
+		contentType = object.ContentType
+		c.Header("Content-Length", strconv.FormatInt(object.Size, 10))
+		c.Header("ETag", `"`+object.SHA256+`"`)
+		if object.Public {
+			c.Header("Cache-Control", "public, max-age=31536000, immutable")
+		} else {
+			c.Header("Cache-Control", "private, no-store")
+		}
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("X-Content-Type-Options", "nosniff")
+	// Uploaded SVGs are sanitized; this keeps any script they might still
+	// carry from running when opened directly.
+	c.Header("Content-Security-Policy", "default-src 'none'; img-src data:; style-src 'unsafe-inline'; sandbox")
+	c.Status(http.StatusOK)
+	_, _ = io.Copy(c.Writer, body)
+}
+
+// storeUpload stores the multipart file in field for purpose and writes the
+// error response if that fails.
+func storeUpload(c *gin.Context, storageService service.StorageService, purpose, field string) (*model.StoredObject, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return nil, false
+	}
+	header, err := c.FormFile(field)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A file is required in the \"" + field + "\" field"})
+		return nil, false
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read upload"})
+		return nil, false
+	}
+	defer file.Close()
+
+	object, err := storageService.Store(context.Background(), purpose, userID, header.Filename, file)
+	switch {
+	case errors.Is(err, service.ErrFileTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrUnsupportedFileType):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrEmptyFile):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, storage.ErrInfected):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": storage.ErrInfected.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store file"})
+	default:
+		return object, true
+	}
+	return nil, false
+}
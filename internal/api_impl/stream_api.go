@@ -0,0 +1,112 @@
+package api_impl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"ping-badge-be/internal/realtime"
+	"ping-badge-be/internal/service"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxStreamTopics   = 20
+	streamHeartbeat   = 25 * time.Second
+	streamRetryMillis = 3000
+)
+
+type StreamAPI struct {
+	hub     *realtime.Hub
+	service service.RealtimeService
+}
+
+func NewStreamAPI(hub *realtime.Hub, service service.RealtimeService) *StreamAPI {
+	return &StreamAPI{hub: hub, service: service}
+}
+
+// Stream serves Server-Sent Events for the comma separated topics query
+// parameter, e.g. ?topics=activity:<id>:participations,user:<id>:badges.
+func (api *StreamAPI) Stream(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	role, _ := c.Get("user_role")
+	roleStr, _ := role.(string)
+
+	var topics []string
+	for _, topic := range strings.Split(c.Query("topics"), ",") {
+		if topic = strings.TrimSpace(topic); topic != "" {
+			topics = append(topics, topic)
+		}
+	}
+	if len(topics) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one topic is required"})
+		return
+	}
+	if len(topics) > maxStreamTopics {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("At most %d topics are allowed", maxStreamTopics)})
+		return
+	}
+	for _, topic := range topics {
+		err := api.service.Authorize(context.Background(), userID, roleStr, topic)
+		switch {
+		case errors.Is(err, realtime.ErrInvalidTopic):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid topic: " + topic})
+			return
+		case errors.Is(err, service.ErrTopicForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Not allowed to subscribe to " + topic})
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to authorize subscription"})
+			return
+		}
+	}
+
+	sub := api.hub.Subscribe(topics...)
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", streamRetryMillis)
+	writeStreamEvent(c.Writer, "", "ready", gin.H{"topics": topics})
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case msg, ok := <-sub.Messages():
+			if !ok {
+				return false
+			}
+			writeStreamEvent(w, msg.ID, msg.Event, msg)
+			return true
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			return true
+		}
+	})
+}
+
+func writeStreamEvent(w io.Writer, id, event string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	if id != "" {
+		fmt.Fprintf(w, "id: %s\n", id)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+}
@@ -3,10 +3,10 @@ package api_impl
 import (
 	"context"
 	"net/http"
-	"ping-badge-be/internal/constant"
 	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
+	"ping-badge-be/internal/repository"
 	"ping-badge-be/internal/service"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -71,24 +71,22 @@ func (api *UserAPI) GetUser(c *gin.Context) {
 }
 
 func (api *UserAPI) ListUsers(c *gin.Context) {
-	// Parse pagination parameters
-	page := c.DefaultQuery("page", strconv.Itoa(constant.DefaultPage))
-	limit := c.DefaultQuery("limit", strconv.Itoa(constant.DefaultLimit))
-	pageInt, _ := strconv.Atoi(page)
-	limitInt, _ := strconv.Atoi(limit)
-	if pageInt < 1 {
-		pageInt = constant.DefaultPage
-	}
-	if limitInt < 1 || limitInt > constant.MaxLimit {
-		limitInt = constant.DefaultLimit
-	}
-	offset := (pageInt - 1) * limitInt
-	users, err := api.service.ListUsers(context.Background(), offset, limitInt)
+	page, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+	criteria, ok := parseListQuery(c, c.Request.URL.Query(), repository.UserListSchema)
+	if !ok {
+		return
+	}
+	users, total, err := api.service.ListUsers(context.Background(), criteria, page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
 		return
 	}
-	c.JSON(http.StatusOK, users)
+	writePage(c, page, users, total, func(user model.User) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: user.CreatedAt, ID: user.UserID}
+	})
 }
 
 func (api *UserAPI) UpdateUser(c *gin.Context) {
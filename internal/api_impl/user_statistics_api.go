@@ -3,6 +3,9 @@ package api_impl
 import (
 	"context"
 	"net/http"
+	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
+	"ping-badge-be/internal/repository"
 	"ping-badge-be/internal/service"
 	"time"
 
@@ -47,8 +50,10 @@ func (api *UserStatisticsAPI) GetUserStatistics(c *gin.Context) {
 	totalBadges := len(badges)
 
 	// Activities Completed
-	var status = "COMPLETED"
-	participations, err := api.participationService.ListParticipations(context.Background(), nil, &userID, &status, 0, 1000)
+	participations, _, err := api.participationService.ListParticipations(context.Background(), repository.ParticipationFilter{
+		UserID:   &userID,
+		Statuses: []string{model.ParticipationCompleted},
+	}, pagination.Params{Limit: 1000})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch participations"})
 		return
@@ -0,0 +1,133 @@
+// Package badgeimage validates uploaded badge artwork and prepares it for
+// storage: PNGs are checked and scaled to the standard thumbnail sizes, SVGs
+// are checked and stripped of anything that could run script.
+package badgeimage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	ContentTypePNG = "image/png"
+	ContentTypeSVG = "image/svg+xml"
+
+	// MaxFileBytes limits the uploaded file.
+	MaxFileBytes = 2 << 20
+	// MinDimension and MaxDimension bound the pixel size of PNG artwork.
+	MinDimension = 256
+	MaxDimension = 4096
+	// MaxAspectRatio is how far from square artwork may be, as the longer
+	// side over the shorter one.
+	MaxAspectRatio = 1.05
+)
+
+// ThumbnailSizes are the square sizes, in pixels, generated for every badge.
+var ThumbnailSizes = []int{64, 128, 256, 512}
+
+var (
+	ErrUnsupportedFormat = errors.New("badge image must be a PNG or SVG")
+	ErrInvalidImage      = errors.New("badge image could not be read")
+	ErrDimensions        = fmt.Errorf("badge image must be between %d and %d pixels", MinDimension, MaxDimension)
+	ErrAspectRatio       = errors.New("badge image must be square")
+)
+
+// Asset is a file ready to store. Size is the thumbnail size, or 0 for the
+// full image.
+type Asset struct {
+	Size        int
+	ContentType string
+	Data        []byte
+}
+
+// Process validates an image of the given content type and returns the full
+// image followed by its thumbnails. SVGs scale without loss, so the sanitized
+// file serves every size and no thumbnails are returned for them.
+func Process(contentType string, data []byte) ([]Asset, error) {
+	switch contentType {
+	case ContentTypePNG:
+		return processPNG(data)
+	case ContentTypeSVG:
+		clean, err := SanitizeSVG(data)
+		if err != nil {
+			return nil, err
+		}
+		return []Asset{{ContentType: ContentTypeSVG, Data: clean}}, nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+func processPNG(data []byte) ([]Asset, error) {
+	// Check the header first so oversized images are never decoded.
+	config, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if err := checkDimensions(float64(config.Width), float64(config.Height), true); err != nil {
+		return nil, err
+	}
+	src, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	assets := []Asset{{ContentType: ContentTypePNG, Data: data}}
+	for _, size := range ThumbnailSizes {
+		thumb, err := encodePNG(Thumbnail(src, size))
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, Asset{Size: size, ContentType: ContentTypePNG, Data: thumb})
+	}
+	return assets, nil
+}
+
+// Thumbnail scales src to fit a size×size square, centred on a transparent
+// background.
+func Thumbnail(src image.Image, size int) image.Image {
+	bounds := src.Bounds()
+	w, h := size, size
+	if bounds.Dx() > bounds.Dy() {
+		h = size * bounds.Dy() / bounds.Dx()
+	} else if bounds.Dy() > bounds.Dx() {
+		w = size * bounds.Dx() / bounds.Dy()
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	offset := image.Pt((size-w)/2, (size-h)/2)
+	draw.CatmullRom.Scale(dst, image.Rectangle{Min: offset, Max: offset.Add(image.Pt(w, h))}, src, bounds, draw.Over, nil)
+	return dst
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// checkDimensions enforces the aspect ratio, and for raster images the pixel
+// bounds too.
+func checkDimensions(width, height float64, raster bool) error {
+	if width <= 0 || height <= 0 {
+		return ErrInvalidImage
+	}
+	if raster && (width < MinDimension || height < MinDimension || width > MaxDimension || height > MaxDimension) {
+		return ErrDimensions
+	}
+	long, short := width, height
+	if short > long {
+		long, short = short, long
+	}
+	if long/short > MaxAspectRatio {
+		return ErrAspectRatio
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package badgeimage
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pngOf(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		img.Set(x, h/2, color.NRGBA{R: 255, A: 255})
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestProcessPNGGeneratesThumbnails(t *testing.T) {
+	assets, err := Process(ContentTypePNG, pngOf(t, 600, 590))
+	require.NoError(t, err)
+	require.Len(t, assets, 1+len(ThumbnailSizes))
+	assert.Equal(t, 0, assets[0].Size)
+	for i, size := range ThumbnailSizes {
+		config, err := png.DecodeConfig(bytes.NewReader(assets[i+1].Data))
+		require.NoError(t, err)
+		assert.Equal(t, size, assets[i+1].Size)
+		assert.Equal(t, size, config.Width)
+		assert.Equal(t, size, config.Height)
+	}
+}
+
+func TestProcessPNGRejectsBadDimensions(t *testing.T) {
+	_, err := Process(ContentTypePNG, pngOf(t, 100, 100))
+	assert.ErrorIs(t, err, ErrDimensions)
+	_, err = Process(ContentTypePNG, pngOf(t, 600, 300))
+	assert.ErrorIs(t, err, ErrAspectRatio)
+	_, err = Process(ContentTypePNG, []byte("not a png"))
+	assert.ErrorIs(t, err, ErrInvalidImage)
+	_, err = Process("image/gif", nil)
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestSanitizeSVGStripsScript(t *testing.T) {
+	dirty := `<?xml version="1.0"?>
+<!DOCTYPE svg>
+<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="0 0 100 100" onload="alert(1)">
+  <script>alert(2)</script>
+  <foreignObject><div>x</div></foreignObject>
+  <a href="https://evil.example"><circle r="5"/></a>
+  <defs><linearGradient id="g"><stop offset="0" stop-color="red"/></linearGradient></defs>
+  <rect width="100" height="100" fill="url(#g)" style="background:url(https://evil.example/x.png)"/>
+  <use xlink:href="javascript:alert(3)"/>
+  <use href="#g"/>
+  <image href="https://evil.example/track.png"/>
+  <text>Gold &amp; &lt;b&gt;</text>
+</svg>`
+	clean, err := SanitizeSVG([]byte(dirty))
+	require.NoError(t, err)
+	out := string(clean)
+
+	for _, banned := range []string{"script", "alert", "onload", "foreignObject", "evil.example", "<a", "<!DOCTYPE"} {
+		assert.NotContains(t, out, banned)
+	}
+	assert.Contains(t, out, `fill="url(#g)"`)
+	assert.Contains(t, out, `<use href="#g"></use>`)
+	assert.Contains(t, out, `xmlns:xlink="http://www.w3.org/1999/xlink"`)
+	assert.Contains(t, out, "Gold &amp; &lt;b&gt;")
+}
+
+func TestSanitizeSVGChecksShape(t *testing.T) {
+	_, err := SanitizeSVG([]byte(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 200 100"></svg>`))
+	assert.ErrorIs(t, err, ErrAspectRatio)
+	_, err = SanitizeSVG([]byte(`<svg xmlns="http://www.w3.org/2000/svg" width="64px" height="64"></svg>`))
+	assert.NoError(t, err)
+	_, err = SanitizeSVG([]byte(`<html><svg viewBox="0 0 1 1"></svg></html>`))
+	assert.ErrorIs(t, err, ErrInvalidImage)
+	_, err = SanitizeSVG([]byte(`<svg viewBox="0 0 1 1">&xxe;</svg>`))
+	assert.ErrorIs(t, err, ErrInvalidImage)
+}
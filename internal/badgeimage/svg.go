@@ -0,0 +1,174 @@
+package badgeimage
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// svgElements are the elements kept by SanitizeSVG. Anything else, including
+// script, foreignObject, style, links and animations, is dropped along with
+// its content.
+var svgElements = map[string]bool{
+	"svg": true, "g": true, "defs": true, "title": true, "desc": true, "symbol": true, "use": true,
+	"path": true, "rect": true, "circle": true, "ellipse": true, "line": true, "polyline": true, "polygon": true,
+	"text": true, "tspan": true, "textPath": true, "image": true, "marker": true,
+	"linearGradient": true, "radialGradient": true, "stop": true, "pattern": true, "clipPath": true, "mask": true,
+	"filter": true, "feBlend": true, "feColorMatrix": true, "feComposite": true, "feDropShadow": true, "feFlood": true,
+	"feGaussianBlur": true, "feMerge": true, "feMergeNode": true, "feOffset": true,
+}
+
+var (
+	// externalURL matches url(...) references to anything but a fragment.
+	externalURL = regexp.MustCompile(`(?i)url\(\s*['"]?\s*[^'"#\s]`)
+	// scriptValue matches values that run script or load styles.
+	scriptValue = regexp.MustCompile(`(?i)(javascript|vbscript)\s*:|data\s*:\s*text|expression\s*\(|@import`)
+	// inlineImage matches the data URLs image elements may embed.
+	inlineImage = regexp.MustCompile(`^data:image/(png|jpeg|gif|webp);base64,`)
+	viewBoxSep  = regexp.MustCompile(`[\s,]+`)
+)
+
+// SanitizeSVG re-serializes an SVG document keeping only allow-listed
+// elements and dropping event handlers, external references and script URLs.
+// It rejects documents that are not SVG or are not square.
+func SanitizeSVG(data []byte) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = true
+
+	var out bytes.Buffer
+	depth, skip := 0, 0
+	rootSeen := false
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, ErrInvalidImage
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if skip > 0 {
+				skip++
+				continue
+			}
+			if depth == 0 {
+				if rootSeen || t.Name.Space != "" || t.Name.Local != "svg" {
+					return nil, ErrInvalidImage
+				}
+				rootSeen = true
+				if err := checkSVGSize(t); err != nil {
+					return nil, err
+				}
+			}
+			if t.Name.Space != "" || !svgElements[t.Name.Local] {
+				skip = 1
+				continue
+			}
+			depth++
+			out.WriteString("<" + t.Name.Local)
+			for _, attr := range t.Attr {
+				name, ok := safeAttr(t.Name.Local, attr)
+				if !ok {
+					continue
+				}
+				out.WriteString(" " + name + `="`)
+				_ = xml.EscapeText(&out, []byte(attr.Value))
+				out.WriteString(`"`)
+			}
+			out.WriteString(">")
+		case xml.EndElement:
+			if skip > 0 {
+				skip--
+				continue
+			}
+			depth--
+			out.WriteString("</" + t.Name.Local + ">")
+		case xml.CharData:
+			if skip == 0 && depth > 0 {
+				_ = xml.EscapeText(&out, t)
+			}
+		}
+		// Comments, processing instructions and directives (DOCTYPE and
+		// entity declarations) are dropped.
+	}
+	if !rootSeen || depth != 0 {
+		return nil, ErrInvalidImage
+	}
+	return out.Bytes(), nil
+}
+
+// safeAttr returns the name to write an attribute under, or false to drop it.
+func safeAttr(element string, attr xml.Attr) (string, bool) {
+	name := attr.Name.Local
+	switch attr.Name.Space {
+	case "":
+	case "xmlns":
+		if name != "xlink" {
+			return "", false
+		}
+		name = "xmlns:xlink"
+	case "xlink":
+		if name != "href" {
+			return "", false
+		}
+		name = "xlink:href"
+	default:
+		return "", false
+	}
+
+	lower := strings.ToLower(attr.Name.Local)
+	value := strings.TrimSpace(attr.Value)
+	switch {
+	case strings.HasPrefix(lower, "on"):
+		return "", false
+	case lower == "href":
+		if strings.HasPrefix(value, "#") || (element == "image" && inlineImage.MatchString(value)) {
+			return name, true
+		}
+		return "", false
+	case scriptValue.MatchString(value), externalURL.MatchString(value):
+		return "", false
+	}
+	return name, true
+}
+
+// checkSVGSize reads the root element's viewBox, or failing that its pixel
+// width and height, and checks the aspect ratio.
+func checkSVGSize(root xml.StartElement) error {
+	var viewBox, width, height string
+	for _, attr := range root.Attr {
+		if attr.Name.Space != "" {
+			continue
+		}
+		switch attr.Name.Local {
+		case "viewBox":
+			viewBox = attr.Value
+		case "width":
+			width = attr.Value
+		case "height":
+			height = attr.Value
+		}
+	}
+	if viewBox != "" {
+		parts := viewBoxSep.Split(strings.TrimSpace(viewBox), -1)
+		if len(parts) != 4 {
+			return ErrInvalidImage
+		}
+		w, errW := strconv.ParseFloat(parts[2], 64)
+		h, errH := strconv.ParseFloat(parts[3], 64)
+		if errW != nil || errH != nil {
+			return ErrInvalidImage
+		}
+		return checkDimensions(w, h, false)
+	}
+	w, errW := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(width), "px"), 64)
+	h, errH := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(height), "px"), 64)
+	if errW != nil || errH != nil {
+		return ErrInvalidImage
+	}
+	return checkDimensions(w, h, false)
+}
@@ -0,0 +1,158 @@
+// Package checkin generates and verifies the codes used to check people in
+// to activities.
+//
+// Door codes are RFC 6238 time-based one-time passwords derived from a
+// per-activity or per-session secret, so a screenshot of the code stops
+// working after a period or two. Personal passes are short-lived HMAC-signed
+// tokens naming a participation, shown by the participant and scanned by
+// staff.
+package checkin
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Period is how long a door code stays current.
+	Period = 30 * time.Second
+	// Digits is the length of a door code.
+	Digits = 6
+	// Skew is the number of periods either side of now still accepted, to
+	// allow for clock drift and slow typing.
+	Skew = 1
+	// PassTTL is how long a personal pass is valid after it is issued.
+	PassTTL = 10 * time.Minute
+)
+
+var (
+	ErrInvalidSecret = errors.New("invalid check-in secret")
+	ErrInvalidPass   = errors.New("invalid check-in pass")
+	ErrExpiredPass   = errors.New("check-in pass has expired")
+)
+
+var secretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns a new random base32 secret.
+func GenerateSecret() (string, error) {
+	buf := make([]byte, 20)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return secretEncoding.EncodeToString(buf), nil
+}
+
+// Code returns the door code for secret at t.
+func Code(secret string, t time.Time) (string, error) {
+	key, err := secretEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(key) == 0 {
+		return "", ErrInvalidSecret
+	}
+	return hotp(key, uint64(t.Unix()/int64(Period/time.Second))), nil
+}
+
+// Verify reports whether code is the door code for secret at t, or within
+// Skew periods of it.
+func Verify(secret, code string, t time.Time) bool {
+	if len(code) != Digits {
+		return false
+	}
+	for i := -Skew; i <= Skew; i++ {
+		expected, err := Code(secret, t.Add(time.Duration(i)*Period))
+		if err != nil {
+			return false
+		}
+		if hmac.Equal([]byte(expected), []byte(code)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpiresAt returns when the code current at t stops being current.
+func ExpiresAt(t time.Time) time.Time {
+	return t.Truncate(Period).Add(Period)
+}
+
+func hotp(key []byte, counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", Digits, value%1000000)
+}
+
+// Payload is the content of the door QR code. sessionID may be nil.
+func Payload(activityID uuid.UUID, sessionID *uuid.UUID, code string) string {
+	query := url.Values{}
+	query.Set("activity_id", activityID.String())
+	if sessionID != nil {
+		query.Set("session_id", sessionID.String())
+	}
+	query.Set("code", code)
+	return "pingbadge://check-in?" + query.Encode()
+}
+
+// Pass is a signed personal check-in pass.
+type Pass struct {
+	ParticipationID uuid.UUID
+	ExpiresAt       time.Time
+}
+
+// IssuePass signs a pass for the participation that expires PassTTL after now.
+func IssuePass(key []byte, participationID uuid.UUID, now time.Time) (string, Pass) {
+	pass := Pass{ParticipationID: participationID, ExpiresAt: now.Add(PassTTL).Truncate(time.Second)}
+	body := participationID.String() + "." + strconv.FormatInt(pass.ExpiresAt.Unix(), 10)
+	return body + "." + signPass(key, body), pass
+}
+
+// ParsePass verifies a token produced by IssuePass.
+func ParsePass(key []byte, token string, now time.Time) (Pass, error) {
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return Pass{}, ErrInvalidPass
+	}
+	body, signature := token[:i], token[i+1:]
+	if !hmac.Equal([]byte(signature), []byte(signPass(key, body))) {
+		return Pass{}, ErrInvalidPass
+	}
+	idPart, expPart, ok := strings.Cut(body, ".")
+	if !ok {
+		return Pass{}, ErrInvalidPass
+	}
+	participationID, err := uuid.Parse(idPart)
+	if err != nil {
+		return Pass{}, ErrInvalidPass
+	}
+	exp, err := strconv.ParseInt(expPart, 10, 64)
+	if err != nil {
+		return Pass{}, ErrInvalidPass
+	}
+	pass := Pass{ParticipationID: participationID, ExpiresAt: time.Unix(exp, 0)}
+	if now.After(pass.ExpiresAt) {
+		return Pass{}, ErrExpiredPass
+	}
+	return pass, nil
+}
+
+func signPass(key []byte, body string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("check-in-pass:" + body))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,63 @@
+package checkin
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the SHA-1 secret from RFC 6238 appendix B.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCodeMatchesRFC6238Vectors(t *testing.T) {
+	// The RFC lists 8-digit codes; a 6-digit code is their last six digits.
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, want := range vectors {
+		got, err := Code(rfcSecret, time.Unix(unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, want, got, "t=%d", unix)
+	}
+}
+
+func TestVerifyAcceptsAdjacentPeriodsOnly(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	now := time.Unix(1_800_000_000, 0)
+	code, err := Code(secret, now)
+	require.NoError(t, err)
+
+	assert.True(t, Verify(secret, code, now))
+	assert.True(t, Verify(secret, code, now.Add(Period)))
+	assert.False(t, Verify(secret, code, now.Add(3*Period)))
+	assert.False(t, Verify(secret, "12345", now))
+}
+
+func TestPassRoundTrip(t *testing.T) {
+	key := []byte("secret")
+	now := time.Unix(1_800_000_000, 0)
+	id := uuid.MustParse("6f1c2d9e-3b7a-4c55-9d0e-2a8f4b1c7e63")
+	token, issued := IssuePass(key, id, now)
+
+	pass, err := ParsePass(key, token, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, id, pass.ParticipationID)
+	assert.True(t, issued.ExpiresAt.Equal(pass.ExpiresAt))
+
+	_, err = ParsePass(key, token, now.Add(PassTTL+time.Second))
+	assert.ErrorIs(t, err, ErrExpiredPass)
+	_, err = ParsePass([]byte("other"), token, now)
+	assert.ErrorIs(t, err, ErrInvalidPass)
+	_, err = ParsePass(key, token[:len(token)-1]+"x", now)
+	assert.ErrorIs(t, err, ErrInvalidPass)
+}
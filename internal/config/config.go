@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strconv"
 )
 
 type Config struct {
@@ -10,6 +11,32 @@ type Config struct {
 	Port        string
 	GinMode     string
 	CORSOrigins string
+
+	OutboxWebhookURL    string
+	OutboxWebhookSecret string
+
+	JobWorkers int
+
+	AppBaseURL         string
+	MailDriver         string
+	MailFrom           string
+	MailLogDir         string
+	SMTPHost           string
+	SMTPPort           string
+	SMTPUsername       string
+	SMTPPassword       string
+	EmailWebhookSecret string
+
+	StorageDriver   string
+	StorageLocalDir string
+	S3Endpoint      string
+	S3Region        string
+	S3Bucket        string
+	S3AccessKey     string
+	S3SecretKey     string
+	S3UsePathStyle  bool
+	UploadMaxBytes  int
+	ClamdAddress    string
 }
 
 func Load() *Config {
@@ -19,6 +46,32 @@ func Load() *Config {
 		Port:        getEnv("PORT", "8080"),
 		GinMode:     getEnv("GIN_MODE", "debug"),
 		CORSOrigins: getEnv("CORS_ORIGINS", "http://localhost:3000"),
+
+		OutboxWebhookURL:    getEnv("OUTBOX_WEBHOOK_URL", ""),
+		OutboxWebhookSecret: getEnv("OUTBOX_WEBHOOK_SECRET", ""),
+
+		JobWorkers: getEnvInt("JOB_WORKERS", 4),
+
+		AppBaseURL:         getEnv("APP_BASE_URL", "http://localhost:8080"),
+		MailDriver:         getEnv("MAIL_DRIVER", "log"),
+		MailFrom:           getEnv("MAIL_FROM", "PingBadge <no-reply@pingbadge.local>"),
+		MailLogDir:         getEnv("MAIL_LOG_DIR", ""),
+		SMTPHost:           getEnv("SMTP_HOST", "localhost"),
+		SMTPPort:           getEnv("SMTP_PORT", "587"),
+		SMTPUsername:       getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:       getEnv("SMTP_PASSWORD", ""),
+		EmailWebhookSecret: getEnv("EMAIL_WEBHOOK_SECRET", ""),
+
+		StorageDriver:   getEnv("STORAGE_DRIVER", "local"),
+		StorageLocalDir: getEnv("STORAGE_LOCAL_DIR", "./uploads"),
+		S3Endpoint:      getEnv("S3_ENDPOINT", "https://s3.amazonaws.com"),
+		S3Region:        getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:        getEnv("S3_BUCKET", ""),
+		S3AccessKey:     getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:     getEnv("S3_SECRET_KEY", ""),
+		S3UsePathStyle:  getEnv("S3_USE_PATH_STYLE", "false") == "true",
+		UploadMaxBytes:  getEnvInt("UPLOAD_MAX_BYTES", 10<<20),
+		ClamdAddress:    getEnv("CLAMD_ADDRESS", ""),
 	}
 }
 
@@ -28,3 +81,10 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
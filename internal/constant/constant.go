@@ -1,7 +1,18 @@
 package constant
 
+// APIPrefix is the path every API route is mounted under. URLs pointing back
+// at the API are built from it.
+const APIPrefix = "/api/v1"
+
 const (
 	DefaultPage  = 1
 	DefaultLimit = 10
 	MaxLimit     = 100
 )
+
+// User roles
+const (
+	RoleUser      = "USER"
+	RoleOrganizer = "ORGANIZER"
+	RoleAdmin     = "ADMIN"
+)
@@ -2,6 +2,7 @@ package database
 
 import (
 	"ping-badge-be/internal/model"
+	"strings"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -17,6 +18,25 @@ func Initialize(databaseURL string) (*gorm.DB, error) {
 		return nil, err
 	}
 
+	// Status must be normalized before the NOT NULL constraint is applied
+	if db.Migrator().HasTable(&model.ActivityParticipation{}) {
+		if err := normalizeParticipationStatuses(db); err != nil {
+			return nil, err
+		}
+		// Duplicates would block the unique index on active participations
+		if err := withdrawDuplicateParticipations(db); err != nil {
+			return nil, err
+		}
+	}
+
+	// Notifications without a recipient cannot be read by anyone and would
+	// block the NOT NULL constraint on user_id
+	if db.Migrator().HasTable(&model.Notification{}) {
+		if err := removeUnaddressedNotifications(db); err != nil {
+			return nil, err
+		}
+	}
+
 	// Auto-migrate the schema
 	err = db.AutoMigrate(
 		&model.User{},
@@ -25,12 +45,136 @@ func Initialize(databaseURL string) (*gorm.DB, error) {
 		&model.Badge{},
 		&model.IssuedBadge{},
 		&model.Activity{},
+		&model.ActivityTemplate{},
+		&model.ActivityCoHost{},
 		&model.ActivityParticipation{},
+		&model.ParticipationStatusTransition{},
+		&model.ParticipationEvidence{},
+		&model.EvidenceComment{},
+		&model.StoredObject{},
+		&model.ActivitySession{},
+		&model.SessionAttendance{},
+		&model.CheckInRecord{},
 		&model.BadgeView{},
+		&model.OutboxEvent{},
+		&model.OutboxDelivery{},
+		&model.Job{},
+		&model.JobSchedule{},
+		&model.BulkOperation{},
+		&model.Notification{},
+		&model.NotificationPreference{},
+		&model.EmailLog{},
+		&model.EmailSuppression{},
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := backfillParticipationHistory(db); err != nil {
+		return nil, err
+	}
+
+	if err := addActivitySearch(db); err != nil {
+		return nil, err
+	}
+
 	return db, nil
 }
+
+// normalizeParticipationStatuses maps statuses written before the
+// participation state machine onto its statuses: known ones are upper-cased,
+// NOT_COMPLETED becomes EVIDENCE_SUBMITTED when proof was uploaded, and
+// anything else becomes REGISTERED. Rows already valid are left untouched.
+func normalizeParticipationStatuses(db *gorm.DB) error {
+	return db.Exec(`
+		UPDATE activity_participations SET status = CASE
+			WHEN UPPER(status) IN ? THEN UPPER(status)
+			WHEN UPPER(status) = 'NOT_COMPLETED' AND proof_of_participation_url IS NOT NULL THEN ?
+			ELSE ?
+		END
+		WHERE status IS NULL OR status NOT IN ?`,
+		model.ParticipationStatuses,
+		model.ParticipationEvidenceSubmitted,
+		model.ParticipationRegistered,
+		model.ParticipationStatuses,
+	).Error
+}
+
+// removeUnaddressedNotifications deletes notifications written before they
+// were tied to a user.
+func removeUnaddressedNotifications(db *gorm.DB) error {
+	return db.Exec(`DELETE FROM notifications WHERE user_id IS NULL`).Error
+}
+
+// participationProgress orders statuses from least to most advanced, for
+// choosing which of several duplicate participations to keep.
+var participationProgress = []string{
+	model.ParticipationNoShow,
+	model.ParticipationRejected,
+	model.ParticipationWaitlisted,
+	model.ParticipationRegistered,
+	model.ParticipationCheckedIn,
+	model.ParticipationEvidenceSubmitted,
+	model.ParticipationApproved,
+	model.ParticipationCompleted,
+}
+
+// withdrawDuplicateParticipations keeps one active participation per user and
+// activity, written before registration checked for duplicates, and withdraws
+// the rest. The kept one is the one holding a badge, else the most advanced,
+// else the earliest.
+func withdrawDuplicateParticipations(db *gorm.DB) error {
+	return db.Exec(`
+		UPDATE activity_participations SET status = ?, waitlist_position = NULL
+		WHERE participation_id IN (
+			SELECT participation_id FROM (
+				SELECT participation_id, ROW_NUMBER() OVER (
+					PARTITION BY activity_id, user_id
+					ORDER BY issued_badge_id IS NOT NULL DESC,
+						array_position(?::varchar[], status::varchar) DESC NULLS LAST,
+						created_at ASC,
+						participation_id ASC
+				) AS rank
+				FROM activity_participations
+				WHERE status <> ?
+			) ranked
+			WHERE rank > 1
+		)`,
+		model.ParticipationWithdrawn,
+		"{"+strings.Join(participationProgress, ",")+"}",
+		model.ParticipationWithdrawn,
+	).Error
+}
+
+// backfillParticipationHistory gives participations without any status
+// history an initial entry, attributed to the system.
+func backfillParticipationHistory(db *gorm.DB) error {
+	return db.Exec(`
+		INSERT INTO participation_status_transitions (participation_id, from_status, to_status, actor_type, reason, created_at)
+		SELECT p.participation_id, '', p.status, 'system', 'migrated', COALESCE(p.created_at, NOW())
+		FROM activity_participations p
+		WHERE NOT EXISTS (
+			SELECT 1 FROM participation_status_transitions t WHERE t.participation_id = p.participation_id
+		)`,
+	).Error
+}
+
+// addActivitySearch adds the full-text search vector over activity names and
+// descriptions, which GORM cannot declare, and the indexes used by search.
+// The 'simple' configuration does not stem, so it works for any language.
+func addActivitySearch(db *gorm.DB) error {
+	statements := []string{
+		`ALTER TABLE activities ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+			setweight(to_tsvector('simple', COALESCE(activity_name, '')), 'A') ||
+			setweight(to_tsvector('simple', COALESCE(description, '')), 'B')
+		) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_activities_search_vector ON activities USING GIN (search_vector)`,
+		`CREATE INDEX IF NOT EXISTS idx_activities_tags ON activities USING GIN (tags jsonb_path_ops)`,
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package events
+
+import (
+	"context"
+	"log"
+	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/repository"
+	"time"
+)
+
+const (
+	defaultPollInterval = time.Second
+	defaultBatchSize    = 50
+	defaultLease        = time.Minute
+	maxRetryBackoff     = 10 * time.Minute
+)
+
+// Consumer receives outbox events. In-process consumers should write through
+// repos: they are bound to the transaction that records the delivery, so the
+// consumer's writes and the delivery mark commit together. External sinks
+// cannot join that transaction and should use the event ID as an idempotency key.
+type Consumer interface {
+	Name() string
+	Handle(ctx context.Context, repos repository.Repositories, event model.OutboxEvent) error
+}
+
+// HandlerFunc handles an event for a Subscriber.
+type HandlerFunc func(ctx context.Context, repos repository.Repositories, event model.OutboxEvent) error
+
+// Subscriber is an in-process Consumer that only receives the given event types.
+// An empty type list subscribes to every event.
+type Subscriber struct {
+	name       string
+	eventTypes map[string]bool
+	handler    HandlerFunc
+}
+
+func NewSubscriber(name string, handler HandlerFunc, eventTypes ...string) *Subscriber {
+	types := make(map[string]bool, len(eventTypes))
+	for _, t := range eventTypes {
+		types[t] = true
+	}
+	return &Subscriber{name: name, eventTypes: types, handler: handler}
+}
+
+func (s *Subscriber) Name() string {
+	return s.name
+}
+
+func (s *Subscriber) Handle(ctx context.Context, repos repository.Repositories, event model.OutboxEvent) error {
+	if len(s.eventTypes) > 0 && !s.eventTypes[event.EventType] {
+		return nil
+	}
+	return s.handler(ctx, repos, event)
+}
+
+// Dispatcher polls the outbox and delivers each event once to every consumer.
+// Several API instances may run a dispatcher against the same database.
+type Dispatcher struct {
+	outbox       repository.OutboxRepository
+	uow          repository.UnitOfWork
+	consumers    []Consumer
+	pollInterval time.Duration
+	batchSize    int
+	lease        time.Duration
+}
+
+func NewDispatcher(outbox repository.OutboxRepository, uow repository.UnitOfWork) *Dispatcher {
+	return &Dispatcher{
+		outbox:       outbox,
+		uow:          uow,
+		pollInterval: defaultPollInterval,
+		batchSize:    defaultBatchSize,
+		lease:        defaultLease,
+	}
+}
+
+// Subscribe registers a consumer. It must be called before Run.
+func (d *Dispatcher) Subscribe(consumer Consumer) {
+	d.consumers = append(d.consumers, consumer)
+}
+
+// Run dispatches events until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+	for {
+		for {
+			n, err := d.dispatchBatch(ctx)
+			if err != nil {
+				log.Printf("outbox: dispatch failed: %v", err)
+			}
+			if err != nil || n < d.batchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Dispatcher) dispatchBatch(ctx context.Context) (int, error) {
+	events, err := d.outbox.ClaimPending(ctx, d.batchSize, d.lease)
+	if err != nil {
+		return 0, err
+	}
+	for _, event := range events {
+		if ctx.Err() != nil {
+			// The lease expires and another dispatcher picks the event up.
+			return len(events), nil
+		}
+		d.dispatch(ctx, event)
+	}
+	return len(events), nil
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, event model.OutboxEvent) {
+	var failed error
+	for _, consumer := range d.consumers {
+		err := d.uow.Do(ctx, func(repos repository.Repositories) error {
+			first, err := repos.Outbox.MarkDelivered(ctx, event.EventID, consumer.Name())
+			if err != nil || !first {
+				return err
+			}
+			return consumer.Handle(ctx, repos, event)
+		})
+		if err != nil {
+			log.Printf("outbox: consumer %s failed on event %s (%s): %v", consumer.Name(), event.EventID, event.EventType, err)
+			failed = err
+		}
+	}
+
+	if failed != nil {
+		backoff := time.Duration(event.Attempts+1) * time.Duration(event.Attempts+1) * 5 * time.Second
+		if backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+		if err := d.outbox.MarkFailed(ctx, event.EventID, failed.Error(), time.Now().Add(backoff)); err != nil {
+			log.Printf("outbox: failed to record failure for event %s: %v", event.EventID, err)
+		}
+		return
+	}
+	if err := d.outbox.MarkDispatched(ctx, event.EventID); err != nil {
+		log.Printf("outbox: failed to mark event %s dispatched: %v", event.EventID, err)
+	}
+}
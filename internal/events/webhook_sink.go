@@ -0,0 +1,67 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/repository"
+	"time"
+)
+
+// WebhookSink forwards events to an external HTTP endpoint. Receivers should
+// deduplicate on the X-Event-ID header, since a crash between a successful
+// POST and the delivery commit causes a redelivery.
+type WebhookSink struct {
+	name   string
+	url    string
+	secret string
+	client *http.Client
+}
+
+func NewWebhookSink(name, url, secret string) *WebhookSink {
+	return &WebhookSink{
+		name:   name,
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *WebhookSink) Name() string {
+	return s.name
+}
+
+func (s *WebhookSink) Handle(ctx context.Context, _ repository.Repositories, event model.OutboxEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", event.EventID.String())
+	req.Header.Set("X-Event-Type", event.EventType)
+	if s.secret != "" {
+		mac := hmac.New(sha256.New, []byte(s.secret))
+		mac.Write(body)
+		req.Header.Set("X-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s responded with %d", s.url, resp.StatusCode)
+	}
+	return nil
+}
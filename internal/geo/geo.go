@@ -0,0 +1,34 @@
+// Package geo provides the distance calculations used for geofenced
+// check-in.
+package geo
+
+import "math"
+
+// EarthRadiusMeters is the mean Earth radius.
+const EarthRadiusMeters = 6371008.8
+
+// Point is a WGS 84 position in decimal degrees.
+type Point struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Valid reports whether the point's coordinates are in range.
+func (p Point) Valid() bool {
+	return p.Latitude >= -90 && p.Latitude <= 90 && p.Longitude >= -180 && p.Longitude <= 180
+}
+
+// Distance returns the great-circle distance between a and b in meters,
+// using the haversine formula.
+func Distance(a, b Point) float64 {
+	lat1 := radians(a.Latitude)
+	lat2 := radians(b.Latitude)
+	dLat := lat2 - lat1
+	dLon := radians(b.Longitude - a.Longitude)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
@@ -0,0 +1,24 @@
+package geo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistance(t *testing.T) {
+	assert.Zero(t, Distance(Point{10.77, 106.70}, Point{10.77, 106.70}))
+	// One degree of longitude along the equator.
+	assert.InDelta(t, 111195, Distance(Point{0, 0}, Point{0, 1}), 1)
+	// Paris to London.
+	assert.InDelta(t, 343500, Distance(Point{48.8566, 2.3522}, Point{51.5074, -0.1278}), 500)
+	// Antipodes are half the circumference apart.
+	assert.InDelta(t, math.Pi*EarthRadiusMeters, Distance(Point{0, 0}, Point{0, 180}), 1)
+}
+
+func TestValid(t *testing.T) {
+	assert.True(t, Point{-90, 180}.Valid())
+	assert.False(t, Point{91, 0}.Valid())
+	assert.False(t, Point{0, -181}.Valid())
+}
@@ -0,0 +1,147 @@
+// Package ical writes iCalendar (RFC 5545) documents of events.
+package ical
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Event statuses.
+const (
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+const (
+	utcFormat   = "20060102T150405Z"
+	localFormat = "20060102T150405"
+	// maxLine is the longest content line in octets, excluding the CRLF.
+	maxLine = 75
+)
+
+// Event is a VEVENT. Start and End are written in Location when it is set and
+// not UTC, so recurrences follow its daylight saving rules; otherwise in UTC.
+// RRule is the rule without its "RRULE:" prefix.
+type Event struct {
+	UID          string
+	Sequence     int
+	Status       string
+	Summary      string
+	Description  string
+	Location     string
+	URL          string
+	Start        time.Time
+	End          time.Time
+	TimeZone     *time.Location
+	RRule        string
+	ExDates      []time.Time
+	Created      time.Time
+	LastModified time.Time
+}
+
+// Calendar is a VCALENDAR. Method is set for invitations sent as
+// attachments ("PUBLISH" or "CANCEL") and left empty for feeds.
+type Calendar struct {
+	ProdID string
+	Name   string
+	Method string
+	Events []Event
+}
+
+// Encode renders the calendar with CRLF line endings and folded lines.
+// stamp is written as every event's DTSTAMP.
+func (c *Calendar) Encode(stamp time.Time) []byte {
+	w := &writer{}
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", c.ProdID)
+	w.line("CALSCALE", "GREGORIAN")
+	if c.Method != "" {
+		w.line("METHOD", c.Method)
+	}
+	if c.Name != "" {
+		w.line("X-WR-CALNAME", Escape(c.Name))
+	}
+	for _, e := range c.Events {
+		w.event(e, stamp)
+	}
+	w.line("END", "VCALENDAR")
+	return []byte(w.b.String())
+}
+
+type writer struct {
+	b strings.Builder
+}
+
+func (w *writer) event(e Event, stamp time.Time) {
+	w.line("BEGIN", "VEVENT")
+	w.line("UID", e.UID)
+	w.line("DTSTAMP", stamp.UTC().Format(utcFormat))
+	w.line("SEQUENCE", strconv.Itoa(e.Sequence))
+	if e.Status != "" {
+		w.line("STATUS", e.Status)
+	}
+	w.time("DTSTART", e.Start, e.TimeZone)
+	if !e.End.IsZero() && e.End.After(e.Start) {
+		w.time("DTEND", e.End, e.TimeZone)
+	}
+	if e.RRule != "" {
+		w.line("RRULE", e.RRule)
+	}
+	for _, ex := range e.ExDates {
+		w.time("EXDATE", ex, e.TimeZone)
+	}
+	w.line("SUMMARY", Escape(e.Summary))
+	if e.Description != "" {
+		w.line("DESCRIPTION", Escape(e.Description))
+	}
+	if e.Location != "" {
+		w.line("LOCATION", Escape(e.Location))
+	}
+	if e.URL != "" {
+		w.line("URL", e.URL)
+	}
+	if !e.Created.IsZero() {
+		w.line("CREATED", e.Created.UTC().Format(utcFormat))
+	}
+	if !e.LastModified.IsZero() {
+		w.line("LAST-MODIFIED", e.LastModified.UTC().Format(utcFormat))
+	}
+	w.line("END", "VEVENT")
+}
+
+func (w *writer) time(name string, t time.Time, loc *time.Location) {
+	if loc == nil || loc == time.UTC || loc.String() == "UTC" {
+		w.line(name, t.UTC().Format(utcFormat))
+		return
+	}
+	w.line(name+";TZID="+loc.String(), t.In(loc).Format(localFormat))
+}
+
+// line writes "name:value", folding it into lines of at most 75 octets
+// without splitting a UTF-8 sequence.
+func (w *writer) line(name, value string) {
+	line := name + ":" + value
+	limit := maxLine
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.b.WriteString(line[:cut])
+		w.b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts toward the limit.
+		limit = maxLine - 1
+	}
+	w.b.WriteString(line)
+	w.b.WriteString("\r\n")
+}
+
+// Escape escapes a TEXT value.
+func Escape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+	return r.Replace(s)
+}
@@ -0,0 +1,66 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeEvent(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Ho_Chi_Minh")
+	require.NoError(t, err)
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, loc)
+	cal := Calendar{
+		ProdID: "-//PingBadge//Activities//EN",
+		Name:   "Club, activities",
+		Events: []Event{{
+			UID:         "a1@pingbadge",
+			Sequence:    2,
+			Status:      StatusCancelled,
+			Summary:     "Beach clean-up; bring gloves",
+			Description: "Line one\nLine two",
+			Start:       start,
+			End:         start.Add(2 * time.Hour),
+			TimeZone:    loc,
+			RRule:       "FREQ=WEEKLY;COUNT=4",
+			ExDates:     []time.Time{start.AddDate(0, 0, 7)},
+		}},
+	}
+	out := string(cal.Encode(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+	assert.True(t, strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(out, "END:VEVENT\r\nEND:VCALENDAR\r\n"))
+	for _, line := range []string{
+		"X-WR-CALNAME:Club\\, activities",
+		"UID:a1@pingbadge",
+		"DTSTAMP:20260101T000000Z",
+		"SEQUENCE:2",
+		"STATUS:CANCELLED",
+		"DTSTART;TZID=Asia/Ho_Chi_Minh:20260302T090000",
+		"DTEND;TZID=Asia/Ho_Chi_Minh:20260302T110000",
+		"RRULE:FREQ=WEEKLY;COUNT=4",
+		"EXDATE;TZID=Asia/Ho_Chi_Minh:20260309T090000",
+		"SUMMARY:Beach clean-up\\; bring gloves",
+		"DESCRIPTION:Line one\\nLine two",
+	} {
+		assert.Contains(t, out, "\r\n"+line+"\r\n")
+	}
+}
+
+func TestEncodeUTCAndFolding(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	summary := strings.Repeat("ễ", 60)
+	cal := Calendar{ProdID: "-//x//EN", Events: []Event{{UID: "u", Summary: summary, Start: start}}}
+	out := string(cal.Encode(start))
+
+	assert.Contains(t, out, "\r\nDTSTART:20260302T090000Z\r\n")
+	assert.NotContains(t, out, "DTEND")
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	assert.Contains(t, unfolded, "SUMMARY:"+summary+"\r\n")
+}
@@ -0,0 +1,43 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/repository"
+	"time"
+)
+
+// Job types run by the application.
+const (
+	TypeOutboxCleanup     = "outbox.cleanup"
+	TypeJobCleanup        = "jobs.cleanup"
+	TypeActivityReminders = "activity.reminders"
+	TypeEmailSend         = "email.send"
+	TypeBulkStatus        = "participations.bulk_status"
+)
+
+// OutboxCleanup removes dispatched outbox events older than retention.
+func OutboxCleanup(outbox repository.OutboxRepository, retention time.Duration) Handler {
+	return func(ctx context.Context, job model.Job) error {
+		deleted, err := outbox.DeleteDispatchedBefore(ctx, time.Now().Add(-retention))
+		if err != nil {
+			return err
+		}
+		log.Printf("jobs: removed %d dispatched outbox events", deleted)
+		return nil
+	}
+}
+
+// JobCleanup removes succeeded jobs older than retention. Failed and dead jobs
+// are kept for inspection.
+func JobCleanup(repo repository.JobRepository, retention time.Duration) Handler {
+	return func(ctx context.Context, job model.Job) error {
+		deleted, err := repo.DeleteCompletedBefore(ctx, time.Now().Add(-retention))
+		if err != nil {
+			return err
+		}
+		log.Printf("jobs: removed %d completed jobs", deleted)
+		return nil
+	}
+}
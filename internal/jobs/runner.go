@@ -0,0 +1,233 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/repository"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPollInterval = 2 * time.Second
+	defaultJobTimeout   = 5 * time.Minute
+	staleLockAfter      = 15 * time.Minute
+	maxRetryBackoff     = time.Hour
+)
+
+// Handler runs a single job. Returning an error schedules a retry unless the
+// error is wrapped with Permanent or the job has used all of its attempts.
+type Handler func(ctx context.Context, job model.Job) error
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying; the job is dead-lettered at once.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+type schedule struct {
+	name    string
+	cron    *Cron
+	expr    string
+	jobType string
+	payload map[string]interface{}
+}
+
+// Runner executes jobs from the Postgres-backed queue with a pool of workers
+// and enqueues jobs for cron schedules.
+type Runner struct {
+	repo         repository.JobRepository
+	handlers     map[string]Handler
+	schedules    []schedule
+	workers      int
+	pollInterval time.Duration
+	workerID     string
+}
+
+func NewRunner(repo repository.JobRepository, workers int) *Runner {
+	if workers < 1 {
+		workers = 1
+	}
+	host, _ := os.Hostname()
+	return &Runner{
+		repo:         repo,
+		handlers:     make(map[string]Handler),
+		workers:      workers,
+		pollInterval: defaultPollInterval,
+		workerID:     fmt.Sprintf("%s-%s", host, uuid.New().String()[:8]),
+	}
+}
+
+// Register sets the handler for jobType. It must be called before Run.
+func (r *Runner) Register(jobType string, handler Handler) {
+	r.handlers[jobType] = handler
+}
+
+// Schedule enqueues a jobType job whenever cronExpr fires. It must be called
+// before Run.
+func (r *Runner) Schedule(name, cronExpr, jobType string, payload map[string]interface{}) error {
+	c, err := ParseCron(cronExpr)
+	if err != nil {
+		return err
+	}
+	r.schedules = append(r.schedules, schedule{name: name, cron: c, expr: cronExpr, jobType: jobType, payload: payload})
+	return nil
+}
+
+// Enqueue adds a job to run as soon as a worker is free.
+func (r *Runner) Enqueue(ctx context.Context, jobType string, payload map[string]interface{}) (*model.Job, error) {
+	job := &model.Job{JobType: jobType, Payload: payload}
+	if err := r.repo.Enqueue(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Run processes jobs until ctx is cancelled, then waits for in-flight jobs to
+// finish before returning.
+func (r *Runner) Run(ctx context.Context) {
+	now := time.Now()
+	for _, s := range r.schedules {
+		err := r.repo.UpsertSchedule(ctx, &model.JobSchedule{
+			Name:      s.name,
+			Cron:      s.expr,
+			JobType:   s.jobType,
+			Payload:   s.payload,
+			NextRunAt: s.cron.Next(now),
+		})
+		if err != nil {
+			log.Printf("jobs: failed to register schedule %s: %v", s.name, err)
+		}
+	}
+
+	jobTypes := make([]string, 0, len(r.handlers))
+	for jobType := range r.handlers {
+		jobTypes = append(jobTypes, jobType)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < r.workers; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			r.work(ctx, fmt.Sprintf("%s-%d", r.workerID, n), jobTypes)
+		}(i)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		r.schedule(ctx)
+	}()
+	wg.Wait()
+}
+
+func (r *Runner) work(ctx context.Context, workerID string, jobTypes []string) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		job, err := r.repo.ClaimNext(ctx, workerID, jobTypes)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("jobs: claim failed: %v", err)
+		}
+		if job == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(r.pollInterval):
+			}
+			continue
+		}
+		r.execute(ctx, *job)
+	}
+}
+
+// execute runs a claimed job. The job context survives shutdown so the job
+// can finish; only the per-job timeout cancels it.
+func (r *Runner) execute(ctx context.Context, job model.Job) {
+	jobCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), defaultJobTimeout)
+	defer cancel()
+
+	err := r.runHandler(jobCtx, job)
+	if err == nil {
+		if err := r.repo.Complete(jobCtx, job.JobID); err != nil {
+			log.Printf("jobs: failed to complete job %s: %v", job.JobID, err)
+		}
+		return
+	}
+
+	var retryAt *time.Time
+	var permanent *permanentError
+	if !errors.As(err, &permanent) && job.Attempts < job.MaxAttempts {
+		backoff := time.Duration(1<<uint(job.Attempts)) * 10 * time.Second
+		if backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+		next := time.Now().Add(backoff)
+		retryAt = &next
+	}
+	log.Printf("jobs: job %s (%s) attempt %d failed: %v", job.JobID, job.JobType, job.Attempts, err)
+	if err := r.repo.Fail(jobCtx, job.JobID, err.Error(), retryAt); err != nil {
+		log.Printf("jobs: failed to record failure for job %s: %v", job.JobID, err)
+	}
+}
+
+func (r *Runner) runHandler(ctx context.Context, job model.Job) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	handler, ok := r.handlers[job.JobType]
+	if !ok {
+		return Permanent(fmt.Errorf("no handler registered for job type %q", job.JobType))
+	}
+	return handler(ctx, job)
+}
+
+func (r *Runner) schedule(ctx context.Context) {
+	crons := make(map[string]*Cron, len(r.schedules))
+	for _, s := range r.schedules {
+		crons[s.name] = s.cron
+	}
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+	for {
+		_, err := r.repo.EnqueueDueSchedules(ctx, time.Now(), func(s model.JobSchedule) (time.Time, error) {
+			c, ok := crons[s.Name]
+			if !ok {
+				// Schedule registered by another deployment; honour its stored expression.
+				parsed, err := ParseCron(s.Cron)
+				if err != nil {
+					return time.Time{}, err
+				}
+				c = parsed
+			}
+			return c.Next(time.Now()), nil
+		})
+		if err != nil && ctx.Err() == nil {
+			log.Printf("jobs: failed to enqueue scheduled jobs: %v", err)
+		}
+		if _, err := r.repo.RequeueStale(ctx, time.Now().Add(-staleLockAfter)); err != nil && ctx.Err() == nil {
+			log.Printf("jobs: failed to requeue stale jobs: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,326 @@
+// Package listquery parses the generic filter and sort parameters of list
+// endpoints and turns them into GORM clauses.
+//
+// Filters are given as filter[field]=op:value, for example
+// filter[status]=in:approved,completed or filter[created_at]=gte:2026-01-01.
+// Without a known operator prefix the whole value is compared for equality.
+// A field may be filtered more than once; all conditions must hold. Sorting
+// is given as sort=field,-field where a leading "-" sorts descending.
+//
+// Only fields listed in a Schema are accepted, and values are bound as query
+// parameters, so requests never reach the SQL text.
+package listquery
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Operators.
+const (
+	OpEq   = "eq"
+	OpNe   = "ne"
+	OpGt   = "gt"
+	OpGte  = "gte"
+	OpLt   = "lt"
+	OpLte  = "lte"
+	OpIn   = "in"
+	OpNin  = "nin"
+	OpLike = "like"
+	// OpNull takes true for IS NULL and false for IS NOT NULL.
+	OpNull = "null"
+)
+
+// maxListValues caps the values of an in or nin condition.
+const maxListValues = 100
+
+var (
+	ErrInvalidFilter = errors.New("invalid filter")
+	ErrInvalidSort   = errors.New("invalid sort")
+)
+
+// Type is the type of a field's values.
+type Type int
+
+const (
+	String Type = iota
+	// Enum is a string limited to the field's Values, matched ignoring case.
+	Enum
+	UUID
+	// Time accepts RFC 3339 timestamps and YYYY-MM-DD dates (midnight UTC).
+	Time
+	Number
+	Bool
+)
+
+var defaultOps = map[Type][]string{
+	String: {OpEq, OpNe, OpIn, OpNin, OpLike, OpNull},
+	Enum:   {OpEq, OpNe, OpIn, OpNin, OpNull},
+	UUID:   {OpEq, OpNe, OpIn, OpNin, OpNull},
+	Time:   {OpEq, OpNe, OpGt, OpGte, OpLt, OpLte, OpNull},
+	Number: {OpEq, OpNe, OpGt, OpGte, OpLt, OpLte, OpNull},
+	Bool:   {OpEq, OpNull},
+}
+
+// Field is a field clients may filter or sort by.
+type Field struct {
+	Column string
+	Type   Type
+	// Values lists the values of an Enum field.
+	Values []string
+	// Ops restricts the operators allowed; nil allows all those of Type.
+	Ops      []string
+	Sortable bool
+}
+
+// Schema lists the fields of a table that clients may use. ID is the
+// primary key column, which breaks ties when sorting.
+type Schema struct {
+	Table  string
+	ID     string
+	Fields map[string]Field
+}
+
+type condition struct {
+	column string
+	op     string
+	values []interface{}
+}
+
+type order struct {
+	column string
+	desc   bool
+}
+
+// Query is a parsed set of filters and sort orders. The zero Query filters
+// nothing and leaves the order alone.
+type Query struct {
+	table      string
+	id         string
+	conditions []condition
+	orders     []order
+}
+
+// Parse reads the filter[...] and sort parameters allowed by schema.
+func Parse(values url.Values, schema Schema) (Query, error) {
+	q := Query{table: schema.Table, id: schema.ID}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if strings.HasPrefix(key, "filter[") && strings.HasSuffix(key, "]") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		raws := values[key]
+		name := key[len("filter[") : len(key)-1]
+		field, ok := schema.Fields[name]
+		if !ok {
+			return Query{}, fmt.Errorf("%w: unknown field %q", ErrInvalidFilter, name)
+		}
+		for _, raw := range raws {
+			cond, err := parseCondition(field, raw)
+			if err != nil {
+				return Query{}, fmt.Errorf("%w: %s: %v", ErrInvalidFilter, name, err)
+			}
+			q.conditions = append(q.conditions, cond)
+		}
+	}
+
+	if orders := values.Get("sort"); orders != "" {
+		for _, name := range strings.Split(orders, ",") {
+			name = strings.TrimSpace(name)
+			desc := strings.HasPrefix(name, "-")
+			name = strings.TrimPrefix(name, "-")
+			field, ok := schema.Fields[name]
+			if !ok || !field.Sortable {
+				return Query{}, fmt.Errorf("%w: cannot sort by %q", ErrInvalidSort, name)
+			}
+			q.orders = append(q.orders, order{column: field.Column, desc: desc})
+		}
+	}
+	return q, nil
+}
+
+func parseCondition(field Field, raw string) (condition, error) {
+	op, value := OpEq, raw
+	if prefix, rest, ok := strings.Cut(raw, ":"); ok && isOperator(prefix) {
+		op, value = prefix, rest
+	}
+	allowed := field.Ops
+	if allowed == nil {
+		allowed = defaultOps[field.Type]
+	}
+	if !contains(allowed, op) {
+		return condition{}, fmt.Errorf("operator %q is not allowed", op)
+	}
+
+	cond := condition{column: field.Column, op: op}
+	switch op {
+	case OpNull:
+		isNull, err := strconv.ParseBool(value)
+		if err != nil {
+			return condition{}, errors.New("null takes true or false")
+		}
+		cond.values = []interface{}{isNull}
+		return cond, nil
+	case OpIn, OpNin:
+		parts := strings.Split(value, ",")
+		if len(parts) > maxListValues {
+			return condition{}, fmt.Errorf("at most %d values are allowed", maxListValues)
+		}
+		for _, part := range parts {
+			v, err := parseValue(field, strings.TrimSpace(part))
+			if err != nil {
+				return condition{}, err
+			}
+			cond.values = append(cond.values, v)
+		}
+		return cond, nil
+	case OpLike:
+		cond.values = []interface{}{"%" + escapeLike(value) + "%"}
+		return cond, nil
+	}
+	v, err := parseValue(field, value)
+	if err != nil {
+		return condition{}, err
+	}
+	cond.values = []interface{}{v}
+	return cond, nil
+}
+
+func parseValue(field Field, value string) (interface{}, error) {
+	switch field.Type {
+	case Enum:
+		for _, allowed := range field.Values {
+			if strings.EqualFold(allowed, value) {
+				return allowed, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown value %q", value)
+	case UUID:
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ID %q", value)
+		}
+		return id, nil
+	case Time:
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t.UTC(), nil
+		}
+		t, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time %q, expected RFC 3339 or YYYY-MM-DD", value)
+		}
+		return t, nil
+	case Number:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", value)
+		}
+		return n, nil
+	case Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean %q", value)
+		}
+		return b, nil
+	}
+	return value, nil
+}
+
+// Where adds the filters to db.
+func (q Query) Where(db *gorm.DB) *gorm.DB {
+	for _, cond := range q.conditions {
+		column := clause.Column{Table: q.table, Name: cond.column}
+		var expr clause.Expression
+		switch cond.op {
+		case OpEq:
+			expr = clause.Eq{Column: column, Value: cond.values[0]}
+		case OpNe:
+			expr = clause.Neq{Column: column, Value: cond.values[0]}
+		case OpGt:
+			expr = clause.Gt{Column: column, Value: cond.values[0]}
+		case OpGte:
+			expr = clause.Gte{Column: column, Value: cond.values[0]}
+		case OpLt:
+			expr = clause.Lt{Column: column, Value: cond.values[0]}
+		case OpLte:
+			expr = clause.Lte{Column: column, Value: cond.values[0]}
+		case OpIn:
+			expr = clause.IN{Column: column, Values: cond.values}
+		case OpNin:
+			expr = clause.Not(clause.IN{Column: column, Values: cond.values})
+		case OpLike:
+			expr = clause.Expr{SQL: "? ILIKE ?", Vars: []interface{}{column, cond.values[0]}}
+		case OpNull:
+			if cond.values[0].(bool) {
+				expr = clause.Eq{Column: column, Value: nil}
+			} else {
+				expr = clause.Neq{Column: column, Value: nil}
+			}
+		}
+		db = db.Where(expr)
+	}
+	return db
+}
+
+// HasFilter reports whether the query filters on column.
+func (q Query) HasFilter(column string) bool {
+	for _, cond := range q.conditions {
+		if cond.column == column {
+			return true
+		}
+	}
+	return false
+}
+
+// Sorted reports whether the query asks for an order.
+func (q Query) Sorted() bool {
+	return len(q.orders) > 0
+}
+
+// Order adds the requested order to db, followed by the primary key so pages
+// are stable. It does nothing when no order was requested.
+func (q Query) Order(db *gorm.DB) *gorm.DB {
+	if !q.Sorted() {
+		return db
+	}
+	columns := make([]clause.OrderByColumn, 0, len(q.orders)+1)
+	for _, o := range q.orders {
+		columns = append(columns, clause.OrderByColumn{Column: clause.Column{Table: q.table, Name: o.column}, Desc: o.desc})
+	}
+	columns = append(columns, clause.OrderByColumn{Column: clause.Column{Table: q.table, Name: q.id}})
+	return db.Clauses(clause.OrderBy{Columns: columns})
+}
+
+func isOperator(op string) bool {
+	switch op {
+	case OpEq, OpNe, OpGt, OpGte, OpLt, OpLte, OpIn, OpNin, OpLike, OpNull:
+		return true
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeLike escapes the LIKE wildcards in s so it matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
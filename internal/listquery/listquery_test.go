@@ -0,0 +1,77 @@
+package listquery
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var testSchema = Schema{
+	Table: "items",
+	ID:    "item_id",
+	Fields: map[string]Field{
+		"status":     {Column: "status", Type: Enum, Values: []string{"APPROVED", "COMPLETED"}, Sortable: true},
+		"name":       {Column: "name", Type: String},
+		"created_at": {Column: "created_at", Type: Time, Sortable: true},
+		"owner_id":   {Column: "owner_id", Type: UUID, Ops: []string{OpEq}},
+	},
+}
+
+type item struct{}
+
+func (item) TableName() string { return "items" }
+
+func toSQL(t *testing.T, q Query) string {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+	return db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return q.Order(q.Where(tx.Model(&item{}))).Find(&[]item{})
+	})
+}
+
+func TestParse(t *testing.T) {
+	q, err := Parse(url.Values{
+		"filter[status]":     {"in:approved,completed"},
+		"filter[created_at]": {"gte:2026-01-01", "lt:2026-02-01T00:00:00Z"},
+		"filter[name]":       {"like:50%_off"},
+		"sort":               {"-created_at,status"},
+		"page":               {"2"},
+	}, testSchema)
+	require.NoError(t, err)
+	assert.Equal(t,
+		`SELECT * FROM "items" WHERE "items"."created_at" >= '2026-01-01 00:00:00' AND "items"."created_at" < '2026-02-01 00:00:00' `+
+			`AND "items"."name" ILIKE '%50\%\_off%' AND "items"."status" IN ('APPROVED','COMPLETED') `+
+			`ORDER BY "items"."created_at" DESC,"items"."status","items"."item_id"`,
+		toSQL(t, q))
+}
+
+func TestParseNullAndEquality(t *testing.T) {
+	q, err := Parse(url.Values{"filter[name]": {"null:false"}, "filter[status]": {"Approved"}}, testSchema)
+	require.NoError(t, err)
+	assert.Equal(t,
+		`SELECT * FROM "items" WHERE "items"."name" IS NOT NULL AND "items"."status" = 'APPROVED'`,
+		toSQL(t, q))
+	assert.True(t, q.HasFilter("status"))
+	assert.False(t, q.HasFilter("created_at"))
+	assert.False(t, q.Sorted())
+}
+
+func TestParseRejects(t *testing.T) {
+	cases := map[string]url.Values{
+		"unknown field":       {"filter[password]": {"x"}},
+		"unknown enum value":  {"filter[status]": {"deleted"}},
+		"disallowed operator": {"filter[owner_id]": {"ne:6f1c1f0e-3c1e-4b8a-9c39-1d7c1c6a0b11"}},
+		"bad time":            {"filter[created_at]": {"gte:yesterday"}},
+		"like on time":        {"filter[created_at]": {"like:2026"}},
+	}
+	for name, values := range cases {
+		_, err := Parse(values, testSchema)
+		assert.ErrorIs(t, err, ErrInvalidFilter, name)
+	}
+	_, err := Parse(url.Values{"sort": {"name"}}, testSchema)
+	assert.ErrorIs(t, err, ErrInvalidSort)
+}
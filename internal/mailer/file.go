@@ -0,0 +1,42 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileMailer is the development and test mailer. It writes each message as an
+// .eml file into dir, or logs it when dir is empty.
+type FileMailer struct {
+	dir string
+}
+
+func NewFileMailer(dir string) *FileMailer {
+	return &FileMailer{dir: dir}
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg Message) (string, error) {
+	messageID := newMessageID(msg.From)
+	if m.dir == "" {
+		log.Printf("mailer: to=%s subject=%q message_id=%s\n%s", msg.To, msg.Subject, messageID, msg.Text)
+		return messageID, nil
+	}
+
+	body, err := buildMIME(msg, messageID)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000"), strings.Trim(messageID, "<>"))
+	if err := os.WriteFile(filepath.Join(m.dir, name), body, 0o644); err != nil {
+		return "", err
+	}
+	return messageID, nil
+}
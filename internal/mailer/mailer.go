@@ -0,0 +1,33 @@
+package mailer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+// Message is a rendered email ready to send.
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	HTML    string
+	Text    string
+	Headers map[string]string
+}
+
+// Mailer delivers a message and returns the Message-ID it was sent with.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) (string, error)
+}
+
+func newMessageID(from string) string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = strings.TrimSuffix(from[i+1:], ">")
+	}
+	return "<" + hex.EncodeToString(buf) + "@" + domain + ">"
+}
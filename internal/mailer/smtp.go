@@ -0,0 +1,49 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"net/smtp"
+)
+
+// SMTPMailer sends mail through an SMTP relay, using STARTTLS when offered.
+type SMTPMailer struct {
+	host     string
+	port     string
+	username string
+	password string
+}
+
+func NewSMTPMailer(host, port, username, password string) *SMTPMailer {
+	return &SMTPMailer{host: host, port: port, username: username, password: password}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	from, err := mail.ParseAddress(msg.From)
+	if err != nil {
+		return "", fmt.Errorf("invalid sender %q: %w", msg.From, err)
+	}
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return "", fmt.Errorf("invalid recipient %q: %w", msg.To, err)
+	}
+
+	messageID := newMessageID(from.Address)
+	body, err := buildMIME(msg, messageID)
+	if err != nil {
+		return "", err
+	}
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+	if err := smtp.SendMail(m.host+":"+m.port, auth, from.Address, []string{to.Address}, body); err != nil {
+		return "", err
+	}
+	return messageID, nil
+}
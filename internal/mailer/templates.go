@@ -0,0 +1,116 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+)
+
+// Template names.
+const (
+	TemplateBadgeAwarded          = "badge_awarded"
+	TemplateParticipationApproved = "participation_approved"
+	TemplateActivityReminder      = "activity_reminder"
+	TemplateActivityCancelled     = "activity_cancelled"
+	TemplateActivityRescheduled   = "activity_rescheduled"
+)
+
+const DefaultLocale = "en"
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// subjects holds the localized subject line of each template.
+var subjects = map[string]map[string]string{
+	"en": {
+		TemplateBadgeAwarded:          "You earned the {{.BadgeName}} badge",
+		TemplateParticipationApproved: "Your participation in {{.ActivityName}} was approved",
+		TemplateActivityReminder:      "Reminder: {{.ActivityName}} starts soon",
+		TemplateActivityCancelled:     "{{.ActivityName}} has been cancelled",
+		TemplateActivityRescheduled:   "{{.ActivityName}} has been rescheduled",
+	},
+	"vi": {
+		TemplateBadgeAwarded:          "Bạn đã nhận được huy hiệu {{.BadgeName}}",
+		TemplateParticipationApproved: "Đăng ký tham gia {{.ActivityName}} đã được duyệt",
+		TemplateActivityReminder:      "Nhắc nhở: {{.ActivityName}} sắp bắt đầu",
+		TemplateActivityCancelled:     "{{.ActivityName}} đã bị hủy",
+		TemplateActivityRescheduled:   "{{.ActivityName}} đã được dời lịch",
+	},
+}
+
+// TemplateData is the data available to every email template. OrgName and
+// OrgLogoURL brand the message for the sending organization.
+type TemplateData struct {
+	Locale         string
+	Subject        string
+	RecipientName  string
+	OrgName        string
+	OrgLogoURL     string
+	BadgeName      string
+	BadgeImageURL  string
+	ActivityName   string
+	StartsAt       string
+	Location       string
+	Reason         string
+	ActionURL      string
+	UnsubscribeURL string
+}
+
+// Renderer renders the embedded templates.
+type Renderer struct {
+	html map[string]*htmltemplate.Template
+	text map[string]*texttemplate.Template
+}
+
+func NewRenderer() (*Renderer, error) {
+	r := &Renderer{
+		html: make(map[string]*htmltemplate.Template),
+		text: make(map[string]*texttemplate.Template),
+	}
+	for _, name := range []string{TemplateBadgeAwarded, TemplateParticipationApproved, TemplateActivityReminder, TemplateActivityCancelled, TemplateActivityRescheduled} {
+		h, err := htmltemplate.ParseFS(templateFS, "templates/layout.html.tmpl", "templates/"+name+".html.tmpl")
+		if err != nil {
+			return nil, err
+		}
+		t, err := texttemplate.ParseFS(templateFS, "templates/layout.txt.tmpl", "templates/"+name+".txt.tmpl")
+		if err != nil {
+			return nil, err
+		}
+		r.html[name] = h
+		r.text[name] = t
+	}
+	return r, nil
+}
+
+// Render returns the subject, HTML and text bodies of the named template.
+// Unknown locales fall back to DefaultLocale.
+func (r *Renderer) Render(name string, data TemplateData) (subject, html, text string, err error) {
+	htmlTmpl, ok := r.html[name]
+	if !ok {
+		return "", "", "", fmt.Errorf("unknown email template %q", name)
+	}
+	if _, ok := subjects[data.Locale]; !ok {
+		data.Locale = DefaultLocale
+	}
+
+	subjectTmpl, err := texttemplate.New("subject").Parse(subjects[data.Locale][name])
+	if err != nil {
+		return "", "", "", err
+	}
+	var buf bytes.Buffer
+	if err := subjectTmpl.Execute(&buf, data); err != nil {
+		return "", "", "", err
+	}
+	data.Subject = buf.String()
+
+	var htmlBuf, textBuf bytes.Buffer
+	if err := htmlTmpl.ExecuteTemplate(&htmlBuf, "layout", data); err != nil {
+		return "", "", "", err
+	}
+	if err := r.text[name].ExecuteTemplate(&textBuf, "layout", data); err != nil {
+		return "", "", "", err
+	}
+	return data.Subject, htmlBuf.String(), textBuf.String(), nil
+}
@@ -6,6 +6,20 @@ import (
 	"github.com/google/uuid"
 )
 
+// Geofence policies for check-ins from outside the radius.
+const (
+	GeofenceReject = "reject"
+	GeofenceFlag   = "flag"
+)
+
+// Activity statuses. Cancelled activities stay visible to their participants;
+// archived ones are hidden from listings but keep their history.
+const (
+	ActivityActive    = "ACTIVE"
+	ActivityCancelled = "CANCELLED"
+	ActivityArchived  = "ARCHIVED"
+)
+
 type Activity struct {
 	ActivityID   uuid.UUID  `json:"activity_id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
 	OrgID        uuid.UUID  `json:"org_id" gorm:"type:uuid;not null"`
@@ -13,8 +27,48 @@ type Activity struct {
 	Description  *string    `json:"description" gorm:"type:text"`
 	StartDate    *time.Time `json:"start_date"`
 	EndDate      *time.Time `json:"end_date"`
-	Location     *string    `json:"location" gorm:"type:varchar(255)"`
-	BadgeDefID   *uuid.UUID `json:"badge_def_id" gorm:"type:uuid"`
+	// Timezone is the IANA zone the activity is scheduled in, e.g. "Asia/Ho_Chi_Minh".
+	Timezone   string     `json:"timezone" gorm:"type:varchar(64);not null;default:'UTC'"`
+	Location   *string    `json:"location" gorm:"type:varchar(255)"`
+	BadgeDefID *uuid.UUID `json:"badge_def_id" gorm:"type:uuid"`
+	// Category and Tags classify the activity for discovery. Tags are
+	// lower-case.
+	Category *string  `json:"category" gorm:"type:varchar(50);index"`
+	Tags     []string `json:"tags" gorm:"type:jsonb;serializer:json"`
+	// Latitude and Longitude place the venue. With GeofenceRadiusMeters set,
+	// code check-ins must come from within that distance of it; GeofencePolicy
+	// decides whether check-ins from further away are rejected or flagged.
+	Latitude             *float64 `json:"latitude"`
+	Longitude            *float64 `json:"longitude"`
+	GeofenceRadiusMeters *int     `json:"geofence_radius_meters"`
+	GeofencePolicy       string   `json:"geofence_policy" gorm:"type:varchar(10);not null;default:'reject'"`
+	// RecurrenceRule is an RFC 5545 RRULE expanded from StartDate; EndDate
+	// then marks the end of the first session. RecurrenceExceptions lists
+	// occurrence start times to skip (EXDATE).
+	RecurrenceRule       *string     `json:"recurrence_rule" gorm:"type:varchar(255)"`
+	RecurrenceExceptions []time.Time `json:"recurrence_exceptions" gorm:"type:jsonb;serializer:json"`
+	// RequiredSessions is how many sessions a participant must attend to
+	// complete the activity. Nil disables attendance-based completion.
+	RequiredSessions *int `json:"required_sessions"`
+	// Capacity limits registered participants; further sign-ups are
+	// waitlisted. Nil means unlimited.
+	Capacity *int `json:"capacity"`
+	// Registration is accepted between RegistrationOpensAt and
+	// RegistrationClosesAt when they are set.
+	RegistrationOpensAt  *time.Time `json:"registration_opens_at"`
+	RegistrationClosesAt *time.Time `json:"registration_closes_at"`
+	// ReminderSentAt is set once participants have been reminded of the start.
+	ReminderSentAt *time.Time `json:"-"`
+	// CheckInSecret seeds the rotating door code; it is created on first use.
+	CheckInSecret *string `json:"-" gorm:"type:varchar(64)"`
+	// Sequence is the iCalendar SEQUENCE, bumped whenever calendar apps need
+	// to replace their copy of the event.
+	Sequence int `json:"sequence" gorm:"not null;default:0"`
+	// Status is one of the Activity status constants. CancelledAt and
+	// CancellationReason are set when the activity is cancelled.
+	Status             string     `json:"status" gorm:"type:varchar(20);not null;default:'ACTIVE';index"`
+	CancelledAt        *time.Time `json:"cancelled_at"`
+	CancellationReason *string    `json:"cancellation_reason" gorm:"type:text"`
 	BaseModel
 
 	// Relationships
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Co-host permissions. Staff of a co-host organization get only the
+// permissions granted to it; the hosting organization (Activity.OrgID) has all.
+const (
+	CoHostManageParticipations = "manage_participations"
+	CoHostEditActivity         = "edit_activity"
+)
+
+var CoHostPermissions = []string{
+	CoHostManageParticipations,
+	CoHostEditActivity,
+}
+
+// ActivityCoHost is an organization running an activity together with its
+// host. When BadgeDefID is set, that badge of the co-host is issued alongside
+// the activity's badge; otherwise the co-host is listed as a co-issuer of it.
+// The link takes effect only once the co-host's staff accept it (AcceptedAt).
+type ActivityCoHost struct {
+	ActivityID  uuid.UUID  `json:"activity_id" gorm:"type:uuid;primaryKey"`
+	OrgID       uuid.UUID  `json:"org_id" gorm:"type:uuid;primaryKey;index"`
+	Permissions []string   `json:"permissions" gorm:"type:jsonb;serializer:json"`
+	BadgeDefID  *uuid.UUID `json:"badge_def_id" gorm:"type:uuid"`
+	AddedBy     *uuid.UUID `json:"added_by" gorm:"type:uuid"`
+	AcceptedAt  *time.Time `json:"accepted_at"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Allows reports whether the co-host was granted permission.
+func (h ActivityCoHost) Allows(permission string) bool {
+	for _, granted := range h.Permissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -6,17 +6,79 @@ import (
 	"github.com/google/uuid"
 )
 
+// Participation statuses. Allowed transitions between them are defined by
+// the participation state machine in the service layer.
+const (
+	ParticipationRegistered        = "REGISTERED"
+	ParticipationWaitlisted        = "WAITLISTED"
+	ParticipationCheckedIn         = "CHECKED_IN"
+	ParticipationEvidenceSubmitted = "EVIDENCE_SUBMITTED"
+	ParticipationApproved          = "APPROVED"
+	ParticipationRejected          = "REJECTED"
+	ParticipationCompleted         = "COMPLETED"
+	ParticipationWithdrawn         = "WITHDRAWN"
+	ParticipationNoShow            = "NO_SHOW"
+)
+
+// ParticipationStatuses lists every valid participation status.
+var ParticipationStatuses = []string{
+	ParticipationRegistered,
+	ParticipationWaitlisted,
+	ParticipationCheckedIn,
+	ParticipationEvidenceSubmitted,
+	ParticipationApproved,
+	ParticipationRejected,
+	ParticipationCompleted,
+	ParticipationWithdrawn,
+	ParticipationNoShow,
+}
+
 type ActivityParticipation struct {
-	ParticipationID         uuid.UUID  `json:"participation_id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	ActivityID              uuid.UUID  `json:"activity_id" gorm:"type:uuid;not null"`
-	UserID                  uuid.UUID  `json:"user_id" gorm:"type:uuid;not null"`
-	Status                  string     `json:"status" gorm:"type:varchar(50);default:'registered'"`
+	ParticipationID uuid.UUID `json:"participation_id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	// A user has at most one participation per activity that is not withdrawn.
+	ActivityID              uuid.UUID  `json:"activity_id" gorm:"type:uuid;not null;uniqueIndex:idx_activity_user_active,where:status <> 'WITHDRAWN'"`
+	UserID                  uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_activity_user_active"`
+	Status                  string     `json:"status" gorm:"type:varchar(50);not null;default:'REGISTERED'"`
 	ProofOfParticipationURL *string    `json:"proof_of_participation_url" gorm:"type:varchar(255)"`
 	IssuedBadgeID           *uuid.UUID `json:"issued_badge_id" gorm:"type:uuid;uniqueIndex"`
-	CreatedAt               time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	// WaitlistPosition orders waitlisted participants; it is nil otherwise.
+	WaitlistPosition *int      `json:"waitlist_position"`
+	CreatedAt        time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// Relationships. Activity and User are summaries filled in by listings
+	// that ask for them.
+	Activity    *ActivitySummary `json:"activity,omitempty" gorm:"-"`
+	User        *UserSummary     `json:"user,omitempty" gorm:"-"`
+	IssuedBadge *IssuedBadge     `gorm:"-"`
+}
+
+// ActivitySummary is the part of an activity embedded in a participation.
+type ActivitySummary struct {
+	ActivityID   uuid.UUID  `json:"activity_id"`
+	OrgID        uuid.UUID  `json:"org_id"`
+	ActivityName string     `json:"activity_name"`
+	StartDate    *time.Time `json:"start_date"`
+	EndDate      *time.Time `json:"end_date"`
+	Status       string     `json:"status"`
+}
+
+// UserSummary is the part of a user embedded in a participation.
+type UserSummary struct {
+	UserID            uuid.UUID `json:"user_id"`
+	Username          string    `json:"username"`
+	FullName          *string   `json:"full_name"`
+	ProfilePictureURL *string   `json:"profile_picture_url"`
+}
 
-	// Relationships
-	Activity    Activity     `gorm:"-"`
-	User        User         `gorm:"-"`
-	IssuedBadge *IssuedBadge `gorm:"-"`
+// ParticipationStatusTransition is one entry of a participation's status
+// history. FromStatus is empty for the initial status.
+type ParticipationStatusTransition struct {
+	TransitionID    uuid.UUID  `json:"transition_id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ParticipationID uuid.UUID  `json:"participation_id" gorm:"type:uuid;not null;index"`
+	FromStatus      string     `json:"from_status" gorm:"type:varchar(50)"`
+	ToStatus        string     `json:"to_status" gorm:"type:varchar(50);not null"`
+	ActorType       string     `json:"actor_type" gorm:"type:varchar(20);not null"`
+	ActorID         *uuid.UUID `json:"actor_id" gorm:"type:uuid"`
+	Reason          *string    `json:"reason" gorm:"type:text"`
+	CreatedAt       time.Time  `json:"created_at" gorm:"autoCreateTime"`
 }
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ActivitySession is one occurrence of an activity. Single activities have
+// one session; recurring activities get one per occurrence of their rule.
+type ActivitySession struct {
+	SessionID  uuid.UUID `json:"session_id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ActivityID uuid.UUID `json:"activity_id" gorm:"type:uuid;not null;uniqueIndex:idx_activity_session_start"`
+	StartsAt   time.Time `json:"starts_at" gorm:"not null;uniqueIndex:idx_activity_session_start"`
+	EndsAt     time.Time `json:"ends_at" gorm:"not null"`
+	// Cancelled sessions dropped from the schedule are kept when they already
+	// have attendance.
+	Cancelled bool `json:"cancelled" gorm:"not null;default:false"`
+	// CheckInSecret seeds the session's own door code; it is created on
+	// first use.
+	CheckInSecret *string   `json:"-" gorm:"type:varchar(64)"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// SessionAttendance records that a participant attended a session.
+type SessionAttendance struct {
+	AttendanceID    uuid.UUID `json:"attendance_id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	SessionID       uuid.UUID `json:"session_id" gorm:"type:uuid;not null;uniqueIndex:idx_session_participation"`
+	ParticipationID uuid.UUID `json:"participation_id" gorm:"type:uuid;not null;uniqueIndex:idx_session_participation;index"`
+	UserID          uuid.UUID `json:"user_id" gorm:"type:uuid;not null"`
+	AttendedAt      time.Time `json:"attended_at" gorm:"not null"`
+}
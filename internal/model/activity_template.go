@@ -0,0 +1,34 @@
+package model
+
+import (
+	"github.com/google/uuid"
+)
+
+// ActivityTemplate is a reusable activity definition. It keeps everything but
+// the dates; DurationMinutes restores the length of the first session when
+// an activity is created from it.
+type ActivityTemplate struct {
+	TemplateID      uuid.UUID  `json:"template_id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	OrgID           uuid.UUID  `json:"org_id" gorm:"type:uuid;not null;index"`
+	TemplateName    string     `json:"template_name" gorm:"type:varchar(255);not null"`
+	ActivityName    string     `json:"activity_name" gorm:"type:varchar(255);not null"`
+	Description     *string    `json:"description" gorm:"type:text"`
+	Location        *string    `json:"location" gorm:"type:varchar(255)"`
+	Timezone        string     `json:"timezone" gorm:"type:varchar(64);not null;default:'UTC'"`
+	DurationMinutes *int       `json:"duration_minutes"`
+	BadgeDefID      *uuid.UUID `json:"badge_def_id" gorm:"type:uuid"`
+	Category        *string    `json:"category" gorm:"type:varchar(50)"`
+	Tags            []string   `json:"tags" gorm:"type:jsonb;serializer:json"`
+	RecurrenceRule  *string    `json:"recurrence_rule" gorm:"type:varchar(255)"`
+	// RequiredSessions and Capacity carry the completion requirement and the
+	// participant limit over to new activities.
+	RequiredSessions *int `json:"required_sessions"`
+	Capacity         *int `json:"capacity"`
+	// Check-in settings.
+	Latitude             *float64   `json:"latitude"`
+	Longitude            *float64   `json:"longitude"`
+	GeofenceRadiusMeters *int       `json:"geofence_radius_meters"`
+	GeofencePolicy       string     `json:"geofence_policy" gorm:"type:varchar(10);not null;default:'reject'"`
+	CreatedBy            *uuid.UUID `json:"created_by" gorm:"type:uuid"`
+	BaseModel
+}
@@ -10,8 +10,12 @@ type Badge struct {
 	BadgeName   string    `json:"badge_name" gorm:"type:varchar(100);not null;index:idx_org_badge_name,unique"`
 	Description *string   `json:"description" gorm:"type:text"`
 	ImageURL    string    `json:"image_url" gorm:"type:varchar(255);not null"`
-	Criteria    *string   `json:"criteria" gorm:"type:text"`
-	BadgeType   string    `json:"badge_type" gorm:"type:varchar(20);not null;default:'instant'"`
+	// ImageObjectID is the uploaded artwork ImageURL serves, and Thumbnails
+	// maps each standard size in pixels to its URL.
+	ImageObjectID *uuid.UUID        `json:"image_object_id" gorm:"type:uuid"`
+	Thumbnails    map[string]string `json:"thumbnails" gorm:"type:jsonb;serializer:json"`
+	Criteria      *string           `json:"criteria" gorm:"type:text"`
+	BadgeType     string            `json:"badge_type" gorm:"type:varchar(20);not null;default:'instant'"`
 	//RuleConfig  map[string]interface{} `json:"rule_config" gorm:"type:jsonb"`
 	IsActive bool `json:"is_active" gorm:"default:true"`
 	BaseModel
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Bulk operation statuses. A failed operation is retried with its job and
+// resumes after the last processed participation.
+const (
+	BulkOperationPending   = "pending"
+	BulkOperationRunning   = "running"
+	BulkOperationSucceeded = "succeeded"
+	BulkOperationFailed    = "failed"
+)
+
+// BulkStatusResult is the outcome for one participation of a bulk status
+// change. Error is set when the participation was left unchanged.
+type BulkStatusResult struct {
+	ParticipationID uuid.UUID  `json:"participation_id"`
+	FromStatus      string     `json:"from_status,omitempty"`
+	Status          string     `json:"status,omitempty"`
+	IssuedBadgeID   *uuid.UUID `json:"issued_badge_id,omitempty"`
+	Error           string     `json:"error,omitempty"`
+}
+
+// BulkOperation tracks a bulk participation status change that is too large
+// to apply within a request. It runs as a background job, which records its
+// progress in Processed and its per-participation outcomes in Results.
+type BulkOperation struct {
+	OperationID      uuid.UUID          `json:"operation_id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ActivityID       uuid.UUID          `json:"activity_id" gorm:"type:uuid;not null;index"`
+	TargetStatus     string             `json:"target_status" gorm:"type:varchar(30);not null"`
+	Reason           *string            `json:"reason" gorm:"type:text"`
+	ParticipationIDs []uuid.UUID        `json:"-" gorm:"type:jsonb;serializer:json"`
+	RequestedBy      uuid.UUID          `json:"requested_by" gorm:"type:uuid;not null"`
+	JobID            *uuid.UUID         `json:"job_id" gorm:"type:uuid"`
+	Status           string             `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
+	Total            int                `json:"total"`
+	Processed        int                `json:"processed"`
+	Succeeded        int                `json:"succeeded"`
+	Failed           int                `json:"failed"`
+	Results          []BulkStatusResult `json:"results" gorm:"type:jsonb;serializer:json"`
+	LastError        *string            `json:"last_error" gorm:"type:text"`
+	CompletedAt      *time.Time         `json:"completed_at"`
+	CreatedAt        time.Time          `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt        time.Time          `json:"updated_at" gorm:"autoUpdateTime"`
+}
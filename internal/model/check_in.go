@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Check-in methods.
+const (
+	CheckInByCode = "code"
+	CheckInByPass = "pass"
+)
+
+// CheckInRecord logs an accepted check-in and where the device was.
+type CheckInRecord struct {
+	CheckInID       uuid.UUID  `json:"check_in_id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ActivityID      uuid.UUID  `json:"activity_id" gorm:"type:uuid;not null;index"`
+	ParticipationID uuid.UUID  `json:"participation_id" gorm:"type:uuid;not null;index"`
+	SessionID       *uuid.UUID `json:"session_id" gorm:"type:uuid"`
+	Method          string     `json:"method" gorm:"type:varchar(10);not null"`
+	ActorID         *uuid.UUID `json:"actor_id" gorm:"type:uuid"`
+	Latitude        *float64   `json:"latitude"`
+	Longitude       *float64   `json:"longitude"`
+	// DistanceMeters is the distance from the venue when both are known.
+	DistanceMeters *float64 `json:"distance_meters"`
+	// Flagged check-ins were accepted from outside the geofence, or without a
+	// location, under the flag policy and wait for staff review.
+	Flagged    bool       `json:"flagged" gorm:"not null;default:false"`
+	ReviewedAt *time.Time `json:"reviewed_at"`
+	ReviewedBy *uuid.UUID `json:"reviewed_by" gorm:"type:uuid"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Email statuses recorded in the send log.
+const (
+	EmailStatusQueued  = "queued"
+	EmailStatusSent    = "sent"
+	EmailStatusFailed  = "failed"
+	EmailStatusBounced = "bounced"
+)
+
+// EmailLog is the persistent record of every email the system sends.
+type EmailLog struct {
+	EmailID           uuid.UUID  `json:"email_id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID            *uuid.UUID `json:"user_id" gorm:"type:uuid;index"`
+	OrgID             *uuid.UUID `json:"org_id" gorm:"type:uuid"`
+	ToAddress         string     `json:"to_address" gorm:"type:varchar(100);not null;index"`
+	Template          string     `json:"template" gorm:"type:varchar(50);not null"`
+	Locale            string     `json:"locale" gorm:"type:varchar(10);not null"`
+	Subject           string     `json:"subject" gorm:"type:varchar(255);not null"`
+	HTMLBody          string     `json:"-" gorm:"type:text"`
+	TextBody          string     `json:"-" gorm:"type:text"`
+	UnsubscribeURL    *string    `json:"-" gorm:"type:text"`
+	Status            string     `json:"status" gorm:"type:varchar(20);not null;default:'queued';index"`
+	Error             *string    `json:"error" gorm:"type:text"`
+	ProviderMessageID *string    `json:"provider_message_id" gorm:"type:varchar(255);index"`
+	SentAt            *time.Time `json:"sent_at"`
+	CreatedAt         time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Suppression reasons.
+const (
+	SuppressionBounce      = "bounce"
+	SuppressionComplaint   = "complaint"
+	SuppressionUnsubscribe = "unsubscribe"
+)
+
+// EmailSuppression blocks all email to an address.
+type EmailSuppression struct {
+	Email     string    `json:"email" gorm:"type:varchar(100);primaryKey"`
+	Reason    string    `json:"reason" gorm:"type:varchar(20);not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -17,7 +17,7 @@ type IssuedBadge struct {
 	SourceID                     *uuid.UUID             `json:"source_id" gorm:"type:uuid"`
 	CumulativeProgressAtIssuance *float64               `json:"cumulative_progress_at_issuance" gorm:"type:numeric"`
 	CumulativeUnit               *string                `json:"cumulative_unit" gorm:"type:varchar(50)"`
-	AdditionalData               map[string]interface{} `json:"additional_data" gorm:"type:jsonb"`
+	AdditionalData               map[string]interface{} `json:"additional_data" gorm:"type:jsonb;serializer:json"`
 	Status                       string                 `json:"status" gorm:"type:varchar(20);default:'issued'"`
 	BlockchainTxID               *string                `json:"blockchain_tx_id" gorm:"type:varchar(255)"`
 
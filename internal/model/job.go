@@ -0,0 +1,45 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job statuses. Failed jobs are retried until MaxAttempts is reached and are
+// then moved to the dead-letter status.
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+	JobStatusDead      = "dead"
+)
+
+type Job struct {
+	JobID       uuid.UUID              `json:"job_id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	JobType     string                 `json:"job_type" gorm:"type:varchar(100);not null;index"`
+	Payload     map[string]interface{} `json:"payload" gorm:"type:jsonb;serializer:json"`
+	Status      string                 `json:"status" gorm:"type:varchar(20);not null;default:'pending';index:idx_job_status_run_at"`
+	Attempts    int                    `json:"attempts" gorm:"default:0"`
+	MaxAttempts int                    `json:"max_attempts" gorm:"default:5"`
+	RunAt       time.Time              `json:"run_at" gorm:"not null;index:idx_job_status_run_at"`
+	LockedAt    *time.Time             `json:"locked_at"`
+	LockedBy    *string                `json:"locked_by" gorm:"type:varchar(100)"`
+	LastError   *string                `json:"last_error" gorm:"type:text"`
+	CompletedAt *time.Time             `json:"completed_at"`
+	CreatedAt   time.Time              `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time              `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// JobSchedule enqueues a job of JobType whenever NextRunAt passes.
+type JobSchedule struct {
+	Name      string                 `json:"name" gorm:"type:varchar(100);primaryKey"`
+	Cron      string                 `json:"cron" gorm:"type:varchar(100);not null"`
+	JobType   string                 `json:"job_type" gorm:"type:varchar(100);not null"`
+	Payload   map[string]interface{} `json:"payload" gorm:"type:jsonb;serializer:json"`
+	NextRunAt time.Time              `json:"next_run_at" gorm:"not null;index"`
+	LastRunAt *time.Time             `json:"last_run_at"`
+	CreatedAt time.Time              `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time              `json:"updated_at" gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification types. Users can opt out of each type individually.
+const (
+	NotificationBadgeIssued         = "badge_issued"
+	NotificationParticipationStatus = "participation_status"
+	NotificationActivityReminder    = "activity_reminder"
+	NotificationOrganizationInvite  = "organization_invite"
+	NotificationActivityChanged     = "activity_changed"
+)
+
+var NotificationTypes = []string{
+	NotificationBadgeIssued,
+	NotificationParticipationStatus,
+	NotificationActivityReminder,
+	NotificationOrganizationInvite,
+	NotificationActivityChanged,
+}
+
+type Notification struct {
+	NotificationID uuid.UUID              `json:"notification_id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID         uuid.UUID              `json:"user_id" gorm:"type:uuid;not null;index:idx_notification_user_read"`
+	Type           string                 `json:"type" gorm:"type:varchar(50);not null;default:''"`
+	Title          string                 `json:"title" gorm:"type:varchar(255);not null;default:''"`
+	Content        string                 `json:"content" gorm:"type:text;not null"`
+	Data           map[string]interface{} `json:"data" gorm:"type:jsonb;serializer:json"`
+	IsRead         bool                   `json:"is_read" gorm:"default:false;index:idx_notification_user_read"`
+	ReadAt         *time.Time             `json:"read_at"`
+	DedupeKey      *string                `json:"-" gorm:"type:varchar(255);uniqueIndex"`
+	BaseModel
+}
+
+// NotificationPreference stores a user's opt-in per notification type and
+// channel. A missing row means both channels are enabled.
+type NotificationPreference struct {
+	UserID       uuid.UUID `json:"-" gorm:"type:uuid;primaryKey"`
+	Type         string    `json:"type" gorm:"type:varchar(50);primaryKey"`
+	InAppEnabled bool      `json:"in_app_enabled" gorm:"not null"`
+	EmailEnabled bool      `json:"email_enabled" gorm:"not null;default:true"`
+	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
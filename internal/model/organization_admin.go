@@ -6,6 +6,16 @@ import (
 	"github.com/google/uuid"
 )
 
+// Organization roles. Reviewers only review evidence; owners and admins are
+// staff.
+const (
+	OrgRoleOwner    = "owner"
+	OrgRoleAdmin    = "admin"
+	OrgRoleReviewer = "reviewer"
+)
+
+var OrgRoles = []string{OrgRoleOwner, OrgRoleAdmin, OrgRoleReviewer}
+
 type OrganizationAdmin struct {
 	AdminID   uuid.UUID `json:"admin_id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
 	OrgID     uuid.UUID `json:"org_id" gorm:"type:uuid;not null;index:idx_org_user,unique"`
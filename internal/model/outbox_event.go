@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Domain event types written to the outbox.
+const (
+	EventBadgeIssued                = "badge.issued"
+	EventParticipationCreated       = "participation.created"
+	EventParticipationUpdated       = "participation.updated"
+	EventParticipationStatusChanged = "participation.status_changed"
+	EventActivityStartingSoon       = "activity.starting_soon"
+	EventActivityCancelled          = "activity.cancelled"
+	EventActivityRescheduled        = "activity.rescheduled"
+	EventOrganizationAdminAdded     = "organization.admin_added"
+	EventEvidenceSubmitted          = "evidence.submitted"
+	EventEvidenceReviewed           = "evidence.reviewed"
+)
+
+// OutboxEvent is a domain event stored in the same transaction as the state
+// change that produced it. The dispatcher publishes it to every consumer.
+type OutboxEvent struct {
+	EventID       uuid.UUID              `json:"event_id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	AggregateType string                 `json:"aggregate_type" gorm:"type:varchar(50);not null"`
+	AggregateID   uuid.UUID              `json:"aggregate_id" gorm:"type:uuid;not null"`
+	EventType     string                 `json:"event_type" gorm:"type:varchar(100);not null;index"`
+	Payload       map[string]interface{} `json:"payload" gorm:"type:jsonb;serializer:json"`
+	OccurredAt    time.Time              `json:"occurred_at" gorm:"autoCreateTime;index"`
+	DispatchedAt  *time.Time             `json:"dispatched_at" gorm:"index"`
+	LockedUntil   *time.Time             `json:"-"`
+	Attempts      int                    `json:"attempts" gorm:"default:0"`
+	LastError     *string                `json:"last_error" gorm:"type:text"`
+}
+
+// OutboxDelivery records that a consumer has handled an event, so each
+// consumer sees every event at most once.
+type OutboxDelivery struct {
+	EventID     uuid.UUID `json:"event_id" gorm:"type:uuid;primaryKey"`
+	Consumer    string    `json:"consumer" gorm:"type:varchar(100);primaryKey"`
+	DeliveredAt time.Time `json:"delivered_at" gorm:"autoCreateTime"`
+}
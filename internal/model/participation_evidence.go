@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Evidence review statuses.
+const (
+	EvidencePending          = "PENDING"
+	EvidenceApproved         = "APPROVED"
+	EvidenceRejected         = "REJECTED"
+	EvidenceChangesRequested = "CHANGES_REQUESTED"
+)
+
+// ParticipationEvidence is one item of proof submitted for a participation:
+// either a link (URL) or an uploaded file (ObjectID). OrgID and ActivityID are
+// copied from the activity for the review queue.
+type ParticipationEvidence struct {
+	EvidenceID      uuid.UUID  `json:"evidence_id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ParticipationID uuid.UUID  `json:"participation_id" gorm:"type:uuid;not null;index"`
+	ActivityID      uuid.UUID  `json:"activity_id" gorm:"type:uuid;not null"`
+	OrgID           uuid.UUID  `json:"org_id" gorm:"type:uuid;not null;index:idx_evidence_org_status"`
+	UserID          uuid.UUID  `json:"user_id" gorm:"type:uuid;not null"`
+	URL             *string    `json:"url" gorm:"type:varchar(255)"`
+	ObjectID        *uuid.UUID `json:"object_id" gorm:"type:uuid"`
+	Note            *string    `json:"note" gorm:"type:text"`
+	Status          string     `json:"status" gorm:"type:varchar(20);not null;default:'PENDING';index:idx_evidence_org_status"`
+	ReviewedBy      *uuid.UUID `json:"reviewed_by" gorm:"type:uuid"`
+	ReviewedAt      *time.Time `json:"reviewed_at"`
+	SubmittedAt     time.Time  `json:"submitted_at" gorm:"autoCreateTime"`
+
+	Comments []EvidenceComment `json:"comments,omitempty" gorm:"-"`
+}
+
+// EvidenceComment is a remark by a reviewer or the participant on an
+// evidence item. Review decisions are recorded as comments too.
+type EvidenceComment struct {
+	CommentID  uuid.UUID `json:"comment_id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	EvidenceID uuid.UUID `json:"evidence_id" gorm:"type:uuid;not null;index"`
+	AuthorID   uuid.UUID `json:"author_id" gorm:"type:uuid;not null"`
+	// Decision is the review decision the comment came with, if any.
+	Decision  *string   `json:"decision" gorm:"type:varchar(20)"`
+	Body      string    `json:"body" gorm:"type:text;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
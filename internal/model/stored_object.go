@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Stored object purposes.
+const (
+	ObjectEvidence   = "evidence"
+	ObjectBadgeImage = "badge_image"
+	ObjectOrgLogo    = "org_logo"
+)
+
+// StoredObject records a file kept in the storage backend. Key locates it
+// there; Public objects (badge images, logos) may be downloaded by anyone.
+type StoredObject struct {
+	ObjectID     uuid.UUID `json:"object_id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Key          string    `json:"-" gorm:"type:varchar(255);not null;uniqueIndex"`
+	Purpose      string    `json:"purpose" gorm:"type:varchar(20);not null"`
+	ContentType  string    `json:"content_type" gorm:"type:varchar(100);not null"`
+	Size         int64     `json:"size" gorm:"not null"`
+	SHA256       string    `json:"sha256" gorm:"type:char(64);not null"`
+	OriginalName *string   `json:"original_name" gorm:"type:varchar(255)"`
+	OwnerID      uuid.UUID `json:"owner_id" gorm:"type:uuid;not null"`
+	Public       bool      `json:"public" gorm:"not null;default:false"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
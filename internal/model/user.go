@@ -23,5 +23,8 @@ type User struct {
 	Bio               *string   `json:"bio" gorm:"type:text"`
 	Role              string    `json:"role" gorm:"type:varchar(20);default:'USER'"`
 	PrivacySetting    string    `json:"privacy_setting" gorm:"type:varchar(20);default:'public'"`
+	Locale            string    `json:"locale" gorm:"type:varchar(10);default:'en'"`
+	// CalendarToken authenticates the user's private calendar feed URL.
+	CalendarToken *string `json:"-" gorm:"type:varchar(64);uniqueIndex"`
 	BaseModel
 }
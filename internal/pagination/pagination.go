@@ -0,0 +1,186 @@
+// Package pagination parses list paging parameters, pages gorm queries by
+// offset or by keyset, and builds the response envelope and Link header.
+//
+// Offset mode is selected by page and limit. Keyset mode is selected by the
+// cursor parameter, left empty for the first page; it walks a table in
+// (created_at, id) order, which stays fast and stable on large tables.
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"ping-badge-be/internal/constant"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is a position in (created_at, id) order: the last item of a page.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// String encodes the cursor as an opaque URL-safe token.
+func (c Cursor) String() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "," + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseCursor decodes a token made by Cursor.String.
+func ParseCursor(token string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	createdAt, id, ok := strings.Cut(string(raw), ",")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	var cursor Cursor
+	if cursor.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.ID, err = uuid.Parse(id); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// Params are the paging parameters of a list request. In keyset mode Page is
+// zero and After is the cursor to continue from, nil on the first page.
+type Params struct {
+	Page   int
+	Limit  int
+	Keyset bool
+	After  *Cursor
+}
+
+// FromQuery reads page, limit and cursor. Missing or out of range page and
+// limit values fall back to the defaults; only a malformed cursor is an error.
+func FromQuery(query url.Values) (Params, error) {
+	params := Params{Page: constant.DefaultPage, Limit: constant.DefaultLimit}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit > 0 && limit <= constant.MaxLimit {
+		params.Limit = limit
+	}
+	if _, ok := query["cursor"]; ok {
+		params.Page = 0
+		params.Keyset = true
+		if token := query.Get("cursor"); token != "" {
+			after, err := ParseCursor(token)
+			if err != nil {
+				return Params{}, err
+			}
+			params.After = after
+		}
+		return params, nil
+	}
+	if page, err := strconv.Atoi(query.Get("page")); err == nil && page > 0 {
+		params.Page = page
+	}
+	return params, nil
+}
+
+// Offset is the number of items before the page in offset mode.
+func (p Params) Offset() int {
+	if p.Page < 1 {
+		return 0
+	}
+	return (p.Page - 1) * p.Limit
+}
+
+// Keyset names the columns a table is walked by in keyset mode.
+type Keyset struct {
+	Table     string
+	CreatedAt string
+	ID        string
+}
+
+// Apply pages query. In keyset mode it replaces the query's order with the
+// keyset columns and starts after the cursor.
+func (p Params) Apply(query *gorm.DB, keys Keyset) *gorm.DB {
+	if !p.Keyset {
+		return query.Offset(p.Offset()).Limit(p.Limit)
+	}
+	if p.After != nil {
+		query = query.Where(
+			fmt.Sprintf("(%s.%s, %s.%s) > (?, ?)", keys.Table, keys.CreatedAt, keys.Table, keys.ID),
+			p.After.CreatedAt, p.After.ID,
+		)
+	}
+	return query.Clauses(clause.OrderBy{Columns: []clause.OrderByColumn{
+		{Column: clause.Column{Table: keys.Table, Name: keys.CreatedAt}, Reorder: true},
+		{Column: clause.Column{Table: keys.Table, Name: keys.ID}},
+	}}).Limit(p.Limit)
+}
+
+// Page is the response envelope of a list endpoint. Page is omitted in keyset
+// mode; NextCursor is set in keyset mode while a full page was returned.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int64  `json:"total"`
+	Page       int    `json:"page,omitempty"`
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// NewPage wraps items. cursorOf gives the keyset position of an item.
+func NewPage[T any](items []T, total int64, params Params, cursorOf func(T) Cursor) Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	page := Page[T]{Items: items, Total: total, Page: params.Page, Limit: params.Limit}
+	if params.Keyset && len(items) == params.Limit && len(items) > 0 {
+		page.NextCursor = cursorOf(items[len(items)-1]).String()
+	}
+	return page
+}
+
+// LinkHeader returns the RFC 8288 Link header for the page of a request to u:
+// first, prev, next and last in offset mode, and next in keyset mode.
+func (pg Page[T]) LinkHeader(u *url.URL) string {
+	link := func(rel string, set func(url.Values)) string {
+		query := u.Query()
+		set(query)
+		target := *u
+		target.RawQuery = query.Encode()
+		return fmt.Sprintf("<%s>; rel=%q", target.String(), rel)
+	}
+	var links []string
+	if pg.Page == 0 {
+		if pg.NextCursor != "" {
+			links = append(links, link("next", func(q url.Values) {
+				q.Set("cursor", pg.NextCursor)
+			}))
+		}
+		return strings.Join(links, ", ")
+	}
+
+	toPage := func(page int) func(url.Values) {
+		return func(q url.Values) {
+			q.Set("page", strconv.Itoa(page))
+			q.Set("limit", strconv.Itoa(pg.Limit))
+		}
+	}
+	last := 1
+	if pg.Limit > 0 && pg.Total > 0 {
+		last = int((pg.Total + int64(pg.Limit) - 1) / int64(pg.Limit))
+	}
+	links = append(links, link("first", toPage(1)))
+	if pg.Page > 1 {
+		links = append(links, link("prev", toPage(min(pg.Page-1, last))))
+	}
+	if pg.Page < last {
+		links = append(links, link("next", toPage(pg.Page+1)))
+	}
+	links = append(links, link("last", toPage(last)))
+	return strings.Join(links, ", ")
+}
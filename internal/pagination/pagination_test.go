@@ -0,0 +1,60 @@
+package pagination
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := Cursor{CreatedAt: time.Date(2026, 3, 1, 9, 30, 0, 123456000, time.UTC), ID: uuid.New()}
+	parsed, err := ParseCursor(cursor.String())
+	require.NoError(t, err)
+	assert.True(t, cursor.CreatedAt.Equal(parsed.CreatedAt))
+	assert.Equal(t, cursor.ID, parsed.ID)
+
+	_, err = ParseCursor("not-a-cursor")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestFromQuery(t *testing.T) {
+	params, err := FromQuery(url.Values{"page": {"3"}, "limit": {"25"}})
+	require.NoError(t, err)
+	assert.Equal(t, Params{Page: 3, Limit: 25}, params)
+	assert.Equal(t, 50, params.Offset())
+
+	params, err = FromQuery(url.Values{"page": {"-1"}, "limit": {"1000"}})
+	require.NoError(t, err)
+	assert.Equal(t, Params{Page: 1, Limit: 10}, params)
+
+	params, err = FromQuery(url.Values{"cursor": {""}, "page": {"4"}})
+	require.NoError(t, err)
+	assert.True(t, params.Keyset)
+	assert.Nil(t, params.After)
+	assert.Zero(t, params.Page)
+
+	_, err = FromQuery(url.Values{"cursor": {"%%%"}})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestLinkHeader(t *testing.T) {
+	u, _ := url.Parse("/api/v1/users?page=2&limit=10&q=x")
+	page := NewPage([]int{1, 2}, 35, Params{Page: 2, Limit: 10}, nil)
+	assert.Equal(t,
+		`</api/v1/users?limit=10&page=1&q=x>; rel="first", `+
+			`</api/v1/users?limit=10&page=1&q=x>; rel="prev", `+
+			`</api/v1/users?limit=10&page=3&q=x>; rel="next", `+
+			`</api/v1/users?limit=10&page=4&q=x>; rel="last"`,
+		page.LinkHeader(u))
+
+	id := uuid.New()
+	cursorOf := func(int) Cursor { return Cursor{ID: id} }
+	keyset := NewPage([]int{1, 2}, 35, Params{Limit: 2, Keyset: true}, cursorOf)
+	require.NotEmpty(t, keyset.NextCursor)
+	assert.Contains(t, keyset.LinkHeader(u), `rel="next"`)
+	assert.Empty(t, NewPage([]int{1}, 35, Params{Limit: 2, Keyset: true}, cursorOf).NextCursor)
+}
@@ -0,0 +1,104 @@
+package realtime
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+)
+
+// subscriptionBuffer is how many messages a slow subscriber may fall behind
+// before further messages are dropped for it.
+const subscriptionBuffer = 64
+
+// Message is an event delivered to stream subscribers of Topic.
+type Message struct {
+	ID    string          `json:"id"`
+	Topic string          `json:"topic"`
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// Hub fans messages out to the subscribers connected to this instance.
+type Hub struct {
+	mu     sync.RWMutex
+	topics map[string]map[*Subscription]struct{}
+	closed bool
+}
+
+func NewHub() *Hub {
+	return &Hub{topics: make(map[string]map[*Subscription]struct{})}
+}
+
+// Subscription receives the messages published to any of its topics.
+type Subscription struct {
+	hub    *Hub
+	topics []string
+	ch     chan Message
+	once   sync.Once
+}
+
+// Subscribe registers a subscription for topics. The caller must Close it.
+func (h *Hub) Subscribe(topics ...string) *Subscription {
+	sub := &Subscription{hub: h, topics: topics, ch: make(chan Message, subscriptionBuffer)}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(sub.ch)
+		return sub
+	}
+	for _, topic := range topics {
+		if h.topics[topic] == nil {
+			h.topics[topic] = make(map[*Subscription]struct{})
+		}
+		h.topics[topic][sub] = struct{}{}
+	}
+	return sub
+}
+
+// Messages returns the channel of delivered messages. It is closed when the
+// subscription or the hub is closed.
+func (s *Subscription) Messages() <-chan Message {
+	return s.ch
+}
+
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+// remove unregisters s. The caller must hold h.mu.
+func (h *Hub) remove(s *Subscription) {
+	for _, topic := range s.topics {
+		delete(h.topics[topic], s)
+		if len(h.topics[topic]) == 0 {
+			delete(h.topics, topic)
+		}
+	}
+	s.once.Do(func() { close(s.ch) })
+}
+
+// Publish delivers msg to the local subscribers of msg.Topic without blocking.
+func (h *Hub) Publish(msg Message) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for sub := range h.topics[msg.Topic] {
+		select {
+		case sub.ch <- msg:
+		default:
+			log.Printf("realtime: dropping %s for slow subscriber on %s", msg.Event, msg.Topic)
+		}
+	}
+}
+
+// Close ends every subscription so open streams return during shutdown.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for _, subs := range h.topics {
+		for sub := range subs {
+			h.remove(sub)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Channel is the Postgres NOTIFY channel carrying realtime messages between
+// API instances.
+const Channel = "realtime"
+
+// Listener relays notifications on Channel into the local Hub. Every API
+// instance runs one, so a message published by any instance reaches all
+// connected clients.
+type Listener struct {
+	databaseURL string
+	hub         *Hub
+}
+
+func NewListener(databaseURL string, hub *Hub) *Listener {
+	return &Listener{databaseURL: databaseURL, hub: hub}
+}
+
+// Run listens until ctx is cancelled, reconnecting with backoff when the
+// connection drops.
+func (l *Listener) Run(ctx context.Context) {
+	backoff := time.Second
+	for {
+		err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("realtime: listener disconnected: %v", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+func (l *Listener) listen(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, l.databaseURL)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+Channel); err != nil {
+		return err
+	}
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var msg Message
+		if err := json.Unmarshal([]byte(notification.Payload), &msg); err != nil {
+			log.Printf("realtime: invalid notification payload: %v", err)
+			continue
+		}
+		l.hub.Publish(msg)
+	}
+}
@@ -0,0 +1,51 @@
+package realtime
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Topic kinds a client can subscribe to.
+const (
+	KindActivityParticipations = "activity_participations"
+	KindUserParticipations     = "user_participations"
+	KindUserBadges             = "user_badges"
+)
+
+var ErrInvalidTopic = errors.New("invalid topic")
+
+func ActivityParticipationsTopic(activityID uuid.UUID) string {
+	return "activity:" + activityID.String() + ":participations"
+}
+
+func UserParticipationsTopic(userID uuid.UUID) string {
+	return "user:" + userID.String() + ":participations"
+}
+
+func UserBadgesTopic(userID uuid.UUID) string {
+	return "user:" + userID.String() + ":badges"
+}
+
+// ParseTopic splits a topic such as "activity:<id>:participations" into its
+// kind and the ID it is scoped to.
+func ParseTopic(topic string) (string, uuid.UUID, error) {
+	parts := strings.Split(topic, ":")
+	if len(parts) != 3 {
+		return "", uuid.Nil, ErrInvalidTopic
+	}
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return "", uuid.Nil, ErrInvalidTopic
+	}
+	switch parts[0] + ":" + parts[2] {
+	case "activity:participations":
+		return KindActivityParticipations, id, nil
+	case "user:participations":
+		return KindUserParticipations, id, nil
+	case "user:badges":
+		return KindUserBadges, id, nil
+	}
+	return "", uuid.Nil, ErrInvalidTopic
+}
@@ -0,0 +1,413 @@
+// Package recurrence expands RFC 5545 recurrence rules into occurrences.
+//
+// The supported subset is FREQ=DAILY|WEEKLY|MONTHLY with INTERVAL, COUNT,
+// UNTIL, BYDAY (with ordinals for MONTHLY, e.g. 2TU or -1FR), BYMONTHDAY and
+// WKST=MO. Occurrences keep DTSTART's wall-clock time in its location, so a
+// weekly 09:00 session stays at 09:00 across daylight saving changes.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequencies.
+const (
+	Daily   = "DAILY"
+	Weekly  = "WEEKLY"
+	Monthly = "MONTHLY"
+)
+
+// maxPeriods bounds the expansion of rules that rarely match, such as
+// BYMONTHDAY=31 with INTERVAL=2.
+const maxPeriods = 10000
+
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+// Weekday is a BYDAY entry. N is the ordinal within the month (1 is the
+// first, -1 the last); zero means every such weekday.
+type Weekday struct {
+	Day time.Weekday
+	N   int
+}
+
+type Rule struct {
+	Freq       string
+	Interval   int
+	Count      int
+	Until      *time.Time
+	ByDay      []Weekday
+	ByMonthDay []int
+}
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Parse parses an RRULE value, with or without the "RRULE:" prefix. A
+// floating or date-only UNTIL is interpreted in loc; a date-only UNTIL
+// includes the whole day.
+func Parse(value string, loc *time.Location) (*Rule, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	if value == "" {
+		return nil, fmt.Errorf("%w: empty rule", ErrInvalidRule)
+	}
+	rule := &Rule{Interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" {
+			return nil, fmt.Errorf("%w: malformed part %q", ErrInvalidRule, part)
+		}
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			switch val {
+			case Daily, Weekly, Monthly:
+				rule.Freq = val
+			default:
+				return nil, fmt.Errorf("%w: unsupported FREQ %q", ErrInvalidRule, val)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: INTERVAL must be a positive integer", ErrInvalidRule)
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: COUNT must be a positive integer", ErrInvalidRule)
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, err := parseUntil(val, loc)
+			if err != nil {
+				return nil, err
+			}
+			rule.Until = &until
+		case "BYDAY":
+			for _, item := range strings.Split(val, ",") {
+				wd, err := parseWeekday(item)
+				if err != nil {
+					return nil, err
+				}
+				rule.ByDay = append(rule.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, item := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(item)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("%w: invalid BYMONTHDAY %q", ErrInvalidRule, item)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+			}
+		case "WKST":
+			if val != "MO" {
+				return nil, fmt.Errorf("%w: only WKST=MO is supported", ErrInvalidRule)
+			}
+		default:
+			return nil, fmt.Errorf("%w: unsupported part %q", ErrInvalidRule, key)
+		}
+	}
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("%w: FREQ is required", ErrInvalidRule)
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, fmt.Errorf("%w: COUNT and UNTIL are mutually exclusive", ErrInvalidRule)
+	}
+	for _, wd := range rule.ByDay {
+		if wd.N != 0 && rule.Freq != Monthly {
+			return nil, fmt.Errorf("%w: BYDAY ordinals require FREQ=MONTHLY", ErrInvalidRule)
+		}
+	}
+	return rule, nil
+}
+
+// Bounded reports whether the rule ends by COUNT or UNTIL.
+func (r *Rule) Bounded() bool {
+	return r.Count > 0 || r.Until != nil
+}
+
+// Occurrences returns up to max start times generated from dtstart, in
+// order, leaving out the exception dates. Excluded occurrences still count
+// towards COUNT, as in RFC 5545.
+func (r *Rule) Occurrences(dtstart time.Time, exceptions []time.Time, max int) []time.Time {
+	var out []time.Time
+	generated := 0
+	for period := 0; period < maxPeriods; period++ {
+		for _, t := range r.expand(dtstart, period) {
+			if t.Before(dtstart) {
+				continue
+			}
+			if r.Until != nil && t.After(*r.Until) {
+				return out
+			}
+			generated++
+			if !contains(exceptions, t) {
+				out = append(out, t)
+			}
+			if (r.Count > 0 && generated >= r.Count) || len(out) >= max {
+				return out
+			}
+		}
+	}
+	return out
+}
+
+// expand returns the candidate times of the n-th period after dtstart.
+func (r *Rule) expand(dtstart time.Time, n int) []time.Time {
+	y, m, d := dtstart.Date()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, dtstart.Hour(), dtstart.Minute(), dtstart.Second(), 0, dtstart.Location())
+	}
+
+	switch r.Freq {
+	case Daily:
+		t := at(y, m, d+n*r.Interval)
+		if r.matchesWeekday(t) && r.matchesMonthDay(t) {
+			return []time.Time{t}
+		}
+		return nil
+
+	case Weekly:
+		monday := d - mondayOffset(dtstart.Weekday()) + 7*n*r.Interval
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []Weekday{{Day: dtstart.Weekday()}}
+		}
+		var out []time.Time
+		for _, wd := range days {
+			out = append(out, at(y, m, monday+mondayOffset(wd.Day)))
+		}
+		return sortUnique(out)
+
+	case Monthly:
+		first := time.Date(y, m+time.Month(n*r.Interval), 1, 0, 0, 0, 0, dtstart.Location())
+		year, month := first.Year(), first.Month()
+		daysInMonth := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+
+		var days []int
+		switch {
+		case len(r.ByMonthDay) > 0:
+			for _, md := range r.ByMonthDay {
+				if md < 0 {
+					md = daysInMonth + md + 1
+				}
+				if md >= 1 && md <= daysInMonth {
+					days = append(days, md)
+				}
+			}
+		case len(r.ByDay) > 0:
+			for _, wd := range r.ByDay {
+				days = append(days, monthWeekdays(year, month, daysInMonth, wd)...)
+			}
+		default:
+			if d <= daysInMonth {
+				days = append(days, d)
+			}
+		}
+
+		var out []time.Time
+		for _, day := range days {
+			t := at(year, month, day)
+			if len(r.ByMonthDay) > 0 && !r.matchesWeekday(t) {
+				continue
+			}
+			out = append(out, t)
+		}
+		return sortUnique(out)
+	}
+	return nil
+}
+
+func (r *Rule) matchesWeekday(t time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, wd := range r.ByDay {
+		if wd.Day == t.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchesMonthDay(t time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	daysInMonth := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, md := range r.ByMonthDay {
+		if md == t.Day() || (md < 0 && daysInMonth+md+1 == t.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+// monthWeekdays returns the days of the month matching wd.
+func monthWeekdays(year int, month time.Month, daysInMonth int, wd Weekday) []int {
+	var matches []int
+	firstWeekday := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC).Weekday()
+	for day := 1 + (int(wd.Day)-int(firstWeekday)+7)%7; day <= daysInMonth; day += 7 {
+		matches = append(matches, day)
+	}
+	switch {
+	case wd.N > 0 && wd.N <= len(matches):
+		return []int{matches[wd.N-1]}
+	case wd.N < 0 && -wd.N <= len(matches):
+		return []int{matches[len(matches)+wd.N]}
+	case wd.N == 0:
+		return matches
+	}
+	return nil
+}
+
+func parseWeekday(value string) (Weekday, error) {
+	if len(value) < 2 {
+		return Weekday{}, fmt.Errorf("%w: invalid BYDAY %q", ErrInvalidRule, value)
+	}
+	day, ok := weekdays[value[len(value)-2:]]
+	if !ok {
+		return Weekday{}, fmt.Errorf("%w: invalid BYDAY %q", ErrInvalidRule, value)
+	}
+	wd := Weekday{Day: day}
+	if prefix := value[:len(value)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return Weekday{}, fmt.Errorf("%w: invalid BYDAY %q", ErrInvalidRule, value)
+		}
+		wd.N = n
+	}
+	return wd, nil
+}
+
+func parseUntil(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(untilUTC, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(untilFloating, value, loc); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(untilDate, value, loc); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("%w: invalid UNTIL %q", ErrInvalidRule, value)
+}
+
+// UNTIL forms, as accepted by parseUntil.
+const (
+	untilUTC      = "20060102T150405Z"
+	untilFloating = "20060102T150405"
+	untilDate     = "20060102"
+)
+
+// ShiftUntil moves the UNTIL of value as far as the series moves when it
+// starts at to instead of from: by the same number of days in loc and the
+// same change in time of day, so a series ending on its last session still
+// does across daylight saving changes. The form UNTIL is written in is kept.
+// Rules without UNTIL are returned unchanged.
+func ShiftUntil(value string, from, to time.Time, loc *time.Location) (string, error) {
+	from, to = from.In(loc), to.In(loc)
+	days := int(dateOf(to).Sub(dateOf(from)) / (24 * time.Hour))
+	clock := timeOfDay(to) - timeOfDay(from)
+	shift := func(t time.Time) time.Time { return t.In(loc).AddDate(0, 0, days).Add(clock) }
+	return replaceUntil(value, func(until string) (string, error) {
+		if t, err := time.Parse(untilUTC, until); err == nil {
+			return "UNTIL=" + shift(t).UTC().Format(untilUTC), nil
+		}
+		if t, err := time.ParseInLocation(untilFloating, until, loc); err == nil {
+			return "UNTIL=" + shift(t).Format(untilFloating), nil
+		}
+		if t, err := time.ParseInLocation(untilDate, until, loc); err == nil {
+			return "UNTIL=" + t.AddDate(0, 0, days).Format(untilDate), nil
+		}
+		return "", fmt.Errorf("%w: invalid UNTIL %q", ErrInvalidRule, until)
+	})
+}
+
+// UntilToCount replaces the UNTIL of value with the COUNT of occurrences it
+// allows from dtstart, so the rule keeps its length wherever it starts.
+// Rules without UNTIL are returned unchanged.
+func UntilToCount(value string, dtstart time.Time, loc *time.Location) (string, error) {
+	rule, err := Parse(value, loc)
+	if err != nil {
+		return "", err
+	}
+	if rule.Until == nil {
+		return value, nil
+	}
+	count := len(rule.Occurrences(dtstart.In(loc), nil, maxPeriods))
+	if count == 0 {
+		return "", fmt.Errorf("%w: UNTIL is before the start", ErrInvalidRule)
+	}
+	return replaceUntil(value, func(string) (string, error) {
+		return "COUNT=" + strconv.Itoa(count), nil
+	})
+}
+
+// replaceUntil rewrites the UNTIL part of value with replace, leaving the
+// other parts as written.
+func replaceUntil(value string, replace func(until string) (string, error)) (string, error) {
+	prefix := ""
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "RRULE:") {
+		prefix, value = "RRULE:", strings.TrimPrefix(value, "RRULE:")
+	}
+	parts := strings.Split(value, ";")
+	for i, part := range parts {
+		key, val, _ := strings.Cut(part, "=")
+		if !strings.EqualFold(key, "UNTIL") {
+			continue
+		}
+		replaced, err := replace(val)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = replaced
+	}
+	return prefix + strings.Join(parts, ";"), nil
+}
+
+func dateOf(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func timeOfDay(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
+
+// mondayOffset is the number of days from Monday to wd.
+func mondayOffset(wd time.Weekday) int {
+	return (int(wd) + 6) % 7
+}
+
+func sortUnique(times []time.Time) []time.Time {
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	out := times[:0]
+	for i, t := range times {
+		if i == 0 || !t.Equal(times[i-1]) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func contains(times []time.Time, t time.Time) bool {
+	for _, candidate := range times {
+		if candidate.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func occurrences(t *testing.T, rule string, dtstart time.Time, exceptions ...time.Time) []time.Time {
+	t.Helper()
+	r, err := Parse(rule, dtstart.Location())
+	require.NoError(t, err, rule)
+	return r.Occurrences(dtstart, exceptions, 100)
+}
+
+func TestWeeklyByDayWithCount(t *testing.T) {
+	start := time.Date(2026, time.March, 2, 18, 0, 0, 0, time.UTC) // Monday
+	got := occurrences(t, "RRULE:FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4", start)
+	assert.Equal(t, []time.Time{
+		time.Date(2026, time.March, 2, 18, 0, 0, 0, time.UTC),
+		time.Date(2026, time.March, 4, 18, 0, 0, 0, time.UTC),
+		time.Date(2026, time.March, 9, 18, 0, 0, 0, time.UTC),
+		time.Date(2026, time.March, 11, 18, 0, 0, 0, time.UTC),
+	}, got)
+}
+
+func TestExceptionsStillCountTowardsCount(t *testing.T) {
+	start := time.Date(2026, time.March, 2, 18, 0, 0, 0, time.UTC)
+	skipped := time.Date(2026, time.March, 9, 18, 0, 0, 0, time.UTC)
+	got := occurrences(t, "FREQ=WEEKLY;COUNT=3", start, skipped)
+	assert.Equal(t, []time.Time{
+		time.Date(2026, time.March, 2, 18, 0, 0, 0, time.UTC),
+		time.Date(2026, time.March, 16, 18, 0, 0, 0, time.UTC),
+	}, got)
+}
+
+func TestDailyIntervalUntil(t *testing.T) {
+	start := time.Date(2026, time.January, 30, 9, 0, 0, 0, time.UTC)
+	got := occurrences(t, "FREQ=DAILY;INTERVAL=2;UNTIL=20260205", start)
+	assert.Equal(t, []time.Time{
+		time.Date(2026, time.January, 30, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, time.February, 1, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, time.February, 3, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, time.February, 5, 9, 0, 0, 0, time.UTC),
+	}, got)
+}
+
+func TestMonthlyOrdinalWeekday(t *testing.T) {
+	start := time.Date(2026, time.January, 1, 19, 0, 0, 0, time.UTC)
+	got := occurrences(t, "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3", start)
+	assert.Equal(t, []time.Time{
+		time.Date(2026, time.January, 30, 19, 0, 0, 0, time.UTC),
+		time.Date(2026, time.February, 27, 19, 0, 0, 0, time.UTC),
+		time.Date(2026, time.March, 27, 19, 0, 0, 0, time.UTC),
+	}, got)
+}
+
+func TestMonthlySkipsMonthsWithoutTheDay(t *testing.T) {
+	start := time.Date(2026, time.January, 31, 8, 0, 0, 0, time.UTC)
+	got := occurrences(t, "FREQ=MONTHLY;COUNT=3", start)
+	assert.Equal(t, []time.Time{
+		time.Date(2026, time.January, 31, 8, 0, 0, 0, time.UTC),
+		time.Date(2026, time.March, 31, 8, 0, 0, 0, time.UTC),
+		time.Date(2026, time.May, 31, 8, 0, 0, 0, time.UTC),
+	}, got)
+}
+
+func TestWallClockTimeKeptAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	start := time.Date(2026, time.March, 5, 9, 0, 0, 0, loc)
+	got := occurrences(t, "FREQ=WEEKLY;COUNT=2", start)
+	require.Len(t, got, 2)
+	assert.Equal(t, 9, got[1].Hour())
+	assert.Equal(t, 167*time.Hour, got[1].Sub(got[0]))
+}
+
+func TestParseRejectsInvalidRules(t *testing.T) {
+	for _, rule := range []string{
+		"",
+		"COUNT=3",
+		"FREQ=YEARLY",
+		"FREQ=WEEKLY;INTERVAL=0",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=WEEKLY;BYDAY=2MO",
+		"FREQ=DAILY;COUNT=2;UNTIL=20260101",
+		"FREQ=DAILY;BYSETPOS=1",
+	} {
+		_, err := Parse(rule, time.UTC)
+		assert.ErrorIs(t, err, ErrInvalidRule, rule)
+	}
+}
+
+func TestShiftUntil(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	from := time.Date(2026, time.March, 2, 9, 0, 0, 0, loc)
+	to := time.Date(2026, time.September, 7, 9, 0, 0, 0, loc) // 27 weeks later, across DST
+
+	shifted, err := ShiftUntil("RRULE:FREQ=WEEKLY;UNTIL=20260323T080000Z", from, to, loc)
+	require.NoError(t, err)
+	assert.Equal(t, "RRULE:FREQ=WEEKLY;UNTIL=20260928T070000Z", shifted)
+	assert.Len(t, occurrences(t, shifted, to), 4)
+
+	shifted, err = ShiftUntil("FREQ=DAILY;UNTIL=20260305;INTERVAL=2", from, to, loc)
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=DAILY;UNTIL=20260910;INTERVAL=2", shifted)
+
+	unchanged, err := ShiftUntil("FREQ=WEEKLY;COUNT=4", from, to, loc)
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=WEEKLY;COUNT=4", unchanged)
+}
+
+func TestUntilToCount(t *testing.T) {
+	start := time.Date(2026, time.January, 30, 9, 0, 0, 0, time.UTC)
+	rule, err := UntilToCount("FREQ=DAILY;INTERVAL=2;UNTIL=20260205", start, time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=DAILY;INTERVAL=2;COUNT=4", rule)
+
+	_, err = UntilToCount("FREQ=DAILY;UNTIL=20260101", start, time.UTC)
+	assert.ErrorIs(t, err, ErrInvalidRule)
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"ping-badge-be/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ActivityCoHostRepository interface {
+	Save(ctx context.Context, host *model.ActivityCoHost) error
+	Get(ctx context.Context, activityID, orgID uuid.UUID) (*model.ActivityCoHost, error)
+	ListByActivity(ctx context.Context, activityID uuid.UUID, acceptedOnly bool) ([]model.ActivityCoHost, error)
+	ListPending(ctx context.Context, orgID uuid.UUID) ([]model.ActivityCoHost, error)
+	Accept(ctx context.Context, activityID, orgID uuid.UUID) error
+	Delete(ctx context.Context, activityID, orgID uuid.UUID) error
+}
+
+type activityCoHostRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewActivityCoHostRepository(db *gorm.DB) ActivityCoHostRepository {
+	return &activityCoHostRepositoryImpl{db: db}
+}
+
+// Save adds the co-host or replaces its permissions and badge. A co-host that
+// already accepted stays accepted.
+func (r *activityCoHostRepositoryImpl) Save(ctx context.Context, host *model.ActivityCoHost) error {
+	updates := append(clause.AssignmentColumns([]string{"permissions", "badge_def_id", "updated_at"}), clause.Assignment{
+		Column: clause.Column{Name: "accepted_at"},
+		Value:  gorm.Expr("COALESCE(activity_co_hosts.accepted_at, EXCLUDED.accepted_at)"),
+	})
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "activity_id"}, {Name: "org_id"}},
+		DoUpdates: updates,
+	}).Create(host).Error
+}
+
+func (r *activityCoHostRepositoryImpl) Get(ctx context.Context, activityID, orgID uuid.UUID) (*model.ActivityCoHost, error) {
+	var host model.ActivityCoHost
+	err := r.db.WithContext(ctx).First(&host, "activity_id = ? AND org_id = ?", activityID, orgID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &host, nil
+}
+
+func (r *activityCoHostRepositoryImpl) ListByActivity(ctx context.Context, activityID uuid.UUID, acceptedOnly bool) ([]model.ActivityCoHost, error) {
+	var hosts []model.ActivityCoHost
+	query := r.db.WithContext(ctx).Where("activity_id = ?", activityID)
+	if acceptedOnly {
+		query = query.Where("accepted_at IS NOT NULL")
+	}
+	err := query.Order("created_at ASC").Find(&hosts).Error
+	return hosts, err
+}
+
+// ListPending returns the co-host invitations the organization has not
+// accepted yet, oldest first.
+func (r *activityCoHostRepositoryImpl) ListPending(ctx context.Context, orgID uuid.UUID) ([]model.ActivityCoHost, error) {
+	var hosts []model.ActivityCoHost
+	err := r.db.WithContext(ctx).
+		Where("org_id = ? AND accepted_at IS NULL", orgID).
+		Order("created_at ASC").
+		Find(&hosts).Error
+	return hosts, err
+}
+
+func (r *activityCoHostRepositoryImpl) Accept(ctx context.Context, activityID, orgID uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&model.ActivityCoHost{}).
+		Where("activity_id = ? AND org_id = ? AND accepted_at IS NULL", activityID, orgID).
+		Update("accepted_at", time.Now()).Error
+}
+
+// acceptedCoHosted selects the IDs of activities the organization has agreed
+// to co-host.
+func acceptedCoHosted(db *gorm.DB, orgID uuid.UUID) *gorm.DB {
+	return db.Model(&model.ActivityCoHost{}).Select("activity_id").Where("org_id = ? AND accepted_at IS NOT NULL", orgID)
+}
+
+func (r *activityCoHostRepositoryImpl) Delete(ctx context.Context, activityID, orgID uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&model.ActivityCoHost{}, "activity_id = ? AND org_id = ?", activityID, orgID).Error
+}
@@ -1,7 +1,10 @@
 package repository
 
 import (
+	"ping-badge-be/internal/listquery"
 	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -10,9 +13,51 @@ import (
 type ActivityParticipationRepository interface {
 	Create(participation *model.ActivityParticipation) error
 	FindByID(id uuid.UUID) (*model.ActivityParticipation, error)
-	FindAll(activityID *uuid.UUID, userID *uuid.UUID, status *string, offset, limit int) ([]model.ActivityParticipation, error)
+	FindAll(filter ParticipationFilter, page pagination.Params) ([]model.ActivityParticipation, error)
+	Count(filter ParticipationFilter) (int64, error)
+	FindByActivity(activityID uuid.UUID) ([]model.ActivityParticipation, error)
 	Update(id uuid.UUID, updates map[string]interface{}) (*model.ActivityParticipation, error)
 	Delete(id uuid.UUID) error
+	FindActive(activityID, userID uuid.UUID) (*model.ActivityParticipation, error)
+	CountRegistered(activityID uuid.UUID) (int64, error)
+	CountByStatus(activityID uuid.UUID, status string) (int64, error)
+	NextWaitlistPosition(activityID uuid.UUID) (int, error)
+	FindWaitlisted(activityID uuid.UUID, limit int) ([]model.ActivityParticipation, error)
+	RecordTransition(transition *model.ParticipationStatusTransition) error
+	ListTransitions(participationID uuid.UUID) ([]model.ParticipationStatusTransition, error)
+}
+
+// ParticipationListSchema lists the participation fields list requests may
+// filter and sort by.
+var ParticipationListSchema = listquery.Schema{
+	Table: "activity_participations",
+	ID:    "participation_id",
+	Fields: map[string]listquery.Field{
+		"status":      {Column: "status", Type: listquery.Enum, Values: model.ParticipationStatuses, Sortable: true},
+		"activity_id": {Column: "activity_id", Type: listquery.UUID},
+		"user_id":     {Column: "user_id", Type: listquery.UUID},
+		"created_at":  {Column: "created_at", Type: listquery.Time, Sortable: true},
+		"updated_at":  {Column: "updated_at", Type: listquery.Time, Sortable: true},
+	},
+}
+
+// ParticipationFilter selects participations. Zero fields do not filter.
+type ParticipationFilter struct {
+	ActivityID *uuid.UUID
+	UserID     *uuid.UUID
+	// Statuses keeps participations in any of the statuses.
+	Statuses []string
+	// OrgID keeps participations in activities the organization hosts or
+	// co-hosts.
+	OrgID *uuid.UUID
+	// CreatedFrom and CreatedTo bound when the participation was created.
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	HasEvidence *bool
+	// Criteria are the generic filters and sort of the request. Without a
+	// sort participations come in creation order, which is also the only
+	// order in keyset mode.
+	Criteria listquery.Query
 }
 
 type activityParticipationRepositoryImpl struct {
@@ -36,20 +81,68 @@ func (r *activityParticipationRepositoryImpl) FindByID(id uuid.UUID) (*model.Act
 	return &participation, nil
 }
 
-func (r *activityParticipationRepositoryImpl) FindAll(activityID *uuid.UUID, userID *uuid.UUID, status *string, offset, limit int) ([]model.ActivityParticipation, error) {
+func (r *activityParticipationRepositoryImpl) FindAll(filter ParticipationFilter, page pagination.Params) ([]model.ActivityParticipation, error) {
+	var participations []model.ActivityParticipation
+	query := r.filtered(filter)
+	if filter.Criteria.Sorted() {
+		query = filter.Criteria.Order(query)
+	} else {
+		query = query.Order("created_at ASC").Order("participation_id ASC")
+	}
+	err := page.Apply(query, pagination.Keyset{Table: "activity_participations", CreatedAt: "created_at", ID: "participation_id"}).Find(&participations).Error
+	return participations, err
+}
+
+// FindByActivity returns every participation of the activity in creation
+// order, for work that must reach all participants.
+func (r *activityParticipationRepositoryImpl) FindByActivity(activityID uuid.UUID) ([]model.ActivityParticipation, error) {
 	var participations []model.ActivityParticipation
-	query := r.db.Table("activity_participations")
-	if activityID != nil {
-		query = query.Where("activity_id = ?", *activityID)
+	err := r.db.Where("activity_id = ?", activityID).
+		Order("created_at ASC").
+		Order("participation_id ASC").
+		Find(&participations).Error
+	return participations, err
+}
+
+func (r *activityParticipationRepositoryImpl) Count(filter ParticipationFilter) (int64, error) {
+	var count int64
+	err := r.filtered(filter).Count(&count).Error
+	return count, err
+}
+
+func (r *activityParticipationRepositoryImpl) filtered(filter ParticipationFilter) *gorm.DB {
+	query := filter.Criteria.Where(r.db.Model(&model.ActivityParticipation{}))
+	if filter.ActivityID != nil {
+		query = query.Where("activity_id = ?", *filter.ActivityID)
 	}
-	if userID != nil {
-		query = query.Where("user_id = ?", *userID)
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
 	}
-	if status != nil {
-		query = query.Where("status = ?", *status)
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
 	}
-	err := query.Offset(offset).Limit(limit).Find(&participations).Error
-	return participations, err
+	if filter.OrgID != nil {
+		hosted := r.db.Model(&model.Activity{}).Select("activity_id").
+			Where("org_id = ? OR activity_id IN (?)", *filter.OrgID, acceptedCoHosted(r.db, *filter.OrgID))
+		query = query.Where("activity_id IN (?)", hosted)
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at <= ?", *filter.CreatedTo)
+	}
+	if filter.HasEvidence != nil {
+		evidence := r.db.Model(&model.ParticipationEvidence{}).
+			Select("1").
+			Where("participation_evidences.participation_id = activity_participations.participation_id")
+		if *filter.HasEvidence {
+			query = query.Where("EXISTS (?)", evidence)
+		} else {
+			query = query.Where("NOT EXISTS (?)", evidence)
+		}
+	}
+	return query
 }
 
 func (r *activityParticipationRepositoryImpl) Update(id uuid.UUID, updates map[string]interface{}) (*model.ActivityParticipation, error) {
@@ -67,3 +160,65 @@ func (r *activityParticipationRepositoryImpl) Update(id uuid.UUID, updates map[s
 func (r *activityParticipationRepositoryImpl) Delete(id uuid.UUID) error {
 	return r.db.Delete(&model.ActivityParticipation{}, "participation_id = ?", id).Error
 }
+
+// FindActive returns the user's participation in the activity that has not
+// been withdrawn.
+func (r *activityParticipationRepositoryImpl) FindActive(activityID, userID uuid.UUID) (*model.ActivityParticipation, error) {
+	var participation model.ActivityParticipation
+	err := r.db.Where("activity_id = ? AND user_id = ? AND status <> ?", activityID, userID, model.ParticipationWithdrawn).
+		First(&participation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &participation, nil
+}
+
+// CountRegistered counts participations holding a place, i.e. everything but
+// waitlisted and withdrawn ones.
+func (r *activityParticipationRepositoryImpl) CountRegistered(activityID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.ActivityParticipation{}).
+		Where("activity_id = ? AND status NOT IN ?", activityID, []string{model.ParticipationWaitlisted, model.ParticipationWithdrawn}).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *activityParticipationRepositoryImpl) CountByStatus(activityID uuid.UUID, status string) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.ActivityParticipation{}).
+		Where("activity_id = ? AND status = ?", activityID, status).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *activityParticipationRepositoryImpl) NextWaitlistPosition(activityID uuid.UUID) (int, error) {
+	var last int
+	err := r.db.Model(&model.ActivityParticipation{}).
+		Where("activity_id = ? AND status = ?", activityID, model.ParticipationWaitlisted).
+		Select("COALESCE(MAX(waitlist_position), 0)").
+		Scan(&last).Error
+	return last + 1, err
+}
+
+// FindWaitlisted returns the first waitlisted participations in order.
+func (r *activityParticipationRepositoryImpl) FindWaitlisted(activityID uuid.UUID, limit int) ([]model.ActivityParticipation, error) {
+	var participations []model.ActivityParticipation
+	err := r.db.Where("activity_id = ? AND status = ?", activityID, model.ParticipationWaitlisted).
+		Order("waitlist_position ASC").
+		Limit(limit).
+		Find(&participations).Error
+	return participations, err
+}
+
+func (r *activityParticipationRepositoryImpl) RecordTransition(transition *model.ParticipationStatusTransition) error {
+	return r.db.Create(transition).Error
+}
+
+// ListTransitions returns the participation's status history, oldest first.
+func (r *activityParticipationRepositoryImpl) ListTransitions(participationID uuid.UUID) ([]model.ParticipationStatusTransition, error) {
+	var transitions []model.ParticipationStatusTransition
+	err := r.db.Where("participation_id = ?", participationID).
+		Order("created_at ASC").
+		Find(&transitions).Error
+	return transitions, err
+}
@@ -1,19 +1,102 @@
 package repository
 
 import (
+	"encoding/json"
+	"fmt"
+	"ping-badge-be/internal/geo"
+	"ping-badge-be/internal/listquery"
 	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// Activity sort orders for FindAll.
+const (
+	SortRelevance = "relevance"
+	SortDate      = "date"
+	SortDateDesc  = "-date"
+	SortDistance  = "distance"
+)
+
+// ActivityListSchema lists the activity fields list requests may filter and
+// sort by.
+var ActivityListSchema = listquery.Schema{
+	Table: "activities",
+	ID:    "activity_id",
+	Fields: map[string]listquery.Field{
+		"activity_name": {Column: "activity_name", Type: listquery.String, Sortable: true},
+		"status":        {Column: "status", Type: listquery.Enum, Values: []string{model.ActivityActive, model.ActivityCancelled, model.ActivityArchived}, Sortable: true},
+		"category":      {Column: "category", Type: listquery.String, Sortable: true},
+		"org_id":        {Column: "org_id", Type: listquery.UUID},
+		"badge_def_id":  {Column: "badge_def_id", Type: listquery.UUID},
+		"capacity":      {Column: "capacity", Type: listquery.Number, Sortable: true},
+		"start_date":    {Column: "start_date", Type: listquery.Time, Sortable: true},
+		"end_date":      {Column: "end_date", Type: listquery.Time, Sortable: true},
+		"created_at":    {Column: "created_at", Type: listquery.Time, Sortable: true},
+	},
+}
+
+// ActivityFilter narrows FindAll. From and To select activities whose schedule
+// overlaps the range; nil and empty fields are ignored.
+type ActivityFilter struct {
+	// OrgID keeps activities the organization hosts or co-hosts.
+	OrgID *uuid.UUID
+	From  *time.Time
+	To    *time.Time
+	// Query is searched for in names and descriptions (websearch syntax).
+	Query    string
+	Category string
+	// Tags lists tags activities must all have.
+	Tags []string
+	// Upcoming selects activities with a session still to end when true, and
+	// activities whose sessions have all ended when false.
+	Upcoming *bool
+	HasBadge *bool
+	// VerifiedOnly keeps activities of verified organizations.
+	VerifiedOnly bool
+	// Near and RadiusMeters keep activities within that distance of a point.
+	Near         *geo.Point
+	RadiusMeters float64
+	// Sort is one of the Sort constants; it defaults to relevance when
+	// searching and to date otherwise.
+	Sort string
+	// Status keeps activities with that status. Archived activities are left
+	// out unless asked for, here or in Criteria.
+	Status string
+	// Criteria are the generic filters and sort of the request. A Criteria
+	// sort takes precedence over Sort.
+	Criteria listquery.Query
+}
+
+// CalendarFilter selects activities for a calendar feed: those an
+// organization hosts or co-hosts, or those a user has joined and not
+// withdrawn from, that are recurring or end after Since. Activities deleted
+// after Since are included so feeds can show them as cancelled.
+type CalendarFilter struct {
+	OrgID  *uuid.UUID
+	UserID *uuid.UUID
+	Since  time.Time
+}
+
 type ActivityRepository interface {
 	Create(activity *model.Activity) error
 	FindByID(activityID uuid.UUID) (*model.Activity, error)
-	FindAll(orgID *uuid.UUID, offset, limit int) ([]model.Activity, error)
-	FindByUser(userID uuid.UUID, offset, limit int) ([]model.Activity, error)
+	FindByIDForUpdate(activityID uuid.UUID) (*model.Activity, error)
+	FindByIDs(activityIDs []uuid.UUID) ([]model.Activity, error)
+	FindAll(filter ActivityFilter, page pagination.Params) ([]model.Activity, error)
+	Count(filter ActivityFilter) (int64, error)
+	FindByUser(userID uuid.UUID, criteria listquery.Query, page pagination.Params) ([]model.Activity, error)
+	CountByUser(userID uuid.UUID, criteria listquery.Query) (int64, error)
+	FindForCalendar(filter CalendarFilter, limit int) ([]model.Activity, error)
 	Update(activityID uuid.UUID, updates map[string]interface{}) (*model.Activity, error)
+	EnsureCheckInSecret(activityID uuid.UUID, secret string) (string, error)
 	Delete(activityID uuid.UUID) error
+	FindPendingReminders(from, to time.Time) ([]model.Activity, error)
+	MarkReminderSent(activityID uuid.UUID) error
 }
 
 // ActivityRepositoryImpl implements ActivityRepository
@@ -39,25 +122,183 @@ func (r *activityRepositoryImpl) FindByID(activityID uuid.UUID) (*model.Activity
 	return &activity, nil
 }
 
-func (r *activityRepositoryImpl) FindAll(orgID *uuid.UUID, offset, limit int) ([]model.Activity, error) {
+// FindByIDForUpdate loads the activity and locks its row until the
+// transaction ends, serializing concurrent registrations.
+func (r *activityRepositoryImpl) FindByIDForUpdate(activityID uuid.UUID) (*model.Activity, error) {
+	var activity model.Activity
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&activity, "activity_id = ?", activityID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &activity, nil
+}
+
+// distanceSQL is the haversine distance in meters from the activity to the
+// point given as (latitude, latitude, longitude) arguments.
+var distanceSQL = fmt.Sprintf(`%f * 2 * ASIN(LEAST(1, SQRT(
+	POWER(SIN(RADIANS(latitude - ?) / 2), 2) +
+	COS(RADIANS(?)) * COS(RADIANS(latitude)) * POWER(SIN(RADIANS(longitude - ?) / 2), 2))))`, geo.EarthRadiusMeters)
+
+func (r *activityRepositoryImpl) FindByIDs(activityIDs []uuid.UUID) ([]model.Activity, error) {
 	var activities []model.Activity
-	query := r.db
-	if orgID != nil {
-		query = query.Table("activities").Where("org_id = ?", *orgID)
+	if len(activityIDs) == 0 {
+		return activities, nil
 	}
-	err := query.Offset(offset).Limit(limit).Find(&activities).Error
+	err := r.db.Where("activity_id IN ?", activityIDs).Find(&activities).Error
 	return activities, err
 }
 
-func (r *activityRepositoryImpl) FindByUser(userID uuid.UUID, offset, limit int) ([]model.Activity, error) {
+// FindAll returns a page of the activities matching filter. In keyset mode
+// they are listed in creation order whatever the filter's sort says.
+func (r *activityRepositoryImpl) FindAll(filter ActivityFilter, page pagination.Params) ([]model.Activity, error) {
 	var activities []model.Activity
-	// Join with activity_participation table to find activities the user has participated in
-	err := r.db.Table("activities").
-		Joins("JOIN activity_participation ON activities.activity_id = activity_participation.activity_id").
-		Where("activity_participation.user_id = ?", userID).
-		Offset(offset).
-		Limit(limit).
-		Find(&activities).Error
+	query, err := r.filtered(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	sort := filter.Sort
+	if sort == "" || (sort == SortRelevance && filter.Query == "") {
+		sort = SortDate
+		if filter.Query != "" {
+			sort = SortRelevance
+		}
+	}
+	switch {
+	case filter.Criteria.Sorted():
+		query = filter.Criteria.Order(query)
+	case sort == SortRelevance:
+		query = query.Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "ts_rank(search_vector, websearch_to_tsquery('simple', ?)) DESC, start_date ASC NULLS LAST",
+			Vars:               []interface{}{filter.Query},
+			WithoutParentheses: true,
+		}})
+	case sort == SortDistance && filter.Near != nil:
+		query = query.Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL:                distanceSQL + " ASC",
+			Vars:               []interface{}{filter.Near.Latitude, filter.Near.Latitude, filter.Near.Longitude},
+			WithoutParentheses: true,
+		}})
+	case sort == SortDateDesc:
+		query = query.Order("start_date DESC NULLS LAST")
+	default:
+		query = query.Order("start_date ASC NULLS LAST")
+	}
+	err = page.Apply(query, pagination.Keyset{Table: "activities", CreatedAt: "created_at", ID: "activity_id"}).Find(&activities).Error
+	return activities, err
+}
+
+func (r *activityRepositoryImpl) Count(filter ActivityFilter) (int64, error) {
+	query, err := r.filtered(filter)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	err = query.Count(&count).Error
+	return count, err
+}
+
+func (r *activityRepositoryImpl) filtered(filter ActivityFilter) (*gorm.DB, error) {
+	query := filter.Criteria.Where(r.db.Model(&model.Activity{}))
+	if filter.OrgID != nil {
+		query = query.Where("org_id = ? OR activity_id IN (?)", *filter.OrgID, r.coHosted(*filter.OrgID))
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	} else if !filter.Criteria.HasFilter("status") {
+		query = query.Where("status <> ?", model.ActivityArchived)
+	}
+	if filter.From != nil {
+		query = query.Where("COALESCE(end_date, start_date) >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("start_date <= ?", *filter.To)
+	}
+	if filter.Query != "" {
+		query = query.Where("search_vector @@ websearch_to_tsquery('simple', ?)", filter.Query)
+	}
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	if len(filter.Tags) > 0 {
+		tags, err := json.Marshal(filter.Tags)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("tags @> ?::jsonb", string(tags))
+	}
+	if filter.Upcoming != nil {
+		pending := r.db.Model(&model.ActivitySession{}).
+			Select("1").
+			Where("activity_sessions.activity_id = activities.activity_id AND NOT cancelled AND ends_at >= ?", time.Now())
+		if *filter.Upcoming {
+			query = query.Where("EXISTS (?)", pending)
+		} else {
+			query = query.Where("start_date IS NOT NULL AND NOT EXISTS (?)", pending)
+		}
+	}
+	if filter.HasBadge != nil {
+		if *filter.HasBadge {
+			query = query.Where("badge_def_id IS NOT NULL")
+		} else {
+			query = query.Where("badge_def_id IS NULL")
+		}
+	}
+	if filter.VerifiedOnly {
+		query = query.Where("org_id IN (?)", r.db.Model(&model.Organization{}).Select("org_id").Where("is_verified"))
+	}
+	if filter.Near != nil {
+		query = query.Where("latitude IS NOT NULL AND longitude IS NOT NULL").
+			Where(distanceSQL+" <= ?", filter.Near.Latitude, filter.Near.Latitude, filter.Near.Longitude, filter.RadiusMeters)
+	}
+	return query, nil
+}
+
+// coHosted selects the IDs of activities the organization co-hosts.
+func (r *activityRepositoryImpl) coHosted(orgID uuid.UUID) *gorm.DB {
+	return acceptedCoHosted(r.db, orgID)
+}
+
+func (r *activityRepositoryImpl) FindByUser(userID uuid.UUID, criteria listquery.Query, page pagination.Params) ([]model.Activity, error) {
+	var activities []model.Activity
+	query := criteria.Where(r.joinedBy(userID)).Select("activities.*")
+	if criteria.Sorted() {
+		query = criteria.Order(query)
+	} else {
+		query = query.Order("activities.created_at ASC").Order("activities.activity_id ASC")
+	}
+	err := page.Apply(query, pagination.Keyset{Table: "activities", CreatedAt: "created_at", ID: "activity_id"}).Find(&activities).Error
+	return activities, err
+}
+
+func (r *activityRepositoryImpl) CountByUser(userID uuid.UUID, criteria listquery.Query) (int64, error) {
+	var count int64
+	err := criteria.Where(r.joinedBy(userID)).Count(&count).Error
+	return count, err
+}
+
+// joinedBy selects the activities the user has participated in.
+func (r *activityRepositoryImpl) joinedBy(userID uuid.UUID) *gorm.DB {
+	return r.db.Model(&model.Activity{}).
+		Joins("JOIN activity_participations ON activities.activity_id = activity_participations.activity_id").
+		Where("activity_participations.user_id = ?", userID)
+}
+
+func (r *activityRepositoryImpl) FindForCalendar(filter CalendarFilter, limit int) ([]model.Activity, error) {
+	var activities []model.Activity
+	query := r.db.Unscoped().Model(&model.Activity{}).
+		Where("start_date IS NOT NULL").
+		Where("COALESCE(end_date, start_date) >= ? OR recurrence_rule IS NOT NULL", filter.Since).
+		Where("deleted_at IS NULL OR deleted_at >= ?", filter.Since)
+	if filter.OrgID != nil {
+		query = query.Where("org_id = ? OR activity_id IN (?)", *filter.OrgID, r.coHosted(*filter.OrgID))
+	}
+	if filter.UserID != nil {
+		query = query.Where("activity_id IN (?)", r.db.Table("activity_participations").
+			Select("activity_id").
+			Where("user_id = ? AND status <> ?", *filter.UserID, model.ParticipationWithdrawn))
+	}
+	err := query.Order("start_date ASC").Limit(limit).Find(&activities).Error
 	return activities, err
 }
 
@@ -74,8 +315,40 @@ func (r *activityRepositoryImpl) Update(activityID uuid.UUID, updates map[string
 	return &activity, nil
 }
 
+// EnsureCheckInSecret stores secret unless the activity already has one and
+// returns the secret in effect, so concurrent callers agree on it.
+func (r *activityRepositoryImpl) EnsureCheckInSecret(activityID uuid.UUID, secret string) (string, error) {
+	err := r.db.Model(&model.Activity{}).
+		Where("activity_id = ? AND check_in_secret IS NULL", activityID).
+		Update("check_in_secret", secret).Error
+	if err != nil {
+		return "", err
+	}
+	var current string
+	err = r.db.Model(&model.Activity{}).
+		Where("activity_id = ?", activityID).
+		Select("check_in_secret").
+		Scan(&current).Error
+	return current, err
+}
+
 func (r *activityRepositoryImpl) Delete(activityID uuid.UUID) error {
 	return r.db.Delete(&model.Activity{}, "activity_id = ?", activityID).Error
 }
 
+// FindPendingReminders returns active activities starting in [from, to)
+// whose participants have not been reminded yet.
+func (r *activityRepositoryImpl) FindPendingReminders(from, to time.Time) ([]model.Activity, error) {
+	var activities []model.Activity
+	err := r.db.Where("start_date >= ? AND start_date < ? AND reminder_sent_at IS NULL AND status = ?", from, to, model.ActivityActive).
+		Find(&activities).Error
+	return activities, err
+}
+
+func (r *activityRepositoryImpl) MarkReminderSent(activityID uuid.UUID) error {
+	return r.db.Model(&model.Activity{}).
+		Where("activity_id = ?", activityID).
+		Update("reminder_sent_at", time.Now()).Error
+}
+
 // Implement participation methods as needed
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRun returns a database that builds statements without running them and
+// a function returning the last query built.
+func dryRun(t *testing.T) (*gorm.DB, func() string) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+	var last string
+	err = db.Callback().Query().After("gorm:query").Register("test:capture", func(tx *gorm.DB) {
+		last = db.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...)
+	})
+	require.NoError(t, err)
+	return db, func() string { return last }
+}
+
+func TestFindForCalendarUserQuery(t *testing.T) {
+	db, lastSQL := dryRun(t)
+	userID := uuid.New()
+	since := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	_, err := NewActivityRepository(db).FindForCalendar(CalendarFilter{UserID: &userID, Since: since}, 500)
+	require.NoError(t, err)
+
+	sql := lastSQL()
+	assert.Contains(t, sql, `activity_id IN (SELECT activity_id FROM "activity_participations" WHERE user_id = '`+userID.String()+`' AND status <> 'WITHDRAWN')`)
+	assert.Contains(t, sql, `ORDER BY start_date ASC LIMIT 500`)
+}
+
+func TestFindForCalendarOrganizationQuery(t *testing.T) {
+	db, lastSQL := dryRun(t)
+	orgID := uuid.New()
+	_, err := NewActivityRepository(db).FindForCalendar(CalendarFilter{OrgID: &orgID}, 500)
+	require.NoError(t, err)
+
+	assert.Contains(t, lastSQL(), `activity_id IN (SELECT "activity_id" FROM "activity_co_hosts" WHERE org_id = '`+orgID.String()+`' AND accepted_at IS NOT NULL)`)
+}
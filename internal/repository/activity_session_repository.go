@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"ping-badge-be/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ActivitySessionRepository interface {
+	ListByActivity(ctx context.Context, activityID uuid.UUID) ([]model.ActivitySession, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*model.ActivitySession, error)
+	Sync(ctx context.Context, activityID uuid.UUID, sessions []model.ActivitySession) error
+	CancelFrom(ctx context.Context, activityID uuid.UUID, from time.Time) error
+	RecordAttendance(ctx context.Context, attendance *model.SessionAttendance) (bool, error)
+	CountAttendance(ctx context.Context, participationID uuid.UUID) (int64, error)
+	ListAttendance(ctx context.Context, sessionID uuid.UUID) ([]model.SessionAttendance, error)
+	EnsureCheckInSecret(ctx context.Context, sessionID uuid.UUID, secret string) (string, error)
+}
+
+type activitySessionRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewActivitySessionRepository(db *gorm.DB) ActivitySessionRepository {
+	return &activitySessionRepositoryImpl{db: db}
+}
+
+func (r *activitySessionRepositoryImpl) ListByActivity(ctx context.Context, activityID uuid.UUID) ([]model.ActivitySession, error) {
+	var sessions []model.ActivitySession
+	err := r.db.WithContext(ctx).
+		Where("activity_id = ?", activityID).
+		Order("starts_at ASC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *activitySessionRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*model.ActivitySession, error) {
+	var session model.ActivitySession
+	err := r.db.WithContext(ctx).First(&session, "session_id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Sync makes the activity's sessions match the given schedule. Sessions that
+// left the schedule are deleted, or cancelled if anyone attended them.
+func (r *activitySessionRepositoryImpl) Sync(ctx context.Context, activityID uuid.UUID, sessions []model.ActivitySession) error {
+	db := r.db.WithContext(ctx)
+	existing, err := r.ListByActivity(ctx, activityID)
+	if err != nil {
+		return err
+	}
+	wanted := make(map[int64]model.ActivitySession, len(sessions))
+	for _, s := range sessions {
+		wanted[s.StartsAt.UnixMicro()] = s
+	}
+
+	for _, current := range existing {
+		key := current.StartsAt.UnixMicro()
+		if s, ok := wanted[key]; ok {
+			delete(wanted, key)
+			err := db.Model(&model.ActivitySession{}).
+				Where("session_id = ?", current.SessionID).
+				Updates(map[string]interface{}{"ends_at": s.EndsAt, "cancelled": false}).Error
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		var attended int64
+		if err := db.Model(&model.SessionAttendance{}).Where("session_id = ?", current.SessionID).Count(&attended).Error; err != nil {
+			return err
+		}
+		if attended > 0 {
+			err = db.Model(&model.ActivitySession{}).Where("session_id = ?", current.SessionID).Update("cancelled", true).Error
+		} else {
+			err = db.Delete(&model.ActivitySession{}, "session_id = ?", current.SessionID).Error
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, s := range sessions {
+		if _, ok := wanted[s.StartsAt.UnixMicro()]; !ok {
+			continue
+		}
+		s.SessionID = uuid.New()
+		s.ActivityID = activityID
+		if err := db.Create(&s).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CancelFrom cancels the activity's sessions that start at or after from.
+func (r *activitySessionRepositoryImpl) CancelFrom(ctx context.Context, activityID uuid.UUID, from time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.ActivitySession{}).
+		Where("activity_id = ? AND starts_at >= ?", activityID, from).
+		Update("cancelled", true).Error
+}
+
+// RecordAttendance stores the attendance and reports whether it is new.
+// Recording the same session twice is a no-op.
+func (r *activitySessionRepositoryImpl) RecordAttendance(ctx context.Context, attendance *model.SessionAttendance) (bool, error) {
+	if attendance.AttendanceID == uuid.Nil {
+		attendance.AttendanceID = uuid.New()
+	}
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "session_id"}, {Name: "participation_id"}}, DoNothing: true}).
+		Create(attendance)
+	return result.RowsAffected > 0, result.Error
+}
+
+// CountAttendance counts the participant's attended sessions that are still
+// on the schedule.
+func (r *activitySessionRepositoryImpl) CountAttendance(ctx context.Context, participationID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.SessionAttendance{}).
+		Joins("JOIN activity_sessions ON activity_sessions.session_id = session_attendances.session_id").
+		Where("session_attendances.participation_id = ? AND activity_sessions.cancelled = ?", participationID, false).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *activitySessionRepositoryImpl) ListAttendance(ctx context.Context, sessionID uuid.UUID) ([]model.SessionAttendance, error) {
+	var attendance []model.SessionAttendance
+	err := r.db.WithContext(ctx).
+		Where("session_id = ?", sessionID).
+		Order("attended_at ASC").
+		Find(&attendance).Error
+	return attendance, err
+}
+
+// EnsureCheckInSecret stores secret unless the session already has one and
+// returns the secret in effect.
+func (r *activitySessionRepositoryImpl) EnsureCheckInSecret(ctx context.Context, sessionID uuid.UUID, secret string) (string, error) {
+	db := r.db.WithContext(ctx)
+	err := db.Model(&model.ActivitySession{}).
+		Where("session_id = ? AND check_in_secret IS NULL", sessionID).
+		Update("check_in_secret", secret).Error
+	if err != nil {
+		return "", err
+	}
+	var current string
+	err = db.Model(&model.ActivitySession{}).
+		Where("session_id = ?", sessionID).
+		Select("check_in_secret").
+		Scan(&current).Error
+	return current, err
+}
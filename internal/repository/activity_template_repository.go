@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"ping-badge-be/internal/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ActivityTemplateRepository interface {
+	Create(ctx context.Context, template *model.ActivityTemplate) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.ActivityTemplate, error)
+	ListByOrg(ctx context.Context, orgID uuid.UUID) ([]model.ActivityTemplate, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type activityTemplateRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewActivityTemplateRepository(db *gorm.DB) ActivityTemplateRepository {
+	return &activityTemplateRepositoryImpl{db: db}
+}
+
+func (r *activityTemplateRepositoryImpl) Create(ctx context.Context, template *model.ActivityTemplate) error {
+	return r.db.WithContext(ctx).Create(template).Error
+}
+
+func (r *activityTemplateRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*model.ActivityTemplate, error) {
+	var template model.ActivityTemplate
+	err := r.db.WithContext(ctx).First(&template, "template_id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (r *activityTemplateRepositoryImpl) ListByOrg(ctx context.Context, orgID uuid.UUID) ([]model.ActivityTemplate, error) {
+	var templates []model.ActivityTemplate
+	err := r.db.WithContext(ctx).
+		Where("org_id = ?", orgID).
+		Order("template_name ASC").
+		Find(&templates).Error
+	return templates, err
+}
+
+func (r *activityTemplateRepositoryImpl) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&model.ActivityTemplate{}, "template_id = ?", id).Error
+}
@@ -2,17 +2,49 @@ package repository
 
 import (
 	"context"
+	"ping-badge-be/internal/listquery"
 	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// BadgeListSchema lists the badge fields list requests may filter and sort by.
+var BadgeListSchema = listquery.Schema{
+	Table: "badges",
+	ID:    "badge_def_id",
+	Fields: map[string]listquery.Field{
+		"org_id":     {Column: "org_id", Type: listquery.UUID},
+		"badge_name": {Column: "badge_name", Type: listquery.String, Sortable: true},
+		"badge_type": {Column: "badge_type", Type: listquery.String, Ops: []string{listquery.OpEq, listquery.OpNe, listquery.OpIn, listquery.OpNin}},
+		"is_active":  {Column: "is_active", Type: listquery.Bool},
+		"created_at": {Column: "created_at", Type: listquery.Time, Sortable: true},
+	},
+}
+
+// IssuedBadgeListSchema lists the issued badge fields list requests may
+// filter and sort by.
+var IssuedBadgeListSchema = listquery.Schema{
+	Table: "issued_badges",
+	ID:    "issued_badge_id",
+	Fields: map[string]listquery.Field{
+		"badge_def_id": {Column: "badge_def_id", Type: listquery.UUID},
+		"org_id":       {Column: "org_id", Type: listquery.UUID},
+		"status":       {Column: "status", Type: listquery.String, Ops: []string{listquery.OpEq, listquery.OpNe, listquery.OpIn, listquery.OpNin}},
+		"source_type":  {Column: "source_type", Type: listquery.String, Ops: []string{listquery.OpEq, listquery.OpIn, listquery.OpNull}},
+		"issue_date":   {Column: "issue_date", Type: listquery.Time, Sortable: true},
+	},
+}
+
 type BadgeRepository interface {
 	Create(ctx context.Context, badge *model.Badge) error
 	GetByID(ctx context.Context, id uuid.UUID) (*model.Badge, error)
-	List(ctx context.Context, orgID *uuid.UUID, offset, limit int) ([]model.Badge, error)
+	List(ctx context.Context, orgID *uuid.UUID, criteria listquery.Query, page pagination.Params) ([]model.Badge, error)
+	Count(ctx context.Context, orgID *uuid.UUID, criteria listquery.Query) (int64, error)
 	ListIssuedBadgesByUser(ctx context.Context, userID uuid.UUID) ([]model.IssuedBadge, error)
+	ListIssuedBadgesPage(ctx context.Context, userID uuid.UUID, criteria listquery.Query, page pagination.Params) ([]model.IssuedBadge, error)
+	CountIssuedBadges(ctx context.Context, userID uuid.UUID, criteria listquery.Query) (int64, error)
 	CreateIssuedBadge(ctx context.Context, issuedBadge *model.IssuedBadge) error
 	Update(ctx context.Context, badge *model.Badge) error
 	Delete(ctx context.Context, id uuid.UUID) error
@@ -39,14 +71,30 @@ func (r *badgeRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*model
 	return &badge, nil
 }
 
-func (r *badgeRepositoryImpl) List(ctx context.Context, orgID *uuid.UUID, offset, limit int) ([]model.Badge, error) {
+func (r *badgeRepositoryImpl) List(ctx context.Context, orgID *uuid.UUID, criteria listquery.Query, page pagination.Params) ([]model.Badge, error) {
 	var badges []model.Badge
+	query := criteria.Where(r.badges(ctx, orgID))
+	if criteria.Sorted() {
+		query = criteria.Order(query)
+	} else {
+		query = query.Order("created_at ASC").Order("badge_def_id ASC")
+	}
+	err := page.Apply(query, pagination.Keyset{Table: "badges", CreatedAt: "created_at", ID: "badge_def_id"}).Find(&badges).Error
+	return badges, err
+}
+
+func (r *badgeRepositoryImpl) Count(ctx context.Context, orgID *uuid.UUID, criteria listquery.Query) (int64, error) {
+	var count int64
+	err := criteria.Where(r.badges(ctx, orgID)).Count(&count).Error
+	return count, err
+}
+
+func (r *badgeRepositoryImpl) badges(ctx context.Context, orgID *uuid.UUID) *gorm.DB {
 	query := r.db.WithContext(ctx).Table("badges")
 	if orgID != nil {
 		query = query.Where("org_id = ?", *orgID)
 	}
-	err := query.Offset(offset).Limit(limit).Find(&badges).Error
-	return badges, err
+	return query
 }
 
 func (r *badgeRepositoryImpl) Update(ctx context.Context, badge *model.Badge) error {
@@ -63,6 +111,26 @@ func (r *badgeRepositoryImpl) ListIssuedBadgesByUser(ctx context.Context, userID
 	return badges, err
 }
 
+// ListIssuedBadgesPage returns a page of the user's badges, in issue order
+// unless criteria sort them.
+func (r *badgeRepositoryImpl) ListIssuedBadgesPage(ctx context.Context, userID uuid.UUID, criteria listquery.Query, page pagination.Params) ([]model.IssuedBadge, error) {
+	var badges []model.IssuedBadge
+	query := criteria.Where(r.db.WithContext(ctx).Table("issued_badges").Where("user_id = ?", userID))
+	if criteria.Sorted() {
+		query = criteria.Order(query)
+	} else {
+		query = query.Order("issue_date ASC").Order("issued_badge_id ASC")
+	}
+	err := page.Apply(query, pagination.Keyset{Table: "issued_badges", CreatedAt: "issue_date", ID: "issued_badge_id"}).Find(&badges).Error
+	return badges, err
+}
+
+func (r *badgeRepositoryImpl) CountIssuedBadges(ctx context.Context, userID uuid.UUID, criteria listquery.Query) (int64, error) {
+	var count int64
+	err := criteria.Where(r.db.WithContext(ctx).Table("issued_badges").Where("user_id = ?", userID)).Count(&count).Error
+	return count, err
+}
+
 func (r *badgeRepositoryImpl) CreateIssuedBadge(ctx context.Context, issuedBadge *model.IssuedBadge) error {
 	return r.db.WithContext(ctx).Create(issuedBadge).Error
 }
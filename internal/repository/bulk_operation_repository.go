@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"ping-badge-be/internal/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type BulkOperationRepository interface {
+	Create(ctx context.Context, operation *model.BulkOperation) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.BulkOperation, error)
+	Save(ctx context.Context, operation *model.BulkOperation) error
+}
+
+type bulkOperationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewBulkOperationRepository(db *gorm.DB) BulkOperationRepository {
+	return &bulkOperationRepositoryImpl{db: db}
+}
+
+func (r *bulkOperationRepositoryImpl) Create(ctx context.Context, operation *model.BulkOperation) error {
+	if operation.OperationID == uuid.Nil {
+		operation.OperationID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Create(operation).Error
+}
+
+func (r *bulkOperationRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*model.BulkOperation, error) {
+	var operation model.BulkOperation
+	err := r.db.WithContext(ctx).First(&operation, "operation_id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &operation, nil
+}
+
+func (r *bulkOperationRepositoryImpl) Save(ctx context.Context, operation *model.BulkOperation) error {
+	return r.db.WithContext(ctx).Save(operation).Error
+}
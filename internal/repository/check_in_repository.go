@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"ping-badge-be/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CheckInRepository interface {
+	Create(ctx context.Context, record *model.CheckInRecord) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.CheckInRecord, error)
+	ListByActivity(ctx context.Context, activityID uuid.UUID, flaggedOnly bool) ([]model.CheckInRecord, error)
+	MarkReviewed(ctx context.Context, id, reviewerID uuid.UUID) error
+}
+
+type checkInRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewCheckInRepository(db *gorm.DB) CheckInRepository {
+	return &checkInRepositoryImpl{db: db}
+}
+
+func (r *checkInRepositoryImpl) Create(ctx context.Context, record *model.CheckInRecord) error {
+	return r.db.WithContext(ctx).Create(record).Error
+}
+
+func (r *checkInRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*model.CheckInRecord, error) {
+	var record model.CheckInRecord
+	err := r.db.WithContext(ctx).First(&record, "check_in_id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// ListByActivity returns the activity's check-ins, newest first. flaggedOnly
+// limits it to flagged check-ins not reviewed yet.
+func (r *checkInRepositoryImpl) ListByActivity(ctx context.Context, activityID uuid.UUID, flaggedOnly bool) ([]model.CheckInRecord, error) {
+	var records []model.CheckInRecord
+	query := r.db.WithContext(ctx).Where("activity_id = ?", activityID)
+	if flaggedOnly {
+		query = query.Where("flagged AND reviewed_at IS NULL")
+	}
+	err := query.Order("created_at DESC").Find(&records).Error
+	return records, err
+}
+
+func (r *checkInRepositoryImpl) MarkReviewed(ctx context.Context, id, reviewerID uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&model.CheckInRecord{}).
+		Where("check_in_id = ?", id).
+		Updates(map[string]interface{}{"reviewed_at": time.Now(), "reviewed_by": reviewerID}).Error
+}
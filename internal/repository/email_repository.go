@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"ping-badge-be/internal/model"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EmailRepository interface {
+	CreateLog(ctx context.Context, log *model.EmailLog) error
+	GetLog(ctx context.Context, id uuid.UUID) (*model.EmailLog, error)
+	UpdateLog(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error
+	MarkBounced(ctx context.Context, providerMessageID string, reason string) error
+	IsSuppressed(ctx context.Context, email string) (bool, error)
+	Suppress(ctx context.Context, email, reason string) error
+}
+
+type emailRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewEmailRepository(db *gorm.DB) EmailRepository {
+	return &emailRepositoryImpl{db: db}
+}
+
+func (r *emailRepositoryImpl) CreateLog(ctx context.Context, log *model.EmailLog) error {
+	if log.EmailID == uuid.Nil {
+		log.EmailID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Create(log).Error
+}
+
+func (r *emailRepositoryImpl) GetLog(ctx context.Context, id uuid.UUID) (*model.EmailLog, error) {
+	var log model.EmailLog
+	err := r.db.WithContext(ctx).First(&log, "email_id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &log, nil
+}
+
+func (r *emailRepositoryImpl) UpdateLog(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&model.EmailLog{}).Where("email_id = ?", id).Updates(updates).Error
+}
+
+func (r *emailRepositoryImpl) MarkBounced(ctx context.Context, providerMessageID string, reason string) error {
+	return r.db.WithContext(ctx).Model(&model.EmailLog{}).
+		Where("provider_message_id = ?", providerMessageID).
+		Updates(map[string]interface{}{"status": model.EmailStatusBounced, "error": reason}).Error
+}
+
+func (r *emailRepositoryImpl) IsSuppressed(ctx context.Context, email string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.EmailSuppression{}).
+		Where("email = ?", strings.ToLower(email)).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *emailRepositoryImpl) Suppress(ctx context.Context, email, reason string) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.EmailSuppression{Email: strings.ToLower(email), Reason: reason}).Error
+}
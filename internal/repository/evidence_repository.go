@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EvidenceFilter narrows an organization's review queue. An empty Status
+// matches every status.
+type EvidenceFilter struct {
+	OrgID      uuid.UUID
+	Status     string
+	ActivityID *uuid.UUID
+	UserID     *uuid.UUID
+}
+
+type EvidenceRepository interface {
+	Create(ctx context.Context, evidence *model.ParticipationEvidence) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.ParticipationEvidence, error)
+	ListByParticipation(ctx context.Context, participationID uuid.UUID) ([]model.ParticipationEvidence, error)
+	CountPending(ctx context.Context, participationID uuid.UUID) (int64, error)
+	SetStatus(ctx context.Context, id uuid.UUID, status string, reviewerID uuid.UUID) error
+	ListQueue(ctx context.Context, filter EvidenceFilter, page pagination.Params) ([]model.ParticipationEvidence, int64, error)
+	AddComment(ctx context.Context, comment *model.EvidenceComment) error
+	ListComments(ctx context.Context, evidenceIDs []uuid.UUID) ([]model.EvidenceComment, error)
+}
+
+type evidenceRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewEvidenceRepository(db *gorm.DB) EvidenceRepository {
+	return &evidenceRepositoryImpl{db: db}
+}
+
+func (r *evidenceRepositoryImpl) Create(ctx context.Context, evidence *model.ParticipationEvidence) error {
+	return r.db.WithContext(ctx).Create(evidence).Error
+}
+
+func (r *evidenceRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*model.ParticipationEvidence, error) {
+	var evidence model.ParticipationEvidence
+	err := r.db.WithContext(ctx).First(&evidence, "evidence_id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &evidence, nil
+}
+
+func (r *evidenceRepositoryImpl) ListByParticipation(ctx context.Context, participationID uuid.UUID) ([]model.ParticipationEvidence, error) {
+	var evidence []model.ParticipationEvidence
+	err := r.db.WithContext(ctx).
+		Where("participation_id = ?", participationID).
+		Order("submitted_at ASC").
+		Find(&evidence).Error
+	return evidence, err
+}
+
+func (r *evidenceRepositoryImpl) CountPending(ctx context.Context, participationID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.ParticipationEvidence{}).
+		Where("participation_id = ? AND status = ?", participationID, model.EvidencePending).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *evidenceRepositoryImpl) SetStatus(ctx context.Context, id uuid.UUID, status string, reviewerID uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&model.ParticipationEvidence{}).
+		Where("evidence_id = ?", id).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"reviewed_by": reviewerID,
+			"reviewed_at": time.Now(),
+		}).Error
+}
+
+// ListQueue returns matching evidence oldest first, so reviewers work through
+// the queue in submission order, together with the total match count.
+func (r *evidenceRepositoryImpl) ListQueue(ctx context.Context, filter EvidenceFilter, page pagination.Params) ([]model.ParticipationEvidence, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.ParticipationEvidence{}).Where("org_id = ?", filter.OrgID)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.ActivityID != nil {
+		query = query.Where("activity_id = ?", *filter.ActivityID)
+	}
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var evidence []model.ParticipationEvidence
+	query = query.Order("submitted_at ASC").Order("evidence_id ASC")
+	err := page.Apply(query, pagination.Keyset{Table: "participation_evidences", CreatedAt: "submitted_at", ID: "evidence_id"}).Find(&evidence).Error
+	return evidence, total, err
+}
+
+func (r *evidenceRepositoryImpl) AddComment(ctx context.Context, comment *model.EvidenceComment) error {
+	return r.db.WithContext(ctx).Create(comment).Error
+}
+
+func (r *evidenceRepositoryImpl) ListComments(ctx context.Context, evidenceIDs []uuid.UUID) ([]model.EvidenceComment, error) {
+	var comments []model.EvidenceComment
+	if len(evidenceIDs) == 0 {
+		return comments, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("evidence_id IN ?", evidenceIDs).
+		Order("created_at ASC").
+		Find(&comments).Error
+	return comments, err
+}
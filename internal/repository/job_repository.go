@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type JobRepository interface {
+	Enqueue(ctx context.Context, job *model.Job) error
+	ClaimNext(ctx context.Context, workerID string, jobTypes []string) (*model.Job, error)
+	Complete(ctx context.Context, id uuid.UUID) error
+	Fail(ctx context.Context, id uuid.UUID, lastError string, retryAt *time.Time) error
+	RequeueStale(ctx context.Context, lockedBefore time.Time) (int64, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Job, error)
+	List(ctx context.Context, status *string, jobType *string, page pagination.Params) ([]model.Job, error)
+	Count(ctx context.Context, status *string, jobType *string) (int64, error)
+	Retry(ctx context.Context, id uuid.UUID) (*model.Job, error)
+	DeleteCompletedBefore(ctx context.Context, before time.Time) (int64, error)
+	UpsertSchedule(ctx context.Context, schedule *model.JobSchedule) error
+	EnqueueDueSchedules(ctx context.Context, now time.Time, next func(model.JobSchedule) (time.Time, error)) (int, error)
+}
+
+var ErrJobNotRetryable = errors.New("only failed or dead jobs can be retried")
+
+type jobRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) JobRepository {
+	return &jobRepositoryImpl{db: db}
+}
+
+func (r *jobRepositoryImpl) Enqueue(ctx context.Context, job *model.Job) error {
+	if job.JobID == uuid.Nil {
+		job.JobID = uuid.New()
+	}
+	if job.Status == "" {
+		job.Status = model.JobStatusPending
+	}
+	if job.RunAt.IsZero() {
+		job.RunAt = time.Now()
+	}
+	if job.MaxAttempts == 0 {
+		job.MaxAttempts = 5
+	}
+	return r.db.WithContext(ctx).Create(job).Error
+}
+
+// ClaimNext locks the oldest runnable job with FOR UPDATE SKIP LOCKED so that
+// concurrent workers never pick the same row. It returns nil when the queue is empty.
+func (r *jobRepositoryImpl) ClaimNext(ctx context.Context, workerID string, jobTypes []string) (*model.Job, error) {
+	var claimed *model.Job
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var job model.Job
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status IN ? AND run_at <= ? AND job_type IN ?", []string{model.JobStatusPending, model.JobStatusFailed}, time.Now(), jobTypes).
+			Order("run_at").
+			Limit(1).
+			Find(&job)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		now := time.Now()
+		err := tx.Model(&job).Updates(map[string]interface{}{
+			"status":    model.JobStatusRunning,
+			"attempts":  gorm.Expr("attempts + 1"),
+			"locked_at": now,
+			"locked_by": workerID,
+		}).Error
+		if err != nil {
+			return err
+		}
+		job.Status = model.JobStatusRunning
+		job.Attempts++
+		job.LockedAt = &now
+		job.LockedBy = &workerID
+		claimed = &job
+		return nil
+	})
+	return claimed, err
+}
+
+func (r *jobRepositoryImpl) Complete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&model.Job{}).
+		Where("job_id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       model.JobStatusSucceeded,
+			"completed_at": time.Now(),
+			"locked_at":    nil,
+			"locked_by":    nil,
+			"last_error":   nil,
+		}).Error
+}
+
+// Fail schedules the job for another attempt at retryAt, or dead-letters it
+// when retryAt is nil.
+func (r *jobRepositoryImpl) Fail(ctx context.Context, id uuid.UUID, lastError string, retryAt *time.Time) error {
+	updates := map[string]interface{}{
+		"status":     model.JobStatusDead,
+		"last_error": lastError,
+		"locked_at":  nil,
+		"locked_by":  nil,
+	}
+	if retryAt != nil {
+		updates["status"] = model.JobStatusFailed
+		updates["run_at"] = *retryAt
+	}
+	return r.db.WithContext(ctx).Model(&model.Job{}).Where("job_id = ?", id).Updates(updates).Error
+}
+
+// RequeueStale releases jobs whose worker died while running them.
+func (r *jobRepositoryImpl) RequeueStale(ctx context.Context, lockedBefore time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&model.Job{}).
+		Where("status = ? AND locked_at < ?", model.JobStatusRunning, lockedBefore).
+		Updates(map[string]interface{}{
+			"status":     model.JobStatusFailed,
+			"run_at":     time.Now(),
+			"locked_at":  nil,
+			"locked_by":  nil,
+			"last_error": "worker lock expired",
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *jobRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*model.Job, error) {
+	var job model.Job
+	err := r.db.WithContext(ctx).First(&job, "job_id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// List returns a page of jobs, newest first. In keyset mode they come in
+// creation order.
+func (r *jobRepositoryImpl) List(ctx context.Context, status *string, jobType *string, page pagination.Params) ([]model.Job, error) {
+	var jobs []model.Job
+	query := r.jobs(ctx, status, jobType).Order("created_at DESC").Order("job_id DESC")
+	err := page.Apply(query, pagination.Keyset{Table: "jobs", CreatedAt: "created_at", ID: "job_id"}).Find(&jobs).Error
+	return jobs, err
+}
+
+func (r *jobRepositoryImpl) Count(ctx context.Context, status *string, jobType *string) (int64, error) {
+	var count int64
+	err := r.jobs(ctx, status, jobType).Count(&count).Error
+	return count, err
+}
+
+func (r *jobRepositoryImpl) jobs(ctx context.Context, status *string, jobType *string) *gorm.DB {
+	query := r.db.WithContext(ctx).Table("jobs")
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+	if jobType != nil {
+		query = query.Where("job_type = ?", *jobType)
+	}
+	return query
+}
+
+func (r *jobRepositoryImpl) Retry(ctx context.Context, id uuid.UUID) (*model.Job, error) {
+	job, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != model.JobStatusFailed && job.Status != model.JobStatusDead {
+		return nil, ErrJobNotRetryable
+	}
+	err = r.db.WithContext(ctx).Model(job).Updates(map[string]interface{}{
+		"status":   model.JobStatusPending,
+		"attempts": 0,
+		"run_at":   time.Now(),
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (r *jobRepositoryImpl) DeleteCompletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("status = ? AND completed_at < ?", model.JobStatusSucceeded, before).
+		Delete(&model.Job{})
+	return result.RowsAffected, result.Error
+}
+
+// UpsertSchedule creates the schedule or updates its definition. An existing
+// row keeps its NextRunAt unless the cron expression changed.
+func (r *jobRepositoryImpl) UpsertSchedule(ctx context.Context, schedule *model.JobSchedule) error {
+	updates := clause.AssignmentColumns([]string{"cron", "job_type", "payload", "updated_at"})
+	updates = append(updates, clause.Assignment{
+		Column: clause.Column{Name: "next_run_at"},
+		Value:  gorm.Expr("CASE WHEN job_schedules.cron <> excluded.cron THEN excluded.next_run_at ELSE job_schedules.next_run_at END"),
+	})
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: updates,
+	}).Create(schedule).Error
+}
+
+// EnqueueDueSchedules enqueues one job per due schedule and advances each
+// schedule to the time returned by next. Schedules locked by another instance
+// are skipped.
+func (r *jobRepositoryImpl) EnqueueDueSchedules(ctx context.Context, now time.Time, next func(model.JobSchedule) (time.Time, error)) (int, error) {
+	enqueued := 0
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var schedules []model.JobSchedule
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("next_run_at <= ?", now).
+			Find(&schedules).Error
+		if err != nil {
+			return err
+		}
+		jobs := NewJobRepository(tx)
+		for _, schedule := range schedules {
+			nextRunAt, err := next(schedule)
+			if err != nil {
+				return err
+			}
+			job := &model.Job{JobType: schedule.JobType, Payload: schedule.Payload, RunAt: now}
+			if err := jobs.Enqueue(ctx, job); err != nil {
+				return err
+			}
+			err = tx.Model(&model.JobSchedule{}).Where("name = ?", schedule.Name).Updates(map[string]interface{}{
+				"next_run_at": nextRunAt,
+				"last_run_at": now,
+			}).Error
+			if err != nil {
+				return err
+			}
+			enqueued++
+		}
+		return nil
+	})
+	return enqueued, err
+}
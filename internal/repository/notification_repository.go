@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *model.Notification) error
+	List(ctx context.Context, userID uuid.UUID, unreadOnly bool, page pagination.Params) ([]model.Notification, error)
+	Count(ctx context.Context, userID uuid.UUID, unreadOnly bool) (int64, error)
+	CountUnread(ctx context.Context, userID uuid.UUID) (int64, error)
+	MarkRead(ctx context.Context, userID, id uuid.UUID) error
+	MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error)
+	ListPreferences(ctx context.Context, userID uuid.UUID) ([]model.NotificationPreference, error)
+	SavePreference(ctx context.Context, preference *model.NotificationPreference) error
+	IsEnabled(ctx context.Context, userID uuid.UUID, notificationType string) (bool, error)
+	IsEmailEnabled(ctx context.Context, userID uuid.UUID, notificationType string) (bool, error)
+}
+
+type notificationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepositoryImpl{db: db}
+}
+
+// Create inserts the notification. A notification whose DedupeKey already
+// exists is silently skipped.
+func (r *notificationRepositoryImpl) Create(ctx context.Context, notification *model.Notification) error {
+	if notification.NotificationID == uuid.Nil {
+		notification.NotificationID = uuid.New()
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "dedupe_key"}}, DoNothing: true}).
+		Create(notification).Error
+}
+
+// List returns a page of the user's notifications, newest first. In keyset
+// mode they come in creation order.
+func (r *notificationRepositoryImpl) List(ctx context.Context, userID uuid.UUID, unreadOnly bool, page pagination.Params) ([]model.Notification, error) {
+	var notifications []model.Notification
+	query := r.notifications(ctx, userID, unreadOnly).Order("created_at DESC").Order("notification_id DESC")
+	err := page.Apply(query, pagination.Keyset{Table: "notifications", CreatedAt: "created_at", ID: "notification_id"}).Find(&notifications).Error
+	return notifications, err
+}
+
+func (r *notificationRepositoryImpl) Count(ctx context.Context, userID uuid.UUID, unreadOnly bool) (int64, error) {
+	var count int64
+	err := r.notifications(ctx, userID, unreadOnly).Count(&count).Error
+	return count, err
+}
+
+func (r *notificationRepositoryImpl) notifications(ctx context.Context, userID uuid.UUID, unreadOnly bool) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&model.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("is_read = ?", false)
+	}
+	return query
+}
+
+func (r *notificationRepositoryImpl) CountUnread(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Notification{}).
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *notificationRepositoryImpl) MarkRead(ctx context.Context, userID, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Model(&model.Notification{}).
+		Where("notification_id = ? AND user_id = ?", id, userID).
+		Updates(map[string]interface{}{"is_read": true, "read_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *notificationRepositoryImpl) MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&model.Notification{}).
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Updates(map[string]interface{}{"is_read": true, "read_at": time.Now()})
+	return result.RowsAffected, result.Error
+}
+
+func (r *notificationRepositoryImpl) ListPreferences(ctx context.Context, userID uuid.UUID) ([]model.NotificationPreference, error) {
+	var preferences []model.NotificationPreference
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&preferences).Error
+	return preferences, err
+}
+
+// SavePreference upserts the preference. Select("*") makes false values part
+// of the insert instead of falling back to the column default.
+func (r *notificationRepositoryImpl) SavePreference(ctx context.Context, preference *model.NotificationPreference) error {
+	return r.db.WithContext(ctx).Select("*").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"in_app_enabled", "email_enabled", "updated_at"}),
+	}).Create(preference).Error
+}
+
+func (r *notificationRepositoryImpl) IsEnabled(ctx context.Context, userID uuid.UUID, notificationType string) (bool, error) {
+	var preference model.NotificationPreference
+	result := r.db.WithContext(ctx).
+		Where("user_id = ? AND type = ?", userID, notificationType).
+		Limit(1).
+		Find(&preference)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 0 || preference.InAppEnabled, nil
+}
+
+func (r *notificationRepositoryImpl) IsEmailEnabled(ctx context.Context, userID uuid.UUID, notificationType string) (bool, error) {
+	var preference model.NotificationPreference
+	result := r.db.WithContext(ctx).
+		Where("user_id = ? AND type = ?", userID, notificationType).
+		Limit(1).
+		Find(&preference)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 0 || preference.EmailEnabled, nil
+}
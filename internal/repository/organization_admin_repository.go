@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -11,7 +12,9 @@ import (
 type OrganizationAdminRepository interface {
 	Create(ctx context.Context, admin *model.OrganizationAdmin) error
 	GetByID(ctx context.Context, id uuid.UUID) (*model.OrganizationAdmin, error)
-	List(ctx context.Context, offset, limit int) ([]model.OrganizationAdmin, error)
+	FindByOrgAndUser(ctx context.Context, orgID, userID uuid.UUID) (*model.OrganizationAdmin, error)
+	List(ctx context.Context, orgID uuid.UUID, page pagination.Params) ([]model.OrganizationAdmin, error)
+	Count(ctx context.Context, orgID uuid.UUID) (int64, error)
 	Update(ctx context.Context, admin *model.OrganizationAdmin) error
 	Delete(ctx context.Context, id uuid.UUID) error
 }
@@ -37,12 +40,24 @@ func (r *organizationAdminRepositoryImpl) GetByID(ctx context.Context, id uuid.U
 	return &admin, nil
 }
 
-func (r *organizationAdminRepositoryImpl) List(ctx context.Context, offset, limit int) ([]model.OrganizationAdmin, error) {
+// List returns a page of the organization's admins in the order they were
+// added.
+func (r *organizationAdminRepositoryImpl) List(ctx context.Context, orgID uuid.UUID, page pagination.Params) ([]model.OrganizationAdmin, error) {
 	var admins []model.OrganizationAdmin
-	err := r.db.WithContext(ctx).Table("organization_admins").Offset(offset).Limit(limit).Find(&admins).Error
+	query := r.db.WithContext(ctx).Table("organization_admins").
+		Where("org_id = ?", orgID).
+		Order("created_at ASC").
+		Order("admin_id ASC")
+	err := page.Apply(query, pagination.Keyset{Table: "organization_admins", CreatedAt: "created_at", ID: "admin_id"}).Find(&admins).Error
 	return admins, err
 }
 
+func (r *organizationAdminRepositoryImpl) Count(ctx context.Context, orgID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Table("organization_admins").Where("org_id = ?", orgID).Count(&count).Error
+	return count, err
+}
+
 func (r *organizationAdminRepositoryImpl) Update(ctx context.Context, admin *model.OrganizationAdmin) error {
 	return r.db.WithContext(ctx).Save(admin).Error
 }
@@ -50,3 +65,12 @@ func (r *organizationAdminRepositoryImpl) Update(ctx context.Context, admin *mod
 func (r *organizationAdminRepositoryImpl) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Delete(&model.OrganizationAdmin{}, "admin_id = ?", id).Error
 }
+
+func (r *organizationAdminRepositoryImpl) FindByOrgAndUser(ctx context.Context, orgID, userID uuid.UUID) (*model.OrganizationAdmin, error) {
+	var admin model.OrganizationAdmin
+	err := r.db.WithContext(ctx).First(&admin, "org_id = ? AND user_id = ?", orgID, userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &admin, nil
+}
@@ -2,12 +2,27 @@ package repository
 
 import (
 	"context"
+	"ping-badge-be/internal/listquery"
 	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// OrganizationListSchema lists the organization fields list requests may
+// filter and sort by.
+var OrganizationListSchema = listquery.Schema{
+	Table: "organizations",
+	ID:    "org_id",
+	Fields: map[string]listquery.Field{
+		"org_name":      {Column: "org_name", Type: listquery.String, Sortable: true},
+		"user_id_owner": {Column: "user_id_owner", Type: listquery.UUID},
+		"is_verified":   {Column: "is_verified", Type: listquery.Bool, Sortable: true},
+		"created_at":    {Column: "created_at", Type: listquery.Time, Sortable: true},
+	},
+}
+
 type OrganizationRepository struct {
 	db *gorm.DB
 }
@@ -29,16 +44,32 @@ func (r *OrganizationRepository) GetByID(ctx context.Context, id uuid.UUID) (*mo
 	return &org, nil
 }
 
-func (r *OrganizationRepository) List(ctx context.Context, offset, limit int, userID *uuid.UUID) ([]model.Organization, error) {
+func (r *OrganizationRepository) List(ctx context.Context, criteria listquery.Query, page pagination.Params, userID *uuid.UUID) ([]model.Organization, error) {
 	var orgs []model.Organization
-	query := r.db.WithContext(ctx)
-	if userID != nil {
-		query = query.Table("organizations").Where("user_id_owner = ?", *userID)
+	query := criteria.Where(r.organizations(ctx, userID))
+	if criteria.Sorted() {
+		query = criteria.Order(query)
+	} else {
+		query = query.Order("created_at ASC").Order("org_id ASC")
 	}
-	err := query.Offset(offset).Limit(limit).Find(&orgs).Error
+	err := page.Apply(query, pagination.Keyset{Table: "organizations", CreatedAt: "created_at", ID: "org_id"}).Find(&orgs).Error
 	return orgs, err
 }
 
+func (r *OrganizationRepository) Count(ctx context.Context, criteria listquery.Query, userID *uuid.UUID) (int64, error) {
+	var count int64
+	err := criteria.Where(r.organizations(ctx, userID)).Count(&count).Error
+	return count, err
+}
+
+func (r *OrganizationRepository) organizations(ctx context.Context, userID *uuid.UUID) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&model.Organization{})
+	if userID != nil {
+		query = query.Where("user_id_owner = ?", *userID)
+	}
+	return query
+}
+
 func (r *OrganizationRepository) Update(ctx context.Context, org *model.Organization) error {
 	return r.db.WithContext(ctx).Save(org).Error
 }
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"ping-badge-be/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OutboxRepository interface {
+	Append(ctx context.Context, event *model.OutboxEvent) error
+	ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]model.OutboxEvent, error)
+	MarkDelivered(ctx context.Context, eventID uuid.UUID, consumer string) (bool, error)
+	MarkDispatched(ctx context.Context, eventID uuid.UUID) error
+	MarkFailed(ctx context.Context, eventID uuid.UUID, lastError string, retryAt time.Time) error
+	DeleteDispatchedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type outboxRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepositoryImpl{db: db}
+}
+
+func (r *outboxRepositoryImpl) Append(ctx context.Context, event *model.OutboxEvent) error {
+	if event.EventID == uuid.Nil {
+		event.EventID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+// ClaimPending leases up to limit undispatched events. Rows locked by another
+// dispatcher are skipped, and the lease keeps them from being claimed again
+// until it expires.
+func (r *outboxRepositoryImpl) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]model.OutboxEvent, error) {
+	var events []model.OutboxEvent
+	now := time.Now()
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("dispatched_at IS NULL AND (locked_until IS NULL OR locked_until < ?)", now).
+			Order("occurred_at").
+			Limit(limit).
+			Find(&events).Error
+		if err != nil || len(events) == 0 {
+			return err
+		}
+		ids := make([]uuid.UUID, len(events))
+		for i, event := range events {
+			ids[i] = event.EventID
+		}
+		return tx.Model(&model.OutboxEvent{}).
+			Where("event_id IN ?", ids).
+			Update("locked_until", now.Add(lease)).Error
+	})
+	return events, err
+}
+
+// MarkDelivered records the delivery and reports whether this call created the
+// record. A false result means the consumer has already handled the event.
+func (r *outboxRepositoryImpl) MarkDelivered(ctx context.Context, eventID uuid.UUID, consumer string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.OutboxDelivery{EventID: eventID, Consumer: consumer})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *outboxRepositoryImpl) MarkDispatched(ctx context.Context, eventID uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&model.OutboxEvent{}).
+		Where("event_id = ?", eventID).
+		Updates(map[string]interface{}{
+			"dispatched_at": time.Now(),
+			"locked_until":  nil,
+			"last_error":    nil,
+		}).Error
+}
+
+func (r *outboxRepositoryImpl) MarkFailed(ctx context.Context, eventID uuid.UUID, lastError string, retryAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.OutboxEvent{}).
+		Where("event_id = ?", eventID).
+		Updates(map[string]interface{}{
+			"attempts":     gorm.Expr("attempts + 1"),
+			"last_error":   lastError,
+			"locked_until": retryAt,
+		}).Error
+}
+
+func (r *outboxRepositoryImpl) DeleteDispatchedBefore(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		dispatched := tx.Model(&model.OutboxEvent{}).Select("event_id").
+			Where("dispatched_at < ?", before)
+		if err := tx.Where("event_id IN (?)", dispatched).Delete(&model.OutboxDelivery{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("dispatched_at < ?", before).Delete(&model.OutboxEvent{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted, err
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// RealtimeRepository publishes Postgres notifications. Inside a transaction
+// the notification is only delivered once the transaction commits.
+type RealtimeRepository interface {
+	Notify(ctx context.Context, channel string, payload []byte) error
+}
+
+type realtimeRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewRealtimeRepository(db *gorm.DB) RealtimeRepository {
+	return &realtimeRepositoryImpl{db: db}
+}
+
+func (r *realtimeRepositoryImpl) Notify(ctx context.Context, channel string, payload []byte) error {
+	return r.db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", channel, string(payload)).Error
+}
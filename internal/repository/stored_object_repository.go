@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"ping-badge-be/internal/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type StoredObjectRepository interface {
+	Create(ctx context.Context, object *model.StoredObject) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.StoredObject, error)
+	GetByKey(ctx context.Context, key string) (*model.StoredObject, error)
+}
+
+type storedObjectRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewStoredObjectRepository(db *gorm.DB) StoredObjectRepository {
+	return &storedObjectRepositoryImpl{db: db}
+}
+
+func (r *storedObjectRepositoryImpl) Create(ctx context.Context, object *model.StoredObject) error {
+	return r.db.WithContext(ctx).Create(object).Error
+}
+
+func (r *storedObjectRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*model.StoredObject, error) {
+	var object model.StoredObject
+	err := r.db.WithContext(ctx).First(&object, "object_id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &object, nil
+}
+
+func (r *storedObjectRepositoryImpl) GetByKey(ctx context.Context, key string) (*model.StoredObject, error) {
+	var object model.StoredObject
+	err := r.db.WithContext(ctx).First(&object, "key = ?", key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &object, nil
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Repositories groups repositories that share a single database handle. Inside
+// UnitOfWork.Do they are all bound to the same transaction.
+type Repositories struct {
+	Activities         ActivityRepository
+	Participations     ActivityParticipationRepository
+	Sessions           ActivitySessionRepository
+	CoHosts            ActivityCoHostRepository
+	CheckIns           CheckInRepository
+	Evidence           EvidenceRepository
+	Badges             BadgeRepository
+	Outbox             OutboxRepository
+	Jobs               JobRepository
+	BulkOperations     BulkOperationRepository
+	Organizations      *OrganizationRepository
+	OrganizationAdmins OrganizationAdminRepository
+	Notifications      NotificationRepository
+	Users              UserRepository
+	Emails             EmailRepository
+	Realtime           RealtimeRepository
+}
+
+func NewRepositories(db *gorm.DB) Repositories {
+	return Repositories{
+		Activities:         NewActivityRepository(db),
+		Participations:     NewActivityParticipationRepository(db),
+		Sessions:           NewActivitySessionRepository(db),
+		CoHosts:            NewActivityCoHostRepository(db),
+		CheckIns:           NewCheckInRepository(db),
+		Evidence:           NewEvidenceRepository(db),
+		Badges:             NewBadgeRepository(db),
+		Outbox:             NewOutboxRepository(db),
+		Jobs:               NewJobRepository(db),
+		BulkOperations:     NewBulkOperationRepository(db),
+		Organizations:      NewOrganizationRepository(db),
+		OrganizationAdmins: NewOrganizationAdminRepository(db),
+		Notifications:      NewNotificationRepository(db),
+		Users:              NewUserRepository(db),
+		Emails:             NewEmailRepository(db),
+		Realtime:           NewRealtimeRepository(db),
+	}
+}
+
+// UnitOfWork runs fn in a transaction. Returning an error from fn rolls back
+// every write made through the supplied repositories, including outbox events.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(repos Repositories) error) error
+}
+
+type gormUnitOfWork struct {
+	db *gorm.DB
+}
+
+func NewUnitOfWork(db *gorm.DB) UnitOfWork {
+	return &gormUnitOfWork{db: db}
+}
+
+func (u *gormUnitOfWork) Do(ctx context.Context, fn func(repos Repositories) error) error {
+	return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(NewRepositories(tx))
+	})
+}
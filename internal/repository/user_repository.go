@@ -2,21 +2,45 @@ package repository
 
 import (
 	"context"
+	"ping-badge-be/internal/constant"
+	"ping-badge-be/internal/listquery"
 	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
+	"strings"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// UserListSchema lists the user fields list requests may filter and sort by.
+var UserListSchema = listquery.Schema{
+	Table: "users",
+	ID:    "user_id",
+	Fields: map[string]listquery.Field{
+		"username":   {Column: "username", Type: listquery.String, Sortable: true},
+		"email":      {Column: "email", Type: listquery.String, Sortable: true},
+		"full_name":  {Column: "full_name", Type: listquery.String, Sortable: true},
+		"role":       {Column: "role", Type: listquery.Enum, Values: []string{constant.RoleUser, constant.RoleOrganizer, constant.RoleAdmin}},
+		"locale":     {Column: "locale", Type: listquery.String},
+		"created_at": {Column: "created_at", Type: listquery.Time, Sortable: true},
+	},
+}
+
 type UserRepository interface {
 	Create(ctx context.Context, user *model.User) error
 	GetByID(ctx context.Context, id uuid.UUID) (*model.User, error)
 	FindByID(ctx context.Context, id interface{}) (*model.User, error)
 	FindByEmail(ctx context.Context, email string) (*model.User, error)
+	FindByIDs(ctx context.Context, ids []uuid.UUID) ([]model.User, error)
+	FindByEmails(ctx context.Context, emails []string) ([]model.User, error)
 	FindByEmailOrUsername(ctx context.Context, email, username string) (*model.User, error)
-	List(ctx context.Context, offset, limit int) ([]model.User, error)
+	List(ctx context.Context, criteria listquery.Query, page pagination.Params) ([]model.User, error)
+	Count(ctx context.Context, criteria listquery.Query) (int64, error)
 	Update(ctx context.Context, user *model.User) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	FindByCalendarToken(ctx context.Context, token string) (*model.User, error)
+	EnsureCalendarToken(ctx context.Context, id uuid.UUID, token string) (string, error)
+	SetCalendarToken(ctx context.Context, id uuid.UUID, token string) error
 }
 
 func (r *userRepositoryImpl) FindByID(ctx context.Context, id interface{}) (*model.User, error) {
@@ -37,6 +61,29 @@ func (r *userRepositoryImpl) FindByEmail(ctx context.Context, email string) (*mo
 	return &user, nil
 }
 
+func (r *userRepositoryImpl) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]model.User, error) {
+	var users []model.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+	err := r.db.WithContext(ctx).Where("user_id IN ?", ids).Find(&users).Error
+	return users, err
+}
+
+// FindByEmails returns the users with any of the emails, ignoring case.
+func (r *userRepositoryImpl) FindByEmails(ctx context.Context, emails []string) ([]model.User, error) {
+	var users []model.User
+	if len(emails) == 0 {
+		return users, nil
+	}
+	lowered := make([]string, len(emails))
+	for i, email := range emails {
+		lowered[i] = strings.ToLower(email)
+	}
+	err := r.db.WithContext(ctx).Where("LOWER(email) IN ?", lowered).Find(&users).Error
+	return users, err
+}
+
 func (r *userRepositoryImpl) FindByEmailOrUsername(ctx context.Context, email, username string) (*model.User, error) {
 	var user model.User
 	err := r.db.WithContext(ctx).Table("users").Where("email = ? OR username = ?", email, username).First(&user).Error
@@ -67,12 +114,24 @@ func (r *userRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*model.
 	return &user, nil
 }
 
-func (r *userRepositoryImpl) List(ctx context.Context, offset, limit int) ([]model.User, error) {
+func (r *userRepositoryImpl) List(ctx context.Context, criteria listquery.Query, page pagination.Params) ([]model.User, error) {
 	var users []model.User
-	err := r.db.WithContext(ctx).Offset(offset).Limit(limit).Find(&users).Error
+	query := criteria.Where(r.db.WithContext(ctx).Model(&model.User{}))
+	if criteria.Sorted() {
+		query = criteria.Order(query)
+	} else {
+		query = query.Order("created_at ASC").Order("user_id ASC")
+	}
+	err := page.Apply(query, pagination.Keyset{Table: "users", CreatedAt: "created_at", ID: "user_id"}).Find(&users).Error
 	return users, err
 }
 
+func (r *userRepositoryImpl) Count(ctx context.Context, criteria listquery.Query) (int64, error) {
+	var count int64
+	err := criteria.Where(r.db.WithContext(ctx).Model(&model.User{})).Count(&count).Error
+	return count, err
+}
+
 func (r *userRepositoryImpl) Update(ctx context.Context, user *model.User) error {
 	return r.db.WithContext(ctx).Save(user).Error
 }
@@ -80,3 +139,36 @@ func (r *userRepositoryImpl) Update(ctx context.Context, user *model.User) error
 func (r *userRepositoryImpl) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Delete(&model.User{}, "user_id = ?", id).Error
 }
+
+func (r *userRepositoryImpl) FindByCalendarToken(ctx context.Context, token string) (*model.User, error) {
+	var user model.User
+	err := r.db.WithContext(ctx).First(&user, "calendar_token = ?", token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// EnsureCalendarToken stores token unless the user already has one and
+// returns the token in effect, so concurrent callers agree on it.
+func (r *userRepositoryImpl) EnsureCalendarToken(ctx context.Context, id uuid.UUID, token string) (string, error) {
+	db := r.db.WithContext(ctx)
+	err := db.Model(&model.User{}).
+		Where("user_id = ? AND calendar_token IS NULL", id).
+		Update("calendar_token", token).Error
+	if err != nil {
+		return "", err
+	}
+	var current string
+	err = db.Model(&model.User{}).
+		Where("user_id = ?", id).
+		Select("calendar_token").
+		Scan(&current).Error
+	return current, err
+}
+
+func (r *userRepositoryImpl) SetCalendarToken(ctx context.Context, id uuid.UUID, token string) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).
+		Where("user_id = ?", id).
+		Update("calendar_token", token).Error
+}
@@ -0,0 +1,131 @@
+// Package roster reads and writes participant rosters as CSV or XLSX
+// spreadsheets. A roster is a list of rows of cells; the first row is the
+// header.
+package roster
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Formats.
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// sheetName is the worksheet rosters are written to.
+const sheetName = "Roster"
+
+var ErrUnknownFormat = errors.New("format must be csv or xlsx")
+
+// ContentType returns the MIME type of format.
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// FormatOf returns the format of a file by its extension, or "" if unknown.
+func FormatOf(filename string) string {
+	name := strings.ToLower(filename)
+	switch {
+	case strings.HasSuffix(name, ".csv"):
+		return FormatCSV
+	case strings.HasSuffix(name, ".xlsx"):
+		return FormatXLSX
+	}
+	return ""
+}
+
+// Write encodes rows in format.
+func Write(w io.Writer, format string, rows [][]string) error {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		for _, row := range rows {
+			escaped := make([]string, len(row))
+			for i, cell := range row {
+				escaped[i] = escapeFormula(cell)
+			}
+			if err := cw.Write(escaped); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case FormatXLSX:
+		f := excelize.NewFile()
+		defer f.Close()
+		if err := f.SetSheetName(f.GetSheetName(0), sheetName); err != nil {
+			return err
+		}
+		for i, row := range rows {
+			cell, err := excelize.CoordinatesToCellName(1, i+1)
+			if err != nil {
+				return err
+			}
+			// Cells are written as strings so they are never evaluated.
+			values := make([]interface{}, len(row))
+			for j, value := range row {
+				values[j] = value
+			}
+			if err := f.SetSheetRow(sheetName, cell, &values); err != nil {
+				return err
+			}
+		}
+		return f.Write(w)
+	}
+	return ErrUnknownFormat
+}
+
+// utf8BOM is the byte order mark spreadsheet apps put before UTF-8 CSV.
+const utf8BOM = "\ufeff"
+
+// Read decodes rows in format. For XLSX the first worksheet is read. Rows may
+// have fewer cells than the header. A leading UTF-8 BOM in CSV is skipped.
+func Read(r io.Reader, format string) ([][]string, error) {
+	switch format {
+	case FormatCSV:
+		br := bufio.NewReader(r)
+		if prefix, _ := br.Peek(len(utf8BOM)); string(prefix) == utf8BOM {
+			br.Discard(len(utf8BOM))
+		}
+		cr := csv.NewReader(br)
+		cr.FieldsPerRecord = -1
+		cr.TrimLeadingSpace = true
+		rows, err := cr.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		return rows, nil
+	case FormatXLSX:
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid XLSX: %w", err)
+		}
+		defer f.Close()
+		rows, err := f.GetRows(f.GetSheetName(0))
+		if err != nil {
+			return nil, fmt.Errorf("invalid XLSX: %w", err)
+		}
+		return rows, nil
+	}
+	return nil, ErrUnknownFormat
+}
+
+// escapeFormula prefixes cells that spreadsheet apps would evaluate as a
+// formula, so exported user data cannot run in the organizer's spreadsheet.
+func escapeFormula(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
@@ -0,0 +1,43 @@
+package roster
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTrip(t *testing.T) {
+	rows := [][]string{
+		{"email", "full_name", "status"},
+		{"an@example.com", "Nguyễn Văn An", "REGISTERED"},
+		{"bo@example.com", "Bo, Jr.", "WAITLISTED"},
+	}
+	for _, format := range []string{FormatCSV, FormatXLSX} {
+		var buf bytes.Buffer
+		require.NoError(t, Write(&buf, format, rows), format)
+		got, err := Read(&buf, format)
+		require.NoError(t, err, format)
+		assert.Equal(t, rows, got, format)
+	}
+}
+
+func TestReadCSVSkipsBOM(t *testing.T) {
+	rows, err := Read(strings.NewReader("\ufeffemail,full_name\nan@example.com,An\n"), FormatCSV)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"email", "full_name"}, {"an@example.com", "An"}}, rows)
+}
+
+func TestWriteCSVEscapesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, FormatCSV, [][]string{{"=HYPERLINK(\"x\")", "-1", "ok"}}))
+	assert.Equal(t, "\"'=HYPERLINK(\"\"x\"\")\",'-1,ok\n", buf.String())
+}
+
+func TestFormatOf(t *testing.T) {
+	assert.Equal(t, FormatCSV, FormatOf("Sign-ups.CSV"))
+	assert.Equal(t, FormatXLSX, FormatOf("sheet.xlsx"))
+	assert.Equal(t, "", FormatOf("sheet.xls"))
+}
@@ -1,17 +1,29 @@
 package router
 
 import (
+	"context"
+	"fmt"
+	"log"
 	"ping-badge-be/internal/api_impl"
 	"ping-badge-be/internal/config"
+	"ping-badge-be/internal/constant"
+	"ping-badge-be/internal/events"
+	"ping-badge-be/internal/jobs"
+	"ping-badge-be/internal/mailer"
 	"ping-badge-be/internal/middleware"
+	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/realtime"
 	"ping-badge-be/internal/repository"
 	"ping-badge-be/internal/service"
+	"ping-badge-be/internal/storage"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
-func Setup(db *gorm.DB, cfg *config.Config) *gin.Engine {
+func Setup(db *gorm.DB, cfg *config.Config, dispatcher *events.Dispatcher, runner *jobs.Runner, hub *realtime.Hub) *gin.Engine {
 	r := gin.Default()
 
 	// Middleware
@@ -26,40 +38,149 @@ func Setup(db *gorm.DB, cfg *config.Config) *gin.Engine {
 	userService := service.NewUserService(userRepo)
 	userAPI := api_impl.NewUserAPI(userService)
 
+	// Initialize file storage (local directory or S3-compatible bucket)
+	localStorage := storage.NewLocalStorage(cfg.StorageLocalDir, localStorageURL(cfg), cfg.JWTSecret)
+	var fileStore storage.Storage = localStorage
+	if cfg.StorageDriver == "s3" {
+		s3Store, err := storage.NewS3Storage(storage.S3Config{
+			Endpoint:     cfg.S3Endpoint,
+			Region:       cfg.S3Region,
+			Bucket:       cfg.S3Bucket,
+			AccessKey:    cfg.S3AccessKey,
+			SecretKey:    cfg.S3SecretKey,
+			UsePathStyle: cfg.S3UsePathStyle,
+		})
+		if err != nil {
+			log.Fatalf("failed to configure S3 storage: %v", err)
+		}
+		fileStore, localStorage = s3Store, nil
+	}
+	var scanner storage.Scanner = storage.NopScanner{}
+	if cfg.ClamdAddress != "" {
+		scanner = storage.NewClamdScanner(cfg.ClamdAddress)
+	}
+	storageService := service.NewStorageService(fileStore, scanner, repository.NewStoredObjectRepository(db), service.DefaultUploadPolicies(int64(cfg.UploadMaxBytes)), cfg.AppBaseURL)
+	storageAPI := api_impl.NewStorageAPI(storageService, localStorage)
+
 	// Initialize OrganizationAdmin API (layered architecture)
+	unitOfWork := repository.NewUnitOfWork(db)
 	orgAdminRepo := repository.NewOrganizationAdminRepository(db)
-	orgAdminService := service.NewOrganizationAdminService(orgAdminRepo)
+	orgRepo := repository.NewOrganizationRepository(db)
+	coHostRepo := repository.NewActivityCoHostRepository(db)
+	orgAdminService := service.NewOrganizationAdminService(orgAdminRepo, orgRepo, coHostRepo, unitOfWork)
 	orgAdminAPI := api_impl.NewOrganizationAdminAPI(orgAdminService)
 	// orgHandler removed: use OrganizationAPI for all organization routes (layered architecture)
-	orgRepo := repository.NewOrganizationRepository(db)
 	orgService := service.NewOrganizationService(orgRepo)
-	orgAPI := api_impl.NewOrganizationAPI(orgService)
+	orgAPI := api_impl.NewOrganizationAPI(orgService, orgAdminService, storageService)
 
 	// Initialize Badge API (layered architecture)
 	badgeRepo := repository.NewBadgeRepository(db)
 	badgeService := service.NewBadgeService(badgeRepo)
-	badgeAPI := api_impl.NewBadgeAPI(badgeService)
+	badgeAPI := api_impl.NewBadgeAPI(badgeService, service.NewBadgeImageService(storageService), orgAdminService)
 
 	// Initialize Activity API (layered architecture)
 	activityRepo := repository.NewActivityRepository(db)
 	participationRepo := repository.NewActivityParticipationRepository(db)
-	participationService := service.NewActivityParticipationService(participationRepo, activityRepo, badgeRepo)
-	activityService := service.NewActivityService(activityRepo)
+	sessionRepo := repository.NewActivitySessionRepository(db)
+	participationService := service.NewActivityParticipationService(participationRepo, activityRepo, badgeRepo, sessionRepo, userRepo, unitOfWork)
+	activityService := service.NewActivityService(activityRepo, badgeRepo, sessionRepo, unitOfWork)
+	activityTemplateService := service.NewActivityTemplateService(repository.NewActivityTemplateRepository(db), activityRepo, activityService)
+	coHostService := service.NewActivityCoHostService(coHostRepo, activityRepo, orgRepo, badgeRepo)
 	activityAPI := api_impl.NewActivityAPI(
 		activityService,
 		orgRepo,
 		badgeRepo,
 		participationService,
+		orgAdminService,
+		activityTemplateService,
+		coHostService,
 	)
 
 	// Initialize UserStatistics API (layered architecture)
 	userStatisticsAPI := api_impl.NewUserStatisticsAPI(badgeService, activityService, participationService)
 
+	// Initialize Evidence API (layered architecture)
+	evidenceService := service.NewEvidenceService(repository.NewEvidenceRepository(db), unitOfWork)
+	evidenceAPI := api_impl.NewEvidenceAPI(evidenceService, orgAdminService, storageService)
+
 	// Initialize ActivityParticipation API (layered architecture)
-	activityParticipationAPI := api_impl.NewActivityParticipationAPI(participationService)
+	rosterService := service.NewRosterService(participationRepo, userRepo, unitOfWork)
+	bulkStatusService := service.NewBulkStatusService(repository.NewBulkOperationRepository(db), participationRepo, unitOfWork)
+	activityParticipationAPI := api_impl.NewActivityParticipationAPI(participationService, activityService, orgAdminService, evidenceService, storageService, rosterService, bulkStatusService)
+
+	// Initialize ActivitySession API (layered architecture)
+	activitySessionAPI := api_impl.NewActivitySessionAPI(activityService, participationService)
+
+	// Initialize CheckIn API (layered architecture)
+	checkInService := service.NewCheckInService(activityRepo, participationRepo, sessionRepo, repository.NewCheckInRepository(db), unitOfWork, cfg.JWTSecret)
+	checkInAPI := api_impl.NewCheckInAPI(checkInService, activityService, participationService, orgAdminService)
+
+	// Outbox consumers
+	if cfg.OutboxWebhookURL != "" {
+		dispatcher.Subscribe(events.NewWebhookSink("webhook", cfg.OutboxWebhookURL, cfg.OutboxWebhookSecret))
+	}
+
+	// Background jobs (layered architecture)
+	jobRepo := repository.NewJobRepository(db)
+	jobService := service.NewJobService(jobRepo)
+	jobAPI := api_impl.NewJobAPI(jobService)
+	runner.Register(jobs.TypeOutboxCleanup, jobs.OutboxCleanup(repository.NewOutboxRepository(db), 7*24*time.Hour))
+	runner.Register(jobs.TypeJobCleanup, jobs.JobCleanup(jobRepo, 7*24*time.Hour))
+	mustSchedule(runner, "outbox-cleanup", "0 3 * * *", jobs.TypeOutboxCleanup)
+	mustSchedule(runner, "jobs-cleanup", "30 3 * * *", jobs.TypeJobCleanup)
+
+	// Initialize Notification API (layered architecture)
+	notificationService := service.NewNotificationService(repository.NewNotificationRepository(db), unitOfWork)
+	notificationAPI := api_impl.NewNotificationAPI(notificationService)
+	dispatcher.Subscribe(events.NewSubscriber("notifications", notificationService.HandleEvent))
+	runner.Register(jobs.TypeActivityReminders, func(ctx context.Context, job model.Job) error {
+		return notificationService.QueueActivityReminders(ctx, 24*time.Hour)
+	})
+	mustSchedule(runner, "activity-reminders", "*/15 * * * *", jobs.TypeActivityReminders)
+
+	// Initialize Calendar API (layered architecture)
+	calendarService := service.NewCalendarService(activityRepo, orgRepo, userRepo, cfg.AppBaseURL)
+	calendarAPI := api_impl.NewCalendarAPI(calendarService, cfg.AppBaseURL)
+
+	// Initialize Email API (layered architecture)
+	renderer, err := mailer.NewRenderer()
+	if err != nil {
+		log.Fatalf("failed to load email templates: %v", err)
+	}
+	var mailSender mailer.Mailer = mailer.NewFileMailer(cfg.MailLogDir)
+	if cfg.MailDriver == "smtp" {
+		mailSender = mailer.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword)
+	}
+	emailService := service.NewEmailService(repository.NewEmailRepository(db), unitOfWork, mailSender, renderer, cfg.MailFrom, cfg.AppBaseURL, cfg.JWTSecret)
+	emailAPI := api_impl.NewEmailAPI(emailService, cfg.EmailWebhookSecret)
+	dispatcher.Subscribe(events.NewSubscriber("email", emailService.HandleEvent))
+	runner.Register(jobs.TypeEmailSend, func(ctx context.Context, job model.Job) error {
+		emailID, err := uuid.Parse(fmt.Sprint(job.Payload["email_id"]))
+		if err != nil {
+			return jobs.Permanent(err)
+		}
+		return emailService.SendQueued(ctx, emailID)
+	})
+	runner.Register(jobs.TypeBulkStatus, func(ctx context.Context, job model.Job) error {
+		operationID, err := uuid.Parse(fmt.Sprint(job.Payload["operation_id"]))
+		if err != nil {
+			return jobs.Permanent(err)
+		}
+		return bulkStatusService.RunOperation(ctx, operationID)
+	})
+
+	// Initialize Stream API (layered architecture)
+	realtimeService := service.NewRealtimeService(activityRepo, orgAdminService)
+	streamAPI := api_impl.NewStreamAPI(hub, realtimeService)
+	dispatcher.Subscribe(events.NewSubscriber("realtime", realtimeService.HandleEvent,
+		model.EventParticipationCreated,
+		model.EventParticipationUpdated,
+		model.EventParticipationStatusChanged,
+		model.EventBadgeIssued,
+	))
 
 	// Public routes
-	api := r.Group("/api/v1")
+	api := r.Group(constant.APIPrefix)
 	{
 		// Health check
 		api.GET("/health", func(c *gin.Context) {
@@ -81,9 +202,24 @@ func Setup(db *gorm.DB, cfg *config.Config) *gin.Engine {
 		api.GET("/badges", badgeAPI.ListBadges)
 		api.GET("/badges/:id", badgeAPI.GetBadge)
 
+		// Email routes (use EmailAPI)
+		api.GET("/email/unsubscribe", emailAPI.Unsubscribe)
+		api.POST("/email/bounces", emailAPI.HandleBounce)
+
 		// Public activity routes (use ActivityAPI)
 		api.GET("/activities", activityAPI.ListActivities)
 		api.GET("/activities/:id", activityAPI.GetActivity)
+		api.GET("/activities/:id/sessions", activitySessionAPI.ListSessions)
+		api.GET("/activities/:id/co-hosts", activityAPI.ListCoHosts)
+
+		// Calendar routes (use CalendarAPI)
+		api.GET("/organizations/:id/activities.ics", calendarAPI.OrganizationFeed)
+		api.GET("/activities/:id/event.ics", calendarAPI.ActivityEvent)
+		api.GET("/calendar/:token", calendarAPI.UserFeed)
+
+		// File download routes (use StorageAPI)
+		api.GET("/files/:id", storageAPI.GetFile)
+		api.GET("/storage/*key", storageAPI.ServeLocal)
 	}
 
 	// Protected routes
@@ -99,9 +235,11 @@ func Setup(db *gorm.DB, cfg *config.Config) *gin.Engine {
 
 		// OrganizationAdmin routes (use OrganizationAdminAPI)
 		protected.POST("/organizations/:id/admins", orgAdminAPI.CreateAdmin)
-		protected.GET("/organizations/:id/admins", orgAdminAPI.GetAdmin)
+		protected.GET("/organizations/:id/admins", orgAdminAPI.ListAdmins)
+		protected.GET("/organizations/:id/admins/:admin_id", orgAdminAPI.GetAdmin)
 		protected.PUT("/organizations/:id/admins/:admin_id", orgAdminAPI.UpdateAdmin)
 		protected.DELETE("/organizations/:id/admins/:admin_id", orgAdminAPI.DeleteAdmin)
+		protected.POST("/organizations/:id/logo", orgAPI.UploadLogo)
 
 		// Badge routes (use BadgeAPI)
 		protected.POST("/organizations/:id/badges", badgeAPI.CreateBadge)
@@ -112,15 +250,62 @@ func Setup(db *gorm.DB, cfg *config.Config) *gin.Engine {
 		// Activity routes (use ActivityAPI)
 		protected.POST("/organizations/:id/activities", activityAPI.CreateActivity)
 		protected.PUT("/activities/:id", activityAPI.UpdateActivity)
+		protected.PATCH("/activities/:id", activityAPI.UpdateActivity)
 		protected.DELETE("/activities/:id", activityAPI.DeleteActivity)
-		// Add join and participations endpoints to ActivityAPI as needed
+		protected.POST("/activities/:activity_id/cancel", activityAPI.CancelActivity)
+		protected.POST("/activities/:activity_id/reschedule", activityAPI.RescheduleActivity)
+		protected.POST("/activities/:activity_id/archive", activityAPI.ArchiveActivity)
+		protected.POST("/activities/:activity_id/clone", activityAPI.CloneActivity)
+		protected.POST("/activities/:activity_id/co-hosts", activityAPI.SaveCoHost)
+		protected.DELETE("/activities/:id/co-hosts/:org_id", activityAPI.RemoveCoHost)
+		protected.POST("/activities/:activity_id/co-hosts/:org_id/accept", activityAPI.AcceptCoHost)
+		protected.GET("/organizations/:id/co-host-invitations", activityAPI.ListCoHostInvitations)
+		protected.POST("/activities/:activity_id/template", activityAPI.SaveAsTemplate)
+		protected.GET("/organizations/:id/activity-templates", activityAPI.ListTemplates)
+		protected.GET("/activity-templates/:id", activityAPI.GetTemplate)
+		protected.DELETE("/activity-templates/:id", activityAPI.DeleteTemplate)
+		protected.POST("/activity-templates/:id/activities", activityAPI.CreateFromTemplate)
+		protected.POST("/activities/:activity_id/join", activityParticipationAPI.JoinActivity)
+		protected.DELETE("/activities/:id/join", activityParticipationAPI.LeaveActivity)
+		protected.GET("/activities/:id/check-in-code", checkInAPI.GetCheckInCode)
+		protected.POST("/activities/:activity_id/check-in", checkInAPI.CheckIn)
+		protected.GET("/activities/:id/check-ins", checkInAPI.ListCheckIns)
+		protected.PUT("/check-ins/:id/review", checkInAPI.ReviewCheckIn)
 
 		// ActivityParticipation routes
 		protected.GET("/participations", activityParticipationAPI.ListParticipations)
 		protected.GET("/participations/:id", activityParticipationAPI.GetParticipation)
 		protected.POST("/activities/:activity_id/participations", activityParticipationAPI.CreateParticipation)
+		protected.GET("/activities/:id/participations/export", activityParticipationAPI.ExportRoster)
+		protected.POST("/activities/:activity_id/participations/import", activityParticipationAPI.ImportRoster)
+		protected.POST("/activities/:activity_id/participations/bulk-status", activityParticipationAPI.BulkUpdateStatus)
+		protected.GET("/bulk-operations/:id", activityParticipationAPI.GetBulkOperation)
+		protected.GET("/participations/:id/evidence", activityParticipationAPI.ListEvidence)
+		protected.POST("/participations/:id/evidence", activityParticipationAPI.UploadEvidence)
 		protected.PUT("/participations/:id/evidence", activityParticipationAPI.UploadEvidence)
 		protected.PUT("/participations/:id/status", activityParticipationAPI.UpdateParticipationStatus)
+		protected.POST("/participations/:id/withdraw", activityParticipationAPI.WithdrawParticipation)
+		protected.GET("/participations/:id/history", activityParticipationAPI.ListStatusHistory)
+		protected.GET("/participations/:id/check-in-pass", checkInAPI.GetPass)
+
+		// Evidence review routes
+		protected.GET("/evidence/:id", evidenceAPI.GetEvidence)
+		protected.GET("/evidence/:id/file", evidenceAPI.GetEvidenceFile)
+		protected.POST("/evidence/:id/review", evidenceAPI.ReviewEvidence)
+		protected.POST("/evidence/:id/comments", evidenceAPI.AddComment)
+		protected.GET("/organizations/:id/review-queue", evidenceAPI.ReviewQueue)
+
+		// ActivitySession attendance routes
+		protected.GET("/sessions/:id/attendance", activitySessionAPI.ListAttendance)
+		protected.POST("/sessions/:id/attendance", activitySessionAPI.RecordAttendance)
+
+		// Notification routes
+		protected.GET("/notifications", notificationAPI.ListNotifications)
+		protected.GET("/notifications/unread-count", notificationAPI.GetUnreadCount)
+		protected.PUT("/notifications/read-all", notificationAPI.MarkAllRead)
+		protected.PUT("/notifications/:id/read", notificationAPI.MarkRead)
+		protected.GET("/notifications/preferences", notificationAPI.GetPreferences)
+		protected.PUT("/notifications/preferences", notificationAPI.UpdatePreferences)
 
 		// User statistics route
 		protected.GET("/users/:id/statistics", userStatisticsAPI.GetUserStatistics)
@@ -128,7 +313,32 @@ func Setup(db *gorm.DB, cfg *config.Config) *gin.Engine {
 		// Auth protected routes
 		protected.GET("/auth/profile", authAPI.GetProfile)
 		protected.PUT("/auth/profile", authAPI.UpdateProfile)
+		protected.GET("/auth/calendar-feed", calendarAPI.GetFeedURL)
+		protected.POST("/auth/calendar-feed/reset", calendarAPI.ResetFeedURL)
+	}
+
+	// Realtime stream (Server-Sent Events); accepts ?access_token= for EventSource clients
+	api.GET("/stream", middleware.StreamAuthMiddleware(cfg.JWTSecret), streamAPI.Stream)
+
+	// Admin routes
+	admin := api.Group("/admin")
+	admin.Use(middleware.AuthMiddleware(cfg.JWTSecret), middleware.RequireRole(constant.RoleAdmin))
+	{
+		admin.GET("/jobs", jobAPI.ListJobs)
+		admin.GET("/jobs/:id", jobAPI.GetJob)
+		admin.POST("/jobs/:id/retry", jobAPI.RetryJob)
 	}
 
 	return r
 }
+
+// localStorageURL is where local files are served, by the storage route.
+func localStorageURL(cfg *config.Config) string {
+	return cfg.AppBaseURL + constant.APIPrefix + "/storage"
+}
+
+func mustSchedule(runner *jobs.Runner, name, cronExpr, jobType string) {
+	if err := runner.Schedule(name, cronExpr, jobType, nil); err != nil {
+		log.Fatalf("invalid schedule %s: %v", name, err)
+	}
+}
@@ -0,0 +1,92 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"ping-badge-be/internal/config"
+	"ping-badge-be/internal/events"
+	"ping-badge-be/internal/jobs"
+	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/realtime"
+	"ping-badge-be/internal/repository"
+	"ping-badge-be/internal/service"
+	"ping-badge-be/internal/storage"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// testEngine sets up the real routes over a database that is never
+// connected to.
+func testEngine(t *testing.T) (*gin.Engine, *config.Config) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(postgres.Open("host=localhost dbname=unused"), &gorm.Config{DisableAutomaticPing: true})
+	require.NoError(t, err)
+	cfg := config.Load()
+	cfg.AppBaseURL = "https://badges.example.com"
+	cfg.StorageDriver = "local"
+	cfg.StorageLocalDir = t.TempDir()
+	uow := repository.NewUnitOfWork(db)
+	dispatcher := events.NewDispatcher(repository.NewOutboxRepository(db), uow)
+	runner := jobs.NewRunner(repository.NewJobRepository(db), 1)
+	return Setup(db, cfg, dispatcher, runner, realtime.NewHub()), cfg
+}
+
+// resolves reports whether a GET of link reaches a route of engine.
+func resolves(engine *gin.Engine, link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	path := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for _, route := range engine.Routes() {
+		if route.Method != http.MethodGet {
+			continue
+		}
+		pattern := strings.Split(strings.Trim(route.Path, "/"), "/")
+		if matches(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func matches(pattern, path []string) bool {
+	for i, segment := range pattern {
+		if strings.HasPrefix(segment, "*") {
+			return len(path) > i
+		}
+		if i >= len(path) || (!strings.HasPrefix(segment, ":") && segment != path[i]) {
+			return false
+		}
+	}
+	return len(pattern) == len(path)
+}
+
+func TestGeneratedURLsResolve(t *testing.T) {
+	engine, cfg := testEngine(t)
+
+	signed, err := storage.NewLocalStorage(cfg.StorageLocalDir, localStorageURL(cfg), cfg.JWTSecret).
+		SignedURL(context.Background(), "evidence/proof.png", time.Minute)
+	require.NoError(t, err)
+	storageService := service.NewStorageService(nil, nil, nil, nil, cfg.AppBaseURL)
+	links := []string{
+		signed,
+		storageService.PublicURL(&model.StoredObject{ObjectID: uuid.New()}),
+		service.EvidenceFilePath(uuid.New()),
+		cfg.AppBaseURL + service.CalendarFeedPath("0123456789abcdef"),
+		cfg.AppBaseURL + service.ActivityPath(uuid.New()),
+	}
+	for _, link := range links {
+		assert.True(t, resolves(engine, link), link)
+	}
+	assert.False(t, resolves(engine, "/api/files/"+uuid.NewString()))
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ActivityCoHostService interface {
+	SaveCoHost(ctx context.Context, host *model.ActivityCoHost) error
+	ListCoHosts(ctx context.Context, activityID uuid.UUID) ([]model.ActivityCoHost, error)
+	ListInvitations(ctx context.Context, orgID uuid.UUID) ([]model.ActivityCoHost, error)
+	AcceptCoHost(ctx context.Context, activityID, orgID uuid.UUID) (*model.ActivityCoHost, error)
+	RemoveCoHost(ctx context.Context, activityID, orgID uuid.UUID) error
+}
+
+var (
+	ErrCoHostIsHost            = errors.New("the hosting organization cannot be a co-host")
+	ErrCoHostNotFound          = errors.New("co-host organization not found")
+	ErrInvalidCoHostPermission = errors.New("permissions must be manage_participations or edit_activity")
+)
+
+type activityCoHostServiceImpl struct {
+	repo         repository.ActivityCoHostRepository
+	activityRepo repository.ActivityRepository
+	orgRepo      *repository.OrganizationRepository
+	badgeRepo    repository.BadgeRepository
+}
+
+func NewActivityCoHostService(repo repository.ActivityCoHostRepository, activityRepo repository.ActivityRepository, orgRepo *repository.OrganizationRepository, badgeRepo repository.BadgeRepository) ActivityCoHostService {
+	return &activityCoHostServiceImpl{repo: repo, activityRepo: activityRepo, orgRepo: orgRepo, badgeRepo: badgeRepo}
+}
+
+// SaveCoHost adds the organization as a co-host of the activity, or updates
+// its permissions and badge. The badge must be an active badge of the co-host.
+// A new co-host is pending until its staff accept, unless host.AcceptedAt is
+// set; host is reloaded with the stored acceptance.
+func (s *activityCoHostServiceImpl) SaveCoHost(ctx context.Context, host *model.ActivityCoHost) error {
+	activity, err := s.activityRepo.FindByID(host.ActivityID)
+	if err != nil {
+		return err
+	}
+	if host.OrgID == activity.OrgID {
+		return ErrCoHostIsHost
+	}
+	if _, err := s.orgRepo.GetByID(ctx, host.OrgID); errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrCoHostNotFound
+	} else if err != nil {
+		return err
+	}
+	for _, permission := range host.Permissions {
+		if !isCoHostPermission(permission) {
+			return ErrInvalidCoHostPermission
+		}
+	}
+	if host.BadgeDefID != nil {
+		badge, err := s.badgeRepo.GetByID(ctx, *host.BadgeDefID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrBadgeNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if badge.OrgID != host.OrgID {
+			return ErrBadgeNotInOrganization
+		}
+		if !badge.IsActive {
+			return ErrBadgeInactive
+		}
+	}
+	if err := s.repo.Save(ctx, host); err != nil {
+		return err
+	}
+	saved, err := s.repo.Get(ctx, host.ActivityID, host.OrgID)
+	if err != nil {
+		return err
+	}
+	*host = *saved
+	return nil
+}
+
+// ListCoHosts returns the co-hosts that accepted.
+func (s *activityCoHostServiceImpl) ListCoHosts(ctx context.Context, activityID uuid.UUID) ([]model.ActivityCoHost, error) {
+	return s.repo.ListByActivity(ctx, activityID, true)
+}
+
+// ListInvitations returns the activities waiting for the organization to
+// accept co-hosting them.
+func (s *activityCoHostServiceImpl) ListInvitations(ctx context.Context, orgID uuid.UUID) ([]model.ActivityCoHost, error) {
+	return s.repo.ListPending(ctx, orgID)
+}
+
+// AcceptCoHost puts the organization's co-hosting of the activity into
+// effect. Accepting again is a no-op.
+func (s *activityCoHostServiceImpl) AcceptCoHost(ctx context.Context, activityID, orgID uuid.UUID) (*model.ActivityCoHost, error) {
+	if _, err := s.repo.Get(ctx, activityID, orgID); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Accept(ctx, activityID, orgID); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, activityID, orgID)
+}
+
+func (s *activityCoHostServiceImpl) RemoveCoHost(ctx context.Context, activityID, orgID uuid.UUID) error {
+	if _, err := s.repo.Get(ctx, activityID, orgID); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, activityID, orgID)
+}
+
+func isCoHostPermission(permission string) bool {
+	for _, known := range model.CoHostPermissions {
+		if permission == known {
+			return true
+		}
+	}
+	return false
+}
@@ -2,142 +2,618 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
 	"ping-badge-be/internal/repository"
+	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type ActivityParticipationService interface {
-	CreateParticipation(ctx context.Context, participation *model.ActivityParticipation) error
+	CreateParticipation(ctx context.Context, participation *model.ActivityParticipation, actor Actor) error
 	GetParticipation(ctx context.Context, id uuid.UUID) (*model.ActivityParticipation, error)
-	ListParticipations(ctx context.Context, activityID *uuid.UUID, userID *uuid.UUID, status *string, offset, limit int) ([]model.ActivityParticipation, error)
-	UpdateParticipation(ctx context.Context, id uuid.UUID, updates map[string]interface{}) (*model.ActivityParticipation, error)
-	UpdateParticipationWithBadgeCreation(ctx context.Context, id uuid.UUID, proofURL *string, status string) (*model.ActivityParticipation, error)
-	DeleteParticipation(ctx context.Context, id uuid.UUID) error
+	ListParticipations(ctx context.Context, filter repository.ParticipationFilter, page pagination.Params) ([]model.ActivityParticipation, int64, error)
+	AttachSummaries(ctx context.Context, participations []model.ActivityParticipation, users, activities bool) error
+	UpdateParticipationWithBadgeCreation(ctx context.Context, id uuid.UUID, proofURL *string, status string, actor Actor) (*model.ActivityParticipation, error)
+	WithdrawParticipation(ctx context.Context, id uuid.UUID, actor Actor) (*model.ActivityParticipation, error)
+	JoinActivity(ctx context.Context, activityID, userID uuid.UUID) (*model.ActivityParticipation, error)
+	LeaveActivity(ctx context.Context, activityID, userID uuid.UUID) (*model.ActivityParticipation, error)
+	RecordAttendance(ctx context.Context, sessionID, participationID uuid.UUID) (*model.SessionAttendance, *model.ActivityParticipation, error)
+	ListAttendance(ctx context.Context, sessionID uuid.UUID) ([]model.SessionAttendance, error)
+	ListTransitions(ctx context.Context, id uuid.UUID) ([]model.ParticipationStatusTransition, error)
 }
 
+// maxWaitlistPromotions bounds a single promotion pass when an activity has
+// no capacity limit.
+const maxWaitlistPromotions = 1000
+
+var (
+	ErrRegistrationNotOpen       = errors.New("registration has not opened yet")
+	ErrRegistrationClosed        = errors.New("registration is closed")
+	ErrInvalidStatus             = errors.New("unknown participation status")
+	ErrAlreadyJoined             = errors.New("user already participates in this activity")
+	ErrNotJoined                 = errors.New("user does not participate in this activity")
+	ErrActivityEnded             = errors.New("activity has already ended")
+	ErrSessionCancelled          = errors.New("session is cancelled")
+	ErrParticipationNotInSession = errors.New("participation does not belong to the session's activity")
+)
+
 type activityParticipationServiceImpl struct {
 	repo         repository.ActivityParticipationRepository
 	activityRepo repository.ActivityRepository
 	badgeRepo    repository.BadgeRepository
+	sessionRepo  repository.ActivitySessionRepository
+	userRepo     repository.UserRepository
+	uow          repository.UnitOfWork
 }
 
-func NewActivityParticipationService(repo repository.ActivityParticipationRepository, activityRepo repository.ActivityRepository, badgeRepo repository.BadgeRepository) ActivityParticipationService {
+func NewActivityParticipationService(repo repository.ActivityParticipationRepository, activityRepo repository.ActivityRepository, badgeRepo repository.BadgeRepository, sessionRepo repository.ActivitySessionRepository, userRepo repository.UserRepository, uow repository.UnitOfWork) ActivityParticipationService {
 	return &activityParticipationServiceImpl{
 		repo:         repo,
 		activityRepo: activityRepo,
 		badgeRepo:    badgeRepo,
+		sessionRepo:  sessionRepo,
+		userRepo:     userRepo,
+		uow:          uow,
 	}
 }
 
-func (s *activityParticipationServiceImpl) CreateParticipation(ctx context.Context, participation *model.ActivityParticipation) error {
+// CreateParticipation registers a participant while holding a lock on the
+// activity, so concurrent sign-ups cannot exceed its capacity. Registrations
+// beyond capacity are put at the end of the waitlist. A participation always
+// starts as registered or waitlisted.
+func (s *activityParticipationServiceImpl) CreateParticipation(ctx context.Context, participation *model.ActivityParticipation, actor Actor) error {
 	// Add business logic, validation, authorization here
-	return s.repo.Create(participation)
+	return s.uow.Do(ctx, func(repos repository.Repositories) error {
+		return createParticipation(ctx, repos, participation, actor)
+	})
+}
+
+// createParticipation is CreateParticipation within the caller's transaction.
+func createParticipation(ctx context.Context, repos repository.Repositories, participation *model.ActivityParticipation, actor Actor) error {
+	activity, err := repos.Activities.FindByIDForUpdate(participation.ActivityID)
+	if err != nil {
+		return err
+	}
+	if err := checkActivityOpen(activity); err != nil {
+		return err
+	}
+	now := time.Now()
+	if activity.RegistrationOpensAt != nil && now.Before(*activity.RegistrationOpensAt) {
+		return ErrRegistrationNotOpen
+	}
+	if activity.RegistrationClosesAt != nil && now.After(*activity.RegistrationClosesAt) {
+		return ErrRegistrationClosed
+	}
+
+	_, err = repos.Participations.FindActive(participation.ActivityID, participation.UserID)
+	if err == nil {
+		return ErrAlreadyJoined
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	switch participation.Status {
+	case "":
+		participation.Status = model.ParticipationRegistered
+	case model.ParticipationRegistered, model.ParticipationWaitlisted:
+	default:
+		return fmt.Errorf("%w: participation cannot start as %s", ErrIllegalTransition, participation.Status)
+	}
+	if participation.Status == model.ParticipationWaitlisted && participation.WaitlistPosition == nil {
+		position, err := repos.Participations.NextWaitlistPosition(activity.ActivityID)
+		if err != nil {
+			return err
+		}
+		participation.WaitlistPosition = &position
+	}
+	if participation.Status == model.ParticipationRegistered && activity.Capacity != nil {
+		registered, err := repos.Participations.CountRegistered(activity.ActivityID)
+		if err != nil {
+			return err
+		}
+		if registered >= int64(*activity.Capacity) {
+			position, err := repos.Participations.NextWaitlistPosition(activity.ActivityID)
+			if err != nil {
+				return err
+			}
+			participation.Status = model.ParticipationWaitlisted
+			participation.WaitlistPosition = &position
+		}
+	}
+
+	if err := repos.Participations.Create(participation); err != nil {
+		return err
+	}
+	if err := recordTransition(repos, participation.ParticipationID, "", participation.Status, actor, ""); err != nil {
+		return err
+	}
+	return repos.Outbox.Append(ctx, participationEvent(participation, model.EventParticipationCreated))
 }
 
 func (s *activityParticipationServiceImpl) GetParticipation(ctx context.Context, id uuid.UUID) (*model.ActivityParticipation, error) {
 	return s.repo.FindByID(id)
 }
 
-func (s *activityParticipationServiceImpl) ListParticipations(ctx context.Context, activityID *uuid.UUID, userID *uuid.UUID, status *string, offset, limit int) ([]model.ActivityParticipation, error) {
-	return s.repo.FindAll(activityID, userID, status, offset, limit)
+// ListParticipations returns a page of the participations matching filter
+// and how many match in total.
+func (s *activityParticipationServiceImpl) ListParticipations(ctx context.Context, filter repository.ParticipationFilter, page pagination.Params) ([]model.ActivityParticipation, int64, error) {
+	total, err := s.repo.Count(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	participations, err := s.repo.FindAll(filter, page)
+	if err != nil {
+		return nil, 0, err
+	}
+	return participations, total, nil
 }
 
-func (s *activityParticipationServiceImpl) UpdateParticipation(ctx context.Context, id uuid.UUID, updates map[string]interface{}) (*model.ActivityParticipation, error) {
-	return s.repo.Update(id, updates)
+// AttachSummaries fills in the user and/or activity summaries of the
+// participations, loading each user and activity once.
+func (s *activityParticipationServiceImpl) AttachSummaries(ctx context.Context, participations []model.ActivityParticipation, users, activities bool) error {
+	if users {
+		ids := make([]uuid.UUID, len(participations))
+		for i, p := range participations {
+			ids[i] = p.UserID
+		}
+		found, err := s.userRepo.FindByIDs(ctx, ids)
+		if err != nil {
+			return err
+		}
+		byID := make(map[uuid.UUID]*model.UserSummary, len(found))
+		for _, user := range found {
+			byID[user.UserID] = &model.UserSummary{
+				UserID:            user.UserID,
+				Username:          user.Username,
+				FullName:          user.FullName,
+				ProfilePictureURL: user.ProfilePictureURL,
+			}
+		}
+		for i := range participations {
+			participations[i].User = byID[participations[i].UserID]
+		}
+	}
+	if activities {
+		ids := make([]uuid.UUID, len(participations))
+		for i, p := range participations {
+			ids[i] = p.ActivityID
+		}
+		found, err := s.activityRepo.FindByIDs(ids)
+		if err != nil {
+			return err
+		}
+		byID := make(map[uuid.UUID]*model.ActivitySummary, len(found))
+		for _, activity := range found {
+			byID[activity.ActivityID] = &model.ActivitySummary{
+				ActivityID:   activity.ActivityID,
+				OrgID:        activity.OrgID,
+				ActivityName: activity.ActivityName,
+				StartDate:    activity.StartDate,
+				EndDate:      activity.EndDate,
+				Status:       activity.Status,
+			}
+		}
+		for i := range participations {
+			participations[i].Activity = byID[participations[i].ActivityID]
+		}
+	}
+	return nil
 }
 
-func (s *activityParticipationServiceImpl) DeleteParticipation(ctx context.Context, id uuid.UUID) error {
-	return s.repo.Delete(id)
+// JoinActivity registers the user for an activity that has not ended yet.
+func (s *activityParticipationServiceImpl) JoinActivity(ctx context.Context, activityID, userID uuid.UUID) (*model.ActivityParticipation, error) {
+	activity, err := s.activityRepo.FindByID(activityID)
+	if err != nil {
+		return nil, err
+	}
+	end := activity.EndDate
+	if end == nil {
+		end = activity.StartDate
+	}
+	if end != nil && time.Now().After(*end) {
+		return nil, ErrActivityEnded
+	}
+	participation := &model.ActivityParticipation{
+		ActivityID: activityID,
+		UserID:     userID,
+		CreatedAt:  time.Now(),
+	}
+	if err := s.CreateParticipation(ctx, participation, UserActor(ActorParticipant, userID)); err != nil {
+		return nil, err
+	}
+	return participation, nil
 }
 
-func (s *activityParticipationServiceImpl) UpdateParticipationWithBadgeCreation(ctx context.Context, id uuid.UUID, proofURL *string, status string) (*model.ActivityParticipation, error) {
-	// Get the current participation
-	_, err := s.repo.FindByID(id)
+// LeaveActivity withdraws the user's active participation in the activity.
+func (s *activityParticipationServiceImpl) LeaveActivity(ctx context.Context, activityID, userID uuid.UUID) (*model.ActivityParticipation, error) {
+	participation, err := s.repo.FindActive(activityID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotJoined
+	}
 	if err != nil {
 		return nil, err
 	}
+	return s.WithdrawParticipation(ctx, participation.ParticipationID, UserActor(ActorParticipant, userID))
+}
 
-	// Prepare updates
-	updates := make(map[string]interface{})
-	if proofURL != nil {
-		updates["proof_of_participation_url"] = *proofURL
+// WithdrawParticipation withdraws the participant and promotes the next
+// waitlisted participant into the freed place. Withdrawing twice is a no-op.
+func (s *activityParticipationServiceImpl) WithdrawParticipation(ctx context.Context, id uuid.UUID, actor Actor) (*model.ActivityParticipation, error) {
+	var participation *model.ActivityParticipation
+	err := s.uow.Do(ctx, func(repos repository.Repositories) error {
+		var err error
+		participation, err = repos.Participations.FindByID(id)
+		if err != nil {
+			return err
+		}
+		if participation.Status == model.ParticipationWithdrawn {
+			return nil
+		}
+		if err := checkTransition(participation.Status, model.ParticipationWithdrawn, actor); err != nil {
+			return err
+		}
+		activity, err := repos.Activities.FindByIDForUpdate(participation.ActivityID)
+		if err != nil {
+			return err
+		}
+
+		previousStatus := participation.Status
+		participation, err = transitionParticipation(ctx, repos, participation, model.ParticipationWithdrawn, actor, "", map[string]interface{}{
+			"waitlist_position": nil,
+		})
+		if err != nil {
+			return err
+		}
+		if previousStatus == model.ParticipationWaitlisted {
+			return nil
+		}
+		return promoteFromWaitlist(ctx, repos, activity)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return participation, nil
+}
+
+// promoteFromWaitlist registers waitlisted participants, in waitlist order,
+// until the activity is full again. The caller must hold the activity lock.
+func promoteFromWaitlist(ctx context.Context, repos repository.Repositories, activity *model.Activity) error {
+	if activity.Status != model.ActivityActive {
+		return nil
+	}
+	free := maxWaitlistPromotions
+	if activity.Capacity != nil {
+		registered, err := repos.Participations.CountRegistered(activity.ActivityID)
+		if err != nil {
+			return err
+		}
+		free = *activity.Capacity - int(registered)
+	}
+	if free <= 0 {
+		return nil
+	}
+	waitlisted, err := repos.Participations.FindWaitlisted(activity.ActivityID, free)
+	if err != nil {
+		return err
+	}
+	for i := range waitlisted {
+		_, err := transitionParticipation(ctx, repos, &waitlisted[i], model.ParticipationRegistered, SystemActor(), "waitlist_promotion", map[string]interface{}{
+			"waitlist_position": nil,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// transitionParticipation moves the participation to status on behalf of
+// actor, together with any extra column updates, and records the change in
+// the status history and the outbox.
+func transitionParticipation(ctx context.Context, repos repository.Repositories, participation *model.ActivityParticipation, status string, actor Actor, reason string, extra map[string]interface{}) (*model.ActivityParticipation, error) {
+	if err := checkTransition(participation.Status, status, actor); err != nil {
+		return nil, err
+	}
+	updates := map[string]interface{}{"status": status}
+	for column, value := range extra {
+		updates[column] = value
+	}
+	previousStatus := participation.Status
+	updated, err := repos.Participations.Update(participation.ParticipationID, updates)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordTransition(repos, updated.ParticipationID, previousStatus, status, actor, reason); err != nil {
+		return nil, err
+	}
+	payload := map[string]interface{}{
+		"participation_id": updated.ParticipationID.String(),
+		"activity_id":      updated.ActivityID.String(),
+		"user_id":          updated.UserID.String(),
+		"from_status":      previousStatus,
+		"to_status":        status,
+		"actor_type":       actor.Type,
+	}
+	if reason != "" {
+		payload["reason"] = reason
 	}
-	if status != "" {
-		updates["status"] = status
+	err = repos.Outbox.Append(ctx, newOutboxEvent("participation", updated.ParticipationID, model.EventParticipationStatusChanged, payload))
+	if err != nil {
+		return nil, err
 	}
+	return updated, nil
+}
+
+func recordTransition(repos repository.Repositories, participationID uuid.UUID, from, to string, actor Actor, reason string) error {
+	transition := &model.ParticipationStatusTransition{
+		ParticipationID: participationID,
+		FromStatus:      from,
+		ToStatus:        to,
+		ActorType:       actor.Type,
+		ActorID:         actor.UserID,
+	}
+	if reason != "" {
+		transition.Reason = &reason
+	}
+	return repos.Participations.RecordTransition(transition)
+}
+
+// UpdateParticipationWithBadgeCreation updates the proof URL and, when
+// status differs from the current one, moves the participation through the
+// state machine. Completing it issues the activity's badge.
+func (s *activityParticipationServiceImpl) UpdateParticipationWithBadgeCreation(ctx context.Context, id uuid.UUID, proofURL *string, status string, actor Actor) (*model.ActivityParticipation, error) {
+	if status != "" && !IsParticipationStatus(status) {
+		return nil, ErrInvalidStatus
+	}
+	if proofURL != nil {
+		if err := checkEvidenceURL(*proofURL); err != nil {
+			return nil, err
+		}
+	}
+	var updatedParticipation *model.ActivityParticipation
+	err := s.uow.Do(ctx, func(repos repository.Repositories) error {
+		// Get the current participation
+		current, err := repos.Participations.FindByID(id)
+		if err != nil {
+			return err
+		}
 
-	// Update the participation
-	updatedParticipation, err := s.repo.Update(id, updates)
+		// Prepare updates
+		updates := make(map[string]interface{})
+		if proofURL != nil {
+			updates["proof_of_participation_url"] = *proofURL
+		}
+
+		if status == "" || status == current.Status {
+			updatedParticipation, err = repos.Participations.Update(id, updates)
+			if err != nil {
+				return err
+			}
+			return repos.Outbox.Append(ctx, participationEvent(updatedParticipation, model.EventParticipationUpdated))
+		}
+
+		updatedParticipation, err = transitionParticipation(ctx, repos, current, status, actor, "", updates)
+		if err != nil {
+			return err
+		}
+
+		// If status is COMPLETED, create a badge in the same transaction
+		if status == model.ParticipationCompleted {
+			return createBadgeForCompletion(ctx, repos, updatedParticipation)
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	return updatedParticipation, nil
+}
 
-	// If status is COMPLETED, create a badge
-	if status == "COMPLETED" {
-		err := s.createBadgeForCompletion(ctx, updatedParticipation)
+// RecordAttendance marks the participant as present at a session. Once they
+// have attended the activity's RequiredSessions, the participation is
+// completed and its badge issued in the same transaction. A registered
+// participant is checked in by their first attendance.
+func (s *activityParticipationServiceImpl) RecordAttendance(ctx context.Context, sessionID, participationID uuid.UUID) (*model.SessionAttendance, *model.ActivityParticipation, error) {
+	var attendance *model.SessionAttendance
+	var participation *model.ActivityParticipation
+	err := s.uow.Do(ctx, func(repos repository.Repositories) error {
+		session, err := repos.Sessions.GetByID(ctx, sessionID)
 		if err != nil {
-			// Log error but don't fail the update
-			// In production, you might want to use proper logging
-			// log.Printf("Failed to create badge for participation %s: %v", id, err)
+			return err
 		}
+		participation, err = repos.Participations.FindByID(participationID)
+		if err != nil {
+			return err
+		}
+		attendance, participation, err = recordAttendance(ctx, repos, session, participation, SystemActor())
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
 	}
+	return attendance, participation, nil
+}
 
-	return updatedParticipation, nil
+// recordAttendance records the participant at the session, checking them in
+// on behalf of actor if they are only registered, and completes the
+// participation once enough sessions have been attended.
+func recordAttendance(ctx context.Context, repos repository.Repositories, session *model.ActivitySession, participation *model.ActivityParticipation, actor Actor) (*model.SessionAttendance, *model.ActivityParticipation, error) {
+	if session.Cancelled {
+		return nil, nil, ErrSessionCancelled
+	}
+	if participation.ActivityID != session.ActivityID {
+		return nil, nil, ErrParticipationNotInSession
+	}
+
+	attendance := &model.SessionAttendance{
+		SessionID:       session.SessionID,
+		ParticipationID: participation.ParticipationID,
+		UserID:          participation.UserID,
+		AttendedAt:      time.Now(),
+	}
+	created, err := repos.Sessions.RecordAttendance(ctx, attendance)
+	if err != nil || !created {
+		return attendance, participation, err
+	}
+
+	if participation.Status == model.ParticipationRegistered {
+		participation, err = transitionParticipation(ctx, repos, participation, model.ParticipationCheckedIn, actor, "attendance", nil)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	activity, err := repos.Activities.FindByID(session.ActivityID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if activity.RequiredSessions == nil || !CanTransition(participation.Status, model.ParticipationCompleted, ActorSystem) {
+		return attendance, participation, nil
+	}
+	attended, err := repos.Sessions.CountAttendance(ctx, participation.ParticipationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if attended < int64(*activity.RequiredSessions) {
+		return attendance, participation, nil
+	}
+
+	participation, err = transitionParticipation(ctx, repos, participation, model.ParticipationCompleted, SystemActor(), "required_sessions_attended", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := createBadgeForCompletion(ctx, repos, participation); err != nil {
+		return nil, nil, err
+	}
+	return attendance, participation, nil
+}
+
+func (s *activityParticipationServiceImpl) ListAttendance(ctx context.Context, sessionID uuid.UUID) ([]model.SessionAttendance, error) {
+	return s.sessionRepo.ListAttendance(ctx, sessionID)
 }
 
-func (s *activityParticipationServiceImpl) createBadgeForCompletion(ctx context.Context, participation *model.ActivityParticipation) error {
+func (s *activityParticipationServiceImpl) ListTransitions(ctx context.Context, id uuid.UUID) ([]model.ParticipationStatusTransition, error) {
+	if _, err := s.repo.FindByID(id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListTransitions(id)
+}
+
+// createBadgeForCompletion issues the activity's badge, and the badges of
+// co-hosts that have one, to the participant.
+func createBadgeForCompletion(ctx context.Context, repos repository.Repositories, participation *model.ActivityParticipation) error {
 	// Get the activity to find the associated badge
-	activity, err := s.activityRepo.FindByID(participation.ActivityID)
+	activity, err := repos.Activities.FindByID(participation.ActivityID)
 	if err != nil {
 		return err
 	}
 
-	// Check if activity has an associated badge
-	if activity.BadgeDefID == nil {
-		// No badge associated with this activity
+	// Cancelled activities never took place, so they award nothing
+	if activity.Status == model.ActivityCancelled {
 		return nil
 	}
 
-	// Check if badge already exists for this user and activity
-	existingBadges, err := s.badgeRepo.ListIssuedBadgesByUser(ctx, participation.UserID)
+	// Accepted co-hosts with a badge of their own issue it alongside the
+	// activity's badge; the others are listed as its co-issuers
+	coHosts, err := repos.CoHosts.ListByActivity(ctx, activity.ActivityID, true)
 	if err != nil {
 		return err
 	}
+	var coIssuers []string
+	for _, host := range coHosts {
+		if host.BadgeDefID == nil {
+			coIssuers = append(coIssuers, host.OrgID.String())
+		}
+	}
+
+	// Check which badges the user already has
+	existingBadges, err := repos.Badges.ListIssuedBadgesByUser(ctx, participation.UserID)
+	if err != nil {
+		return err
+	}
+
+	if activity.BadgeDefID != nil {
+		issuedBadge, err := issueActivityBadge(ctx, repos, participation, existingBadges, *activity.BadgeDefID, activity.OrgID, coIssuers)
+		if err != nil {
+			return err
+		}
+		if issuedBadge != nil {
+			// Update participation with issued badge ID
+			_, err = repos.Participations.Update(participation.ParticipationID, map[string]interface{}{
+				"issued_badge_id": issuedBadge.IssuedBadgeID,
+			})
+			if err != nil {
+				return err
+			}
+			participation.IssuedBadgeID = &issuedBadge.IssuedBadgeID
+		}
+	}
+	for _, host := range coHosts {
+		if host.BadgeDefID == nil {
+			continue
+		}
+		if _, err := issueActivityBadge(ctx, repos, participation, existingBadges, *host.BadgeDefID, host.OrgID, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	// Check if user already has this badge
+// issueActivityBadge issues the badge of orgID for the participation's
+// activity unless the user already has it. It returns nil when nothing was
+// issued.
+func issueActivityBadge(ctx context.Context, repos repository.Repositories, participation *model.ActivityParticipation, existingBadges []model.IssuedBadge, badgeDefID, orgID uuid.UUID, coIssuers []string) (*model.IssuedBadge, error) {
 	for _, badge := range existingBadges {
-		if badge.BadgeDefID == *activity.BadgeDefID && badge.SourceID != nil && *badge.SourceID == participation.ActivityID {
+		if badge.BadgeDefID == badgeDefID && badge.SourceID != nil && *badge.SourceID == participation.ActivityID {
 			// Badge already exists for this activity
-			return nil
+			return nil, nil
 		}
 	}
 
-	// Create new issued badge
 	issuedBadge := &model.IssuedBadge{
 		IssuedBadgeID:    uuid.New(),
-		BadgeDefID:       *activity.BadgeDefID,
+		BadgeDefID:       badgeDefID,
 		UserID:           participation.UserID,
-		OrgID:            activity.OrgID,
+		OrgID:            orgID,
 		VerificationCode: generateVerificationCode(),
 		SourceType:       stringPtr("activity"),
 		SourceID:         &participation.ActivityID,
 		Status:           "issued",
 	}
+	if len(coIssuers) > 0 {
+		issuedBadge.AdditionalData = map[string]interface{}{"co_issuers": coIssuers}
+	}
+	if err := repos.Badges.CreateIssuedBadge(ctx, issuedBadge); err != nil {
+		return nil, err
+	}
 
-	// Create the issued badge
-	err = s.badgeRepo.CreateIssuedBadge(ctx, issuedBadge)
+	err := repos.Outbox.Append(ctx, newOutboxEvent("issued_badge", issuedBadge.IssuedBadgeID, model.EventBadgeIssued, map[string]interface{}{
+		"issued_badge_id":  issuedBadge.IssuedBadgeID.String(),
+		"badge_def_id":     issuedBadge.BadgeDefID.String(),
+		"user_id":          issuedBadge.UserID.String(),
+		"org_id":           issuedBadge.OrgID.String(),
+		"activity_id":      participation.ActivityID.String(),
+		"participation_id": participation.ParticipationID.String(),
+	}))
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return issuedBadge, nil
+}
 
-	// Update participation with issued badge ID
-	_, err = s.repo.Update(participation.ParticipationID, map[string]interface{}{
-		"issued_badge_id": issuedBadge.IssuedBadgeID,
+func participationEvent(participation *model.ActivityParticipation, eventType string) *model.OutboxEvent {
+	return newOutboxEvent("participation", participation.ParticipationID, eventType, map[string]interface{}{
+		"participation_id": participation.ParticipationID.String(),
+		"activity_id":      participation.ActivityID.String(),
+		"user_id":          participation.UserID.String(),
+		"status":           participation.Status,
 	})
-
-	return err
 }
 
 func generateVerificationCode() string {
@@ -2,32 +2,133 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"ping-badge-be/internal/geo"
+	"ping-badge-be/internal/listquery"
 	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
+	"ping-badge-be/internal/recurrence"
 	"ping-badge-be/internal/repository"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type ActivityService interface {
 	CreateActivity(ctx context.Context, activity *model.Activity) error
 	GetActivity(ctx context.Context, id uuid.UUID) (*model.Activity, error)
-	ListActivities(ctx context.Context, orgID *uuid.UUID, offset, limit int) ([]model.Activity, error)
-	ListActivitiesByUser(ctx context.Context, userID uuid.UUID, offset, limit int) ([]model.Activity, error)
-	UpdateActivity(ctx context.Context, id uuid.UUID, updates map[string]interface{}) (*model.Activity, error)
+	ListActivities(ctx context.Context, filter repository.ActivityFilter, page pagination.Params) ([]model.Activity, int64, error)
+	ListActivitiesByUser(ctx context.Context, userID uuid.UUID, criteria listquery.Query, page pagination.Params) ([]model.Activity, int64, error)
+	UpdateActivity(ctx context.Context, id uuid.UUID, patch ActivityPatch) (*model.Activity, error)
 	DeleteActivity(ctx context.Context, id uuid.UUID) error
+	CancelActivity(ctx context.Context, id uuid.UUID, reason string, withdraw bool, actor Actor) (*model.Activity, error)
+	RescheduleActivity(ctx context.Context, id uuid.UUID, patch ActivityPatch, reason string) (*model.Activity, error)
+	ArchiveActivity(ctx context.Context, id uuid.UUID) (*model.Activity, error)
+	ListSessions(ctx context.Context, activityID uuid.UUID) ([]model.ActivitySession, error)
+}
+
+// maxSessions caps how many sessions a recurrence rule may generate.
+const maxSessions = 500
+
+var (
+	ErrInvalidTimezone         = errors.New("invalid timezone")
+	ErrEndBeforeStart          = errors.New("end_date must be after start_date")
+	ErrBadgeNotFound           = errors.New("badge not found")
+	ErrBadgeNotInOrganization  = errors.New("badge does not belong to the activity's organization")
+	ErrBadgeInactive           = errors.New("badge is not active")
+	ErrInvalidRecurrence       = errors.New("invalid recurrence")
+	ErrInvalidRequiredCount    = errors.New("required_sessions must be between 1 and the number of sessions")
+	ErrInvalidCapacity         = errors.New("capacity must be at least 1")
+	ErrInvalidRegistration     = errors.New("registration_closes_at must be after registration_opens_at")
+	ErrInvalidCoordinates      = errors.New("latitude and longitude must be set together and within range")
+	ErrInvalidGeofence         = errors.New("geofence_radius_meters must be positive and needs coordinates")
+	ErrInvalidGeofencePolicy   = errors.New("geofence_policy must be reject or flag")
+	ErrInvalidCategory         = fmt.Errorf("category must be at most %d characters", maxCategoryLength)
+	ErrInvalidTags             = fmt.Errorf("at most %d tags of up to %d characters each are allowed", maxTags, maxTagLength)
+	ErrActivityCancelled       = errors.New("activity is cancelled")
+	ErrActivityArchived        = errors.New("activity is archived")
+	ErrActivityNotEnded        = errors.New("only cancelled activities or activities that have ended can be archived")
+	ErrActivityHasCompletions  = errors.New("activity has completed participations; archive it instead")
+	ErrActivityHasParticipants = errors.New("activity still has participants; cancel it before deleting it")
+	ErrNoScheduleChange        = errors.New("reschedule needs a new start_date, end_date, timezone or recurrence")
+)
+
+// Limits on activity classification.
+const (
+	maxCategoryLength = 50
+	maxTags           = 10
+	maxTagLength      = 30
+)
+
+// ActivityPatch is a partial activity update. Nil fields are left unchanged;
+// ClearBadge unlinks the badge, an empty RecurrenceRule removes recurrence,
+// a zero RequiredSessions, Capacity or GeofenceRadiusMeters removes the limit,
+// a zero registration time removes that bound of the window and an empty
+// Category removes the category.
+type ActivityPatch struct {
+	ActivityName         *string
+	Description          *string
+	Location             *string
+	StartDate            *time.Time
+	EndDate              *time.Time
+	Timezone             *string
+	BadgeDefID           *uuid.UUID
+	ClearBadge           bool
+	RecurrenceRule       *string
+	RecurrenceExceptions *[]time.Time
+	RequiredSessions     *int
+	Capacity             *int
+	RegistrationOpensAt  *time.Time
+	RegistrationClosesAt *time.Time
+	Latitude             *float64
+	Longitude            *float64
+	GeofenceRadiusMeters *int
+	GeofencePolicy       *string
+	Category             *string
+	Tags                 *[]string
 }
 
 type activityServiceImpl struct {
-	repo repository.ActivityRepository
+	repo        repository.ActivityRepository
+	badgeRepo   repository.BadgeRepository
+	sessionRepo repository.ActivitySessionRepository
+	uow         repository.UnitOfWork
 }
 
-func NewActivityService(repo repository.ActivityRepository) ActivityService {
-	return &activityServiceImpl{repo: repo}
+func NewActivityService(repo repository.ActivityRepository, badgeRepo repository.BadgeRepository, sessionRepo repository.ActivitySessionRepository, uow repository.UnitOfWork) ActivityService {
+	return &activityServiceImpl{repo: repo, badgeRepo: badgeRepo, sessionRepo: sessionRepo, uow: uow}
 }
 
 func (s *activityServiceImpl) CreateActivity(ctx context.Context, activity *model.Activity) error {
 	// Add business logic, validation, authorization here
-	return s.repo.Create(activity)
+	if activity.Timezone == "" {
+		activity.Timezone = "UTC"
+	}
+	if activity.GeofencePolicy == "" {
+		activity.GeofencePolicy = model.GeofenceReject
+	}
+	activity.Status = model.ActivityActive
+	if err := normalizeClassification(activity); err != nil {
+		return err
+	}
+	if err := s.validate(ctx, activity); err != nil {
+		return err
+	}
+	sessions, err := buildSessions(activity)
+	if err != nil {
+		return err
+	}
+	return s.uow.Do(ctx, func(repos repository.Repositories) error {
+		if err := repos.Activities.Create(activity); err != nil {
+			return err
+		}
+		return repos.Sessions.Sync(ctx, activity.ActivityID, sessions)
+	})
 }
 
 func (s *activityServiceImpl) GetActivity(ctx context.Context, id uuid.UUID) (*model.Activity, error) {
@@ -35,22 +136,565 @@ func (s *activityServiceImpl) GetActivity(ctx context.Context, id uuid.UUID) (*m
 	return s.repo.FindByID(id)
 }
 
-func (s *activityServiceImpl) ListActivities(ctx context.Context, orgID *uuid.UUID, offset, limit int) ([]model.Activity, error) {
+func (s *activityServiceImpl) ListActivities(ctx context.Context, filter repository.ActivityFilter, page pagination.Params) ([]model.Activity, int64, error) {
 	// Add business logic, validation, authorization here
-	return s.repo.FindAll(orgID, offset, limit)
+	total, err := s.repo.Count(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	activities, err := s.repo.FindAll(filter, page)
+	if err != nil {
+		return nil, 0, err
+	}
+	return activities, total, nil
 }
 
-func (s *activityServiceImpl) ListActivitiesByUser(ctx context.Context, userID uuid.UUID, offset, limit int) ([]model.Activity, error) {
+func (s *activityServiceImpl) ListActivitiesByUser(ctx context.Context, userID uuid.UUID, criteria listquery.Query, page pagination.Params) ([]model.Activity, int64, error) {
 	// Add business logic, validation, authorization here
-	return s.repo.FindByUser(userID, offset, limit)
+	total, err := s.repo.CountByUser(userID, criteria)
+	if err != nil {
+		return nil, 0, err
+	}
+	activities, err := s.repo.FindByUser(userID, criteria, page)
+	if err != nil {
+		return nil, 0, err
+	}
+	return activities, total, nil
 }
 
-func (s *activityServiceImpl) UpdateActivity(ctx context.Context, id uuid.UUID, updates map[string]interface{}) (*model.Activity, error) {
-	// Add business logic, validation, authorization here
-	return s.repo.Update(id, updates)
+// UpdateActivity applies patch and validates the resulting activity as a
+// whole, so a new end date is checked against the stored start date.
+func (s *activityServiceImpl) UpdateActivity(ctx context.Context, id uuid.UUID, patch ActivityPatch) (*model.Activity, error) {
+	return s.update(ctx, id, patch, nil)
+}
+
+// update applies patch and, when set, calls after in the same transaction
+// with the updated activity.
+func (s *activityServiceImpl) update(ctx context.Context, id uuid.UUID, patch ActivityPatch, after func(repos repository.Repositories, activity *model.Activity) error) (*model.Activity, error) {
+	activity, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkActivityOpen(activity); err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if patch.ActivityName != nil {
+		activity.ActivityName = *patch.ActivityName
+		updates["activity_name"] = *patch.ActivityName
+	}
+	if patch.Description != nil {
+		activity.Description = patch.Description
+		updates["description"] = *patch.Description
+	}
+	if patch.Location != nil {
+		activity.Location = patch.Location
+		updates["location"] = *patch.Location
+	}
+	if patch.StartDate != nil {
+		activity.StartDate = patch.StartDate
+		updates["start_date"] = *patch.StartDate
+	}
+	if patch.EndDate != nil {
+		activity.EndDate = patch.EndDate
+		updates["end_date"] = *patch.EndDate
+	}
+	if patch.Timezone != nil {
+		activity.Timezone = *patch.Timezone
+		updates["timezone"] = *patch.Timezone
+	}
+	if patch.ClearBadge {
+		activity.BadgeDefID = nil
+		updates["badge_def_id"] = nil
+	} else if patch.BadgeDefID != nil {
+		activity.BadgeDefID = patch.BadgeDefID
+		updates["badge_def_id"] = *patch.BadgeDefID
+	}
+	if patch.RecurrenceRule != nil {
+		if *patch.RecurrenceRule == "" {
+			activity.RecurrenceRule = nil
+			updates["recurrence_rule"] = nil
+		} else {
+			activity.RecurrenceRule = patch.RecurrenceRule
+			updates["recurrence_rule"] = *patch.RecurrenceRule
+		}
+	}
+	if patch.RecurrenceExceptions != nil {
+		activity.RecurrenceExceptions = *patch.RecurrenceExceptions
+		exceptions, err := json.Marshal(*patch.RecurrenceExceptions)
+		if err != nil {
+			return nil, err
+		}
+		updates["recurrence_exceptions"] = string(exceptions)
+	}
+	if patch.RequiredSessions != nil {
+		if *patch.RequiredSessions == 0 {
+			activity.RequiredSessions = nil
+			updates["required_sessions"] = nil
+		} else {
+			activity.RequiredSessions = patch.RequiredSessions
+			updates["required_sessions"] = *patch.RequiredSessions
+		}
+	}
+	if patch.Capacity != nil {
+		if *patch.Capacity == 0 {
+			activity.Capacity = nil
+			updates["capacity"] = nil
+		} else {
+			activity.Capacity = patch.Capacity
+			updates["capacity"] = *patch.Capacity
+		}
+	}
+	if patch.RegistrationOpensAt != nil {
+		activity.RegistrationOpensAt = nilIfZero(patch.RegistrationOpensAt)
+		updates["registration_opens_at"] = activity.RegistrationOpensAt
+	}
+	if patch.RegistrationClosesAt != nil {
+		activity.RegistrationClosesAt = nilIfZero(patch.RegistrationClosesAt)
+		updates["registration_closes_at"] = activity.RegistrationClosesAt
+	}
+	if patch.Latitude != nil {
+		activity.Latitude = patch.Latitude
+		updates["latitude"] = *patch.Latitude
+	}
+	if patch.Longitude != nil {
+		activity.Longitude = patch.Longitude
+		updates["longitude"] = *patch.Longitude
+	}
+	if patch.GeofenceRadiusMeters != nil {
+		if *patch.GeofenceRadiusMeters == 0 {
+			activity.GeofenceRadiusMeters = nil
+			updates["geofence_radius_meters"] = nil
+		} else {
+			activity.GeofenceRadiusMeters = patch.GeofenceRadiusMeters
+			updates["geofence_radius_meters"] = *patch.GeofenceRadiusMeters
+		}
+	}
+	if patch.GeofencePolicy != nil {
+		activity.GeofencePolicy = *patch.GeofencePolicy
+		updates["geofence_policy"] = *patch.GeofencePolicy
+	}
+	if patch.Category != nil {
+		activity.Category = patch.Category
+	}
+	if patch.Tags != nil {
+		activity.Tags = *patch.Tags
+	}
+	if patch.Category != nil || patch.Tags != nil {
+		if err := normalizeClassification(activity); err != nil {
+			return nil, err
+		}
+		if patch.Category != nil {
+			updates["category"] = activity.Category
+		}
+		if patch.Tags != nil {
+			tags, err := json.Marshal(activity.Tags)
+			if err != nil {
+				return nil, err
+			}
+			updates["tags"] = string(tags)
+		}
+	}
+	if len(updates) == 0 {
+		return activity, nil
+	}
+	if patch.ActivityName != nil || patch.Description != nil || patch.Location != nil || scheduleChanged(patch) {
+		// Calendar apps only replace their copy of the event when the
+		// sequence grows.
+		activity.Sequence++
+		updates["sequence"] = activity.Sequence
+	}
+
+	if err := s.validate(ctx, activity); err != nil {
+		return nil, err
+	}
+	sessions, err := buildSessions(activity)
+	if err != nil {
+		return nil, err
+	}
+	var updated *model.Activity
+	err = s.uow.Do(ctx, func(repos repository.Repositories) error {
+		var err error
+		updated, err = repos.Activities.Update(id, updates)
+		if err != nil {
+			return err
+		}
+		updated.RecurrenceExceptions = activity.RecurrenceExceptions
+		if patch.Capacity != nil {
+			// A larger or removed limit frees places for the waitlist.
+			if err := promoteFromWaitlist(ctx, repos, updated); err != nil {
+				return err
+			}
+		}
+		if scheduleChanged(patch) {
+			if err := repos.Sessions.Sync(ctx, id, sessions); err != nil {
+				return err
+			}
+		}
+		if after == nil {
+			return nil
+		}
+		return after(repos, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
 }
 
+// scheduleChanged reports whether the patch moves the activity's sessions.
+func scheduleChanged(patch ActivityPatch) bool {
+	return patch.StartDate != nil || patch.EndDate != nil || patch.Timezone != nil ||
+		patch.RecurrenceRule != nil || patch.RecurrenceExceptions != nil
+}
+
+// DeleteActivity soft-deletes the activity. Its sequence is bumped first so
+// calendar feeds publish the cancellation as a new revision. Activities with
+// completed participations back issued badges and can only be archived; an
+// active activity that anyone still takes part in must be cancelled first, so
+// its participants are notified.
 func (s *activityServiceImpl) DeleteActivity(ctx context.Context, id uuid.UUID) error {
-	// Add business logic, validation, authorization here
-	return s.repo.Delete(id)
+	return s.uow.Do(ctx, func(repos repository.Repositories) error {
+		activity, err := repos.Activities.FindByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		completed, err := repos.Participations.CountByStatus(id, model.ParticipationCompleted)
+		if err != nil {
+			return err
+		}
+		if completed > 0 {
+			return ErrActivityHasCompletions
+		}
+		if activity.Status == model.ActivityActive {
+			participations, err := repos.Participations.FindByActivity(id)
+			if err != nil {
+				return err
+			}
+			for _, p := range participations {
+				if p.Status != model.ParticipationWithdrawn {
+					return ErrActivityHasParticipants
+				}
+			}
+		}
+		if _, err := repos.Activities.Update(id, map[string]interface{}{"sequence": activity.Sequence + 1}); err != nil {
+			return err
+		}
+		return repos.Activities.Delete(id)
+	})
+}
+
+// CancelActivity marks the activity cancelled, cancels its remaining sessions
+// and notifies everyone still taking part. With withdraw set, participants
+// who have not checked in yet are withdrawn on behalf of actor; the rest keep
+// their status so their history stays intact. No badges are issued for a
+// cancelled activity.
+func (s *activityServiceImpl) CancelActivity(ctx context.Context, id uuid.UUID, reason string, withdraw bool, actor Actor) (*model.Activity, error) {
+	var cancelled *model.Activity
+	err := s.uow.Do(ctx, func(repos repository.Repositories) error {
+		activity, err := repos.Activities.FindByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if err := checkActivityOpen(activity); err != nil {
+			return err
+		}
+		now := time.Now()
+		updates := map[string]interface{}{
+			"status":       model.ActivityCancelled,
+			"cancelled_at": now,
+			"sequence":     activity.Sequence + 1,
+		}
+		if reason != "" {
+			updates["cancellation_reason"] = reason
+		}
+		cancelled, err = repos.Activities.Update(id, updates)
+		if err != nil {
+			return err
+		}
+		if err := repos.Sessions.CancelFrom(ctx, id, now); err != nil {
+			return err
+		}
+
+		participations, err := repos.Participations.FindByActivity(id)
+		if err != nil {
+			return err
+		}
+		for i := range participations {
+			p := &participations[i]
+			if p.Status == model.ParticipationWithdrawn {
+				continue
+			}
+			if withdraw && CanTransition(p.Status, model.ParticipationWithdrawn, actor.Type) {
+				_, err := transitionParticipation(ctx, repos, p, model.ParticipationWithdrawn, actor, "activity_cancelled", map[string]interface{}{
+					"waitlist_position": nil,
+				})
+				if err != nil {
+					return err
+				}
+			}
+			if err := repos.Outbox.Append(ctx, activityChangedEvent(cancelled, p, model.EventActivityCancelled, reason)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cancelled, nil
+}
+
+// RescheduleActivity moves an active activity to a new schedule and notifies
+// everyone still taking part. Only the schedule fields of patch are applied.
+func (s *activityServiceImpl) RescheduleActivity(ctx context.Context, id uuid.UUID, patch ActivityPatch, reason string) (*model.Activity, error) {
+	schedule := ActivityPatch{
+		StartDate:            patch.StartDate,
+		EndDate:              patch.EndDate,
+		Timezone:             patch.Timezone,
+		RecurrenceRule:       patch.RecurrenceRule,
+		RecurrenceExceptions: patch.RecurrenceExceptions,
+	}
+	if !scheduleChanged(schedule) {
+		return nil, ErrNoScheduleChange
+	}
+	activity, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkActivityOpen(activity); err != nil {
+		return nil, err
+	}
+	return s.update(ctx, id, schedule, func(repos repository.Repositories, updated *model.Activity) error {
+		// Participants must be reminded again for the new start.
+		if _, err := repos.Activities.Update(id, map[string]interface{}{"reminder_sent_at": nil}); err != nil {
+			return err
+		}
+		participations, err := repos.Participations.FindByActivity(id)
+		if err != nil {
+			return err
+		}
+		for i := range participations {
+			if participations[i].Status == model.ParticipationWithdrawn {
+				continue
+			}
+			if err := repos.Outbox.Append(ctx, activityChangedEvent(updated, &participations[i], model.EventActivityRescheduled, reason)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ArchiveActivity hides a past or cancelled activity from listings while
+// keeping its participations and badges.
+func (s *activityServiceImpl) ArchiveActivity(ctx context.Context, id uuid.UUID) (*model.Activity, error) {
+	activity, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if activity.Status == model.ActivityArchived {
+		return activity, nil
+	}
+	if activity.Status != model.ActivityCancelled {
+		sessions, err := s.sessionRepo.ListByActivity(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if !activityEnded(activity, sessions, time.Now()) {
+			return nil, ErrActivityNotEnded
+		}
+	}
+	return s.repo.Update(id, map[string]interface{}{"status": model.ActivityArchived})
+}
+
+// activityEnded reports whether all the activity's sessions that were not
+// cancelled have ended. Without such sessions it goes by the end date, or the
+// start date when there is none; an undated activity never ends.
+func activityEnded(activity *model.Activity, sessions []model.ActivitySession, now time.Time) bool {
+	scheduled := false
+	for _, session := range sessions {
+		if session.Cancelled {
+			continue
+		}
+		if !session.EndsAt.Before(now) {
+			return false
+		}
+		scheduled = true
+	}
+	if scheduled {
+		return true
+	}
+	end := activity.EndDate
+	if end == nil {
+		end = activity.StartDate
+	}
+	return end != nil && end.Before(now)
+}
+
+// checkActivityOpen rejects changes to cancelled and archived activities.
+func checkActivityOpen(activity *model.Activity) error {
+	switch activity.Status {
+	case model.ActivityCancelled:
+		return ErrActivityCancelled
+	case model.ActivityArchived:
+		return ErrActivityArchived
+	}
+	return nil
+}
+
+// activityChangedEvent tells one participant that the activity was
+// cancelled or rescheduled.
+func activityChangedEvent(activity *model.Activity, participation *model.ActivityParticipation, eventType, reason string) *model.OutboxEvent {
+	payload := map[string]interface{}{
+		"activity_id":      activity.ActivityID.String(),
+		"participation_id": participation.ParticipationID.String(),
+		"user_id":          participation.UserID.String(),
+	}
+	if activity.StartDate != nil {
+		payload["start_date"] = activity.StartDate.Format(time.RFC3339)
+	}
+	if reason != "" {
+		payload["reason"] = reason
+	}
+	return newOutboxEvent("activity", activity.ActivityID, eventType, payload)
+}
+
+func (s *activityServiceImpl) ListSessions(ctx context.Context, activityID uuid.UUID) ([]model.ActivitySession, error) {
+	return s.sessionRepo.ListByActivity(ctx, activityID)
+}
+
+func (s *activityServiceImpl) validate(ctx context.Context, activity *model.Activity) error {
+	if _, err := time.LoadLocation(activity.Timezone); err != nil {
+		return ErrInvalidTimezone
+	}
+	if activity.StartDate != nil && activity.EndDate != nil && !activity.EndDate.After(*activity.StartDate) {
+		return ErrEndBeforeStart
+	}
+	if activity.Capacity != nil && *activity.Capacity < 1 {
+		return ErrInvalidCapacity
+	}
+	if activity.RegistrationOpensAt != nil && activity.RegistrationClosesAt != nil &&
+		!activity.RegistrationClosesAt.After(*activity.RegistrationOpensAt) {
+		return ErrInvalidRegistration
+	}
+	if (activity.Latitude == nil) != (activity.Longitude == nil) {
+		return ErrInvalidCoordinates
+	}
+	if activity.Latitude != nil && !(geo.Point{Latitude: *activity.Latitude, Longitude: *activity.Longitude}).Valid() {
+		return ErrInvalidCoordinates
+	}
+	if activity.GeofenceRadiusMeters != nil && (*activity.GeofenceRadiusMeters < 1 || activity.Latitude == nil) {
+		return ErrInvalidGeofence
+	}
+	if activity.GeofencePolicy != model.GeofenceReject && activity.GeofencePolicy != model.GeofenceFlag {
+		return ErrInvalidGeofencePolicy
+	}
+	if activity.BadgeDefID != nil {
+		badge, err := s.badgeRepo.GetByID(ctx, *activity.BadgeDefID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrBadgeNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if badge.OrgID != activity.OrgID {
+			return ErrBadgeNotInOrganization
+		}
+		if !badge.IsActive {
+			return ErrBadgeInactive
+		}
+	}
+	return nil
+}
+
+// buildSessions expands the activity's schedule into sessions: one per
+// occurrence of its recurrence rule, or a single session otherwise.
+func buildSessions(activity *model.Activity) ([]model.ActivitySession, error) {
+	if activity.StartDate == nil {
+		if activity.RecurrenceRule != nil {
+			return nil, fmt.Errorf("%w: start_date is required for recurring activities", ErrInvalidRecurrence)
+		}
+		if activity.RequiredSessions != nil {
+			return nil, ErrInvalidRequiredCount
+		}
+		return nil, nil
+	}
+	duration := time.Duration(0)
+	if activity.EndDate != nil {
+		duration = activity.EndDate.Sub(*activity.StartDate)
+	}
+
+	starts := []time.Time{*activity.StartDate}
+	if activity.RecurrenceRule != nil {
+		loc, err := time.LoadLocation(activity.Timezone)
+		if err != nil {
+			return nil, ErrInvalidTimezone
+		}
+		rule, err := recurrence.Parse(*activity.RecurrenceRule, loc)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRecurrence, err)
+		}
+		if !rule.Bounded() {
+			return nil, fmt.Errorf("%w: rule must end with COUNT or UNTIL", ErrInvalidRecurrence)
+		}
+		starts = rule.Occurrences(activity.StartDate.In(loc), activity.RecurrenceExceptions, maxSessions+1)
+		if len(starts) > maxSessions {
+			return nil, fmt.Errorf("%w: rule generates more than %d sessions", ErrInvalidRecurrence, maxSessions)
+		}
+		if len(starts) == 0 {
+			return nil, fmt.Errorf("%w: rule generates no sessions", ErrInvalidRecurrence)
+		}
+	}
+	if activity.RequiredSessions != nil && (*activity.RequiredSessions < 1 || *activity.RequiredSessions > len(starts)) {
+		return nil, ErrInvalidRequiredCount
+	}
+
+	sessions := make([]model.ActivitySession, 0, len(starts))
+	for _, start := range starts {
+		start = start.UTC()
+		sessions = append(sessions, model.ActivitySession{StartsAt: start, EndsAt: start.Add(duration)})
+	}
+	return sessions, nil
+}
+
+// normalizeClassification trims the category, dropping it when empty, and
+// lower-cases, trims and de-duplicates the tags.
+func normalizeClassification(activity *model.Activity) error {
+	if activity.Category != nil {
+		category := strings.TrimSpace(*activity.Category)
+		if category == "" {
+			activity.Category = nil
+		} else if utf8.RuneCountInString(category) > maxCategoryLength {
+			return ErrInvalidCategory
+		} else {
+			activity.Category = &category
+		}
+	}
+	tags := make([]string, 0, len(activity.Tags))
+	seen := make(map[string]bool)
+	for _, tag := range activity.Tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			return ErrInvalidTags
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	if len(tags) > maxTags {
+		return ErrInvalidTags
+	}
+	activity.Tags = tags
+	return nil
+}
+
+func nilIfZero(t *time.Time) *time.Time {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return t
 }
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"ping-badge-be/internal/model"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeClassification(t *testing.T) {
+	blank := "  "
+	activity := &model.Activity{Category: &blank, Tags: []string{" Outdoor", "outdoor", "", "Beach "}}
+	require.NoError(t, normalizeClassification(activity))
+	assert.Nil(t, activity.Category)
+	assert.Equal(t, []string{"outdoor", "beach"}, activity.Tags)
+
+	activity.Tags = []string{strings.Repeat("x", maxTagLength+1)}
+	assert.ErrorIs(t, normalizeClassification(activity), ErrInvalidTags)
+
+	activity.Tags = make([]string, 0, maxTags+1)
+	for i := 0; i <= maxTags; i++ {
+		activity.Tags = append(activity.Tags, strings.Repeat("t", i+1))
+	}
+	assert.ErrorIs(t, normalizeClassification(activity), ErrInvalidTags)
+}
+
+func TestActivityEnded(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	assert.False(t, activityEnded(&model.Activity{}, nil, now))
+	assert.True(t, activityEnded(&model.Activity{StartDate: &past}, nil, now))
+	assert.False(t, activityEnded(&model.Activity{StartDate: &past, EndDate: &future}, nil, now))
+
+	sessions := []model.ActivitySession{{EndsAt: past}, {EndsAt: future, Cancelled: true}}
+	assert.True(t, activityEnded(&model.Activity{StartDate: &past, EndDate: &future}, sessions, now))
+	sessions = append(sessions, model.ActivitySession{EndsAt: future})
+	assert.False(t, activityEnded(&model.Activity{StartDate: &past}, sessions, now))
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/recurrence"
+	"ping-badge-be/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ActivityTemplateService interface {
+	SaveAsTemplate(ctx context.Context, activityID uuid.UUID, name string, createdBy uuid.UUID) (*model.ActivityTemplate, error)
+	GetTemplate(ctx context.Context, id uuid.UUID) (*model.ActivityTemplate, error)
+	ListTemplates(ctx context.Context, orgID uuid.UUID) ([]model.ActivityTemplate, error)
+	DeleteTemplate(ctx context.Context, id uuid.UUID) error
+	CreateFromTemplate(ctx context.Context, templateID uuid.UUID, schedule NewSchedule) (*model.Activity, error)
+	CloneActivity(ctx context.Context, activityID uuid.UUID, schedule NewSchedule) (*model.Activity, error)
+}
+
+var ErrTemplateNameRequired = errors.New("template_name is required")
+
+// NewSchedule places an activity created from a template or a clone. EndDate
+// defaults to StartDate plus the source's length; a non-empty ActivityName
+// replaces the source's name.
+type NewSchedule struct {
+	StartDate    time.Time
+	EndDate      *time.Time
+	ActivityName string
+}
+
+type activityTemplateServiceImpl struct {
+	repo            repository.ActivityTemplateRepository
+	activityRepo    repository.ActivityRepository
+	activityService ActivityService
+}
+
+func NewActivityTemplateService(repo repository.ActivityTemplateRepository, activityRepo repository.ActivityRepository, activityService ActivityService) ActivityTemplateService {
+	return &activityTemplateServiceImpl{repo: repo, activityRepo: activityRepo, activityService: activityService}
+}
+
+// SaveAsTemplate stores the activity's settings, without its dates, as a
+// template of its organization. A recurrence rule ending with UNTIL is stored
+// with the COUNT of sessions it produced instead, so activities created from
+// the template get a series of the same length.
+func (s *activityTemplateServiceImpl) SaveAsTemplate(ctx context.Context, activityID uuid.UUID, name string, createdBy uuid.UUID) (*model.ActivityTemplate, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrTemplateNameRequired
+	}
+	activity, err := s.activityRepo.FindByID(activityID)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := templateRule(activity)
+	if err != nil {
+		return nil, err
+	}
+	template := &model.ActivityTemplate{
+		TemplateID:           uuid.New(),
+		OrgID:                activity.OrgID,
+		TemplateName:         name,
+		ActivityName:         activity.ActivityName,
+		Description:          activity.Description,
+		Location:             activity.Location,
+		Timezone:             activity.Timezone,
+		BadgeDefID:           activity.BadgeDefID,
+		Category:             activity.Category,
+		Tags:                 activity.Tags,
+		RecurrenceRule:       rule,
+		RequiredSessions:     activity.RequiredSessions,
+		Capacity:             activity.Capacity,
+		Latitude:             activity.Latitude,
+		Longitude:            activity.Longitude,
+		GeofenceRadiusMeters: activity.GeofenceRadiusMeters,
+		GeofencePolicy:       activity.GeofencePolicy,
+		CreatedBy:            &createdBy,
+	}
+	if activity.StartDate != nil && activity.EndDate != nil {
+		minutes := int(activity.EndDate.Sub(*activity.StartDate) / time.Minute)
+		template.DurationMinutes = &minutes
+	}
+	if err := s.repo.Create(ctx, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (s *activityTemplateServiceImpl) GetTemplate(ctx context.Context, id uuid.UUID) (*model.ActivityTemplate, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *activityTemplateServiceImpl) ListTemplates(ctx context.Context, orgID uuid.UUID) ([]model.ActivityTemplate, error) {
+	return s.repo.ListByOrg(ctx, orgID)
+}
+
+func (s *activityTemplateServiceImpl) DeleteTemplate(ctx context.Context, id uuid.UUID) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// CreateFromTemplate creates an activity with the template's settings at the
+// given schedule.
+func (s *activityTemplateServiceImpl) CreateFromTemplate(ctx context.Context, templateID uuid.UUID, schedule NewSchedule) (*model.Activity, error) {
+	template, err := s.repo.GetByID(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	start := schedule.StartDate.UTC()
+	activity := &model.Activity{
+		ActivityID:           uuid.New(),
+		OrgID:                template.OrgID,
+		ActivityName:         template.ActivityName,
+		Description:          template.Description,
+		Location:             template.Location,
+		StartDate:            &start,
+		EndDate:              schedule.EndDate,
+		Timezone:             template.Timezone,
+		BadgeDefID:           template.BadgeDefID,
+		Category:             template.Category,
+		Tags:                 template.Tags,
+		RecurrenceRule:       template.RecurrenceRule,
+		RequiredSessions:     template.RequiredSessions,
+		Capacity:             template.Capacity,
+		Latitude:             template.Latitude,
+		Longitude:            template.Longitude,
+		GeofenceRadiusMeters: template.GeofenceRadiusMeters,
+		GeofencePolicy:       template.GeofencePolicy,
+	}
+	if activity.EndDate == nil && template.DurationMinutes != nil {
+		end := start.Add(time.Duration(*template.DurationMinutes) * time.Minute)
+		activity.EndDate = &end
+	}
+	if schedule.ActivityName != "" {
+		activity.ActivityName = schedule.ActivityName
+	}
+	if err := s.activityService.CreateActivity(ctx, activity); err != nil {
+		return nil, err
+	}
+	return activity, nil
+}
+
+// CloneActivity copies the activity to a new start date. Every other date of
+// the source (end, registration window, recurrence exceptions and end) moves
+// by the same amount. Participations, check-in secrets and cancellation are not
+// copied.
+func (s *activityTemplateServiceImpl) CloneActivity(ctx context.Context, activityID uuid.UUID, schedule NewSchedule) (*model.Activity, error) {
+	source, err := s.activityRepo.FindByID(activityID)
+	if err != nil {
+		return nil, err
+	}
+	start := schedule.StartDate.UTC()
+	var shift time.Duration
+	if source.StartDate != nil {
+		shift = start.Sub(*source.StartDate)
+	}
+	rule, err := clonedRule(source, start)
+	if err != nil {
+		return nil, err
+	}
+	clone := &model.Activity{
+		ActivityID:           uuid.New(),
+		OrgID:                source.OrgID,
+		ActivityName:         source.ActivityName,
+		Description:          source.Description,
+		Location:             source.Location,
+		StartDate:            &start,
+		EndDate:              schedule.EndDate,
+		Timezone:             source.Timezone,
+		BadgeDefID:           source.BadgeDefID,
+		Category:             source.Category,
+		Tags:                 source.Tags,
+		RecurrenceRule:       rule,
+		RequiredSessions:     source.RequiredSessions,
+		Capacity:             source.Capacity,
+		RegistrationOpensAt:  shiftTime(source.RegistrationOpensAt, shift),
+		RegistrationClosesAt: shiftTime(source.RegistrationClosesAt, shift),
+		Latitude:             source.Latitude,
+		Longitude:            source.Longitude,
+		GeofenceRadiusMeters: source.GeofenceRadiusMeters,
+		GeofencePolicy:       source.GeofencePolicy,
+	}
+	if clone.EndDate == nil && source.StartDate != nil {
+		clone.EndDate = shiftTime(source.EndDate, shift)
+	}
+	for _, exception := range source.RecurrenceExceptions {
+		clone.RecurrenceExceptions = append(clone.RecurrenceExceptions, exception.Add(shift))
+	}
+	if schedule.ActivityName != "" {
+		clone.ActivityName = schedule.ActivityName
+	}
+	if err := s.activityService.CreateActivity(ctx, clone); err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
+
+// templateRule is the activity's recurrence rule with UNTIL replaced by the
+// number of sessions it allows.
+func templateRule(activity *model.Activity) (*string, error) {
+	if activity.RecurrenceRule == nil || activity.StartDate == nil {
+		return activity.RecurrenceRule, nil
+	}
+	loc, err := time.LoadLocation(activity.Timezone)
+	if err != nil {
+		return nil, ErrInvalidTimezone
+	}
+	rule, err := recurrence.UntilToCount(*activity.RecurrenceRule, *activity.StartDate, loc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRecurrence, err)
+	}
+	return &rule, nil
+}
+
+// clonedRule is the source's recurrence rule with UNTIL moved as far as the
+// clone's start is from the source's.
+func clonedRule(source *model.Activity, start time.Time) (*string, error) {
+	if source.RecurrenceRule == nil || source.StartDate == nil {
+		return source.RecurrenceRule, nil
+	}
+	loc, err := time.LoadLocation(source.Timezone)
+	if err != nil {
+		return nil, ErrInvalidTimezone
+	}
+	rule, err := recurrence.ShiftUntil(*source.RecurrenceRule, *source.StartDate, start, loc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRecurrence, err)
+	}
+	return &rule, nil
+}
+
+func shiftTime(t *time.Time, shift time.Duration) *time.Time {
+	if t == nil {
+		return nil
+	}
+	shifted := t.Add(shift)
+	return &shifted
+}
@@ -86,7 +86,7 @@ func (s *AuthServiceImpl) GetProfile(ctx context.Context, userID interface{}) (*
 	return user, nil
 }
 
-func (s *AuthServiceImpl) UpdateProfile(ctx context.Context, userID interface{}, username, fullName, profilePictureURL, bio, privacySetting string) (*model.User, error) {
+func (s *AuthServiceImpl) UpdateProfile(ctx context.Context, userID interface{}, username, fullName, profilePictureURL, bio, privacySetting, locale string) (*model.User, error) {
 	user, err := s.repo.FindByID(ctx, userID)
 	if err != nil || user == nil {
 		return nil, ErrUserNotFound
@@ -106,6 +106,9 @@ func (s *AuthServiceImpl) UpdateProfile(ctx context.Context, userID interface{},
 	if privacySetting != "" {
 		user.PrivacySetting = privacySetting
 	}
+	if locale != "" {
+		user.Locale = locale
+	}
 	user.UpdatedAt = time.Now()
 	err = s.repo.Update(ctx, user)
 	if err != nil {
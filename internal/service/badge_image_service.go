@@ -0,0 +1,85 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"ping-badge-be/internal/badgeimage"
+	"ping-badge-be/internal/model"
+	"strconv"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/google/uuid"
+)
+
+// BadgeImage is stored badge artwork with its thumbnails.
+type BadgeImage struct {
+	ObjectID   uuid.UUID
+	URL        string
+	Thumbnails map[string]string
+}
+
+type BadgeImageService interface {
+	Upload(ctx context.Context, ownerID uuid.UUID, filename string, body io.Reader) (*BadgeImage, error)
+}
+
+type badgeImageServiceImpl struct {
+	storage StorageService
+}
+
+func NewBadgeImageService(storage StorageService) BadgeImageService {
+	return &badgeImageServiceImpl{storage: storage}
+}
+
+// Upload validates PNG or SVG artwork, sanitizes SVGs, and stores the image
+// and its thumbnails as public objects.
+func (s *badgeImageServiceImpl) Upload(ctx context.Context, ownerID uuid.UUID, filename string, body io.Reader) (*BadgeImage, error) {
+	data, err := io.ReadAll(io.LimitReader(body, badgeimage.MaxFileBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, ErrEmptyFile
+	}
+	if len(data) > badgeimage.MaxFileBytes {
+		return nil, ErrFileTooLarge
+	}
+	detected := mimetype.Detect(data)
+	contentType := allowedType(detected, []string{badgeimage.ContentTypePNG, badgeimage.ContentTypeSVG})
+	if contentType == "" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFileType, detected.String())
+	}
+
+	assets, err := badgeimage.Process(contentType, data)
+	if err != nil {
+		return nil, err
+	}
+	base := strings.TrimSuffix(path.Base(filename), path.Ext(filename))
+	image := &BadgeImage{Thumbnails: map[string]string{}}
+	for _, asset := range assets {
+		name := filename
+		if asset.Size > 0 {
+			name = fmt.Sprintf("%s@%dpx.png", base, asset.Size)
+		}
+		object, err := s.storage.Store(ctx, model.ObjectBadgeImage, ownerID, name, bytes.NewReader(asset.Data))
+		if err != nil {
+			return nil, err
+		}
+		if asset.Size == 0 {
+			image.ObjectID = object.ObjectID
+			image.URL = s.storage.PublicURL(object)
+		} else {
+			image.Thumbnails[strconv.Itoa(asset.Size)] = s.storage.PublicURL(object)
+		}
+	}
+	// Vector artwork serves every size itself.
+	for _, size := range badgeimage.ThumbnailSizes {
+		if _, ok := image.Thumbnails[strconv.Itoa(size)]; !ok {
+			image.Thumbnails[strconv.Itoa(size)] = image.URL
+		}
+	}
+	return image, nil
+}
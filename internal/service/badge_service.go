@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"ping-badge-be/internal/listquery"
 	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
 	"ping-badge-be/internal/repository"
 
 	"github.com/google/uuid"
@@ -11,8 +13,9 @@ import (
 type BadgeService interface {
 	CreateBadge(ctx context.Context, badge *model.Badge) error
 	GetBadge(ctx context.Context, id uuid.UUID) (*model.Badge, error)
-	ListBadges(ctx context.Context, orgID *uuid.UUID, offset, limit int) ([]model.Badge, error)
+	ListBadges(ctx context.Context, orgID *uuid.UUID, criteria listquery.Query, page pagination.Params) ([]model.Badge, int64, error)
 	ListIssuedBadgesByUser(ctx context.Context, userID uuid.UUID) ([]model.IssuedBadge, error)
+	ListIssuedBadgesPage(ctx context.Context, userID uuid.UUID, criteria listquery.Query, page pagination.Params) ([]model.IssuedBadge, int64, error)
 	UpdateBadge(ctx context.Context, badge *model.Badge) error
 	DeleteBadge(ctx context.Context, id uuid.UUID) error
 }
@@ -35,9 +38,17 @@ func (s *badgeServiceImpl) GetBadge(ctx context.Context, id uuid.UUID) (*model.B
 	return s.repo.GetByID(ctx, id)
 }
 
-func (s *badgeServiceImpl) ListBadges(ctx context.Context, orgID *uuid.UUID, offset, limit int) ([]model.Badge, error) {
+func (s *badgeServiceImpl) ListBadges(ctx context.Context, orgID *uuid.UUID, criteria listquery.Query, page pagination.Params) ([]model.Badge, int64, error) {
 	// Add business logic, validation, authorization here
-	return s.repo.List(ctx, orgID, offset, limit)
+	total, err := s.repo.Count(ctx, orgID, criteria)
+	if err != nil {
+		return nil, 0, err
+	}
+	badges, err := s.repo.List(ctx, orgID, criteria, page)
+	if err != nil {
+		return nil, 0, err
+	}
+	return badges, total, nil
 }
 
 func (s *badgeServiceImpl) UpdateBadge(ctx context.Context, badge *model.Badge) error {
@@ -53,3 +64,15 @@ func (s *badgeServiceImpl) DeleteBadge(ctx context.Context, id uuid.UUID) error
 func (s *badgeServiceImpl) ListIssuedBadgesByUser(ctx context.Context, userID uuid.UUID) ([]model.IssuedBadge, error) {
 	return s.repo.ListIssuedBadgesByUser(ctx, userID)
 }
+
+func (s *badgeServiceImpl) ListIssuedBadgesPage(ctx context.Context, userID uuid.UUID, criteria listquery.Query, page pagination.Params) ([]model.IssuedBadge, int64, error) {
+	total, err := s.repo.CountIssuedBadges(ctx, userID, criteria)
+	if err != nil {
+		return nil, 0, err
+	}
+	badges, err := s.repo.ListIssuedBadgesPage(ctx, userID, criteria, page)
+	if err != nil {
+		return nil, 0, err
+	}
+	return badges, total, nil
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"ping-badge-be/internal/jobs"
+	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
+	"ping-badge-be/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type BulkStatusService interface {
+	UpdateStatuses(ctx context.Context, activityID uuid.UUID, req BulkStatusRequest, actor Actor) (*BulkStatusReport, *model.BulkOperation, error)
+	GetOperation(ctx context.Context, id uuid.UUID) (*model.BulkOperation, error)
+	RunOperation(ctx context.Context, id uuid.UUID) error
+}
+
+const (
+	// maxBulkSyncItems is the largest batch applied within the request;
+	// larger batches run as a background job.
+	maxBulkSyncItems = 100
+	// maxBulkItems caps the participations of a single bulk update.
+	maxBulkItems = 5000
+	// bulkChunkSize is how many participations a background job applies per
+	// transaction, and so how often its progress advances.
+	bulkChunkSize = 100
+)
+
+var (
+	ErrBulkNoSelection = errors.New("give either participation_ids or from_status")
+	ErrBulkEmpty       = errors.New("no participations match the selection")
+	ErrBulkTooLarge    = fmt.Errorf("a bulk update is limited to %d participations", maxBulkItems)
+)
+
+// BulkStatusRequest selects participations of one activity, either by ID or
+// as all of those currently in FromStatus, and the status to move them to.
+type BulkStatusRequest struct {
+	ParticipationIDs []uuid.UUID
+	FromStatus       string
+	Status           string
+	Reason           string
+}
+
+// BulkStatusReport is the outcome of a bulk update applied within the request.
+type BulkStatusReport struct {
+	Total     int                      `json:"total"`
+	Succeeded int                      `json:"succeeded"`
+	Failed    int                      `json:"failed"`
+	Results   []model.BulkStatusResult `json:"results"`
+}
+
+type bulkStatusServiceImpl struct {
+	repo              repository.BulkOperationRepository
+	participationRepo repository.ActivityParticipationRepository
+	uow               repository.UnitOfWork
+}
+
+func NewBulkStatusService(repo repository.BulkOperationRepository, participationRepo repository.ActivityParticipationRepository, uow repository.UnitOfWork) BulkStatusService {
+	return &bulkStatusServiceImpl{repo: repo, participationRepo: participationRepo, uow: uow}
+}
+
+// UpdateStatuses moves the selected participations to req.Status on behalf of
+// actor. Up to maxBulkSyncItems participations are updated at once in a single
+// transaction and reported; larger selections are queued as a bulk operation
+// to poll instead. Participations that cannot make the transition are
+// reported and left unchanged.
+func (s *bulkStatusServiceImpl) UpdateStatuses(ctx context.Context, activityID uuid.UUID, req BulkStatusRequest, actor Actor) (*BulkStatusReport, *model.BulkOperation, error) {
+	if !IsParticipationStatus(req.Status) {
+		return nil, nil, ErrInvalidStatus
+	}
+	if (len(req.ParticipationIDs) == 0) == (req.FromStatus == "") {
+		return nil, nil, ErrBulkNoSelection
+	}
+	if req.FromStatus != "" && !IsParticipationStatus(req.FromStatus) {
+		return nil, nil, ErrInvalidStatus
+	}
+	ids, err := s.selectParticipations(activityID, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil, ErrBulkEmpty
+	}
+	if len(ids) > maxBulkItems {
+		return nil, nil, ErrBulkTooLarge
+	}
+
+	if len(ids) <= maxBulkSyncItems {
+		var results []model.BulkStatusResult
+		err := s.uow.Do(ctx, func(repos repository.Repositories) error {
+			var err error
+			results, err = applyBulkStatus(ctx, repos, activityID, ids, req.Status, req.Reason, actor)
+			return err
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		succeeded, failed := tallyBulkResults(results)
+		return &BulkStatusReport{Total: len(results), Succeeded: succeeded, Failed: failed, Results: results}, nil, nil
+	}
+
+	operation := &model.BulkOperation{
+		OperationID:      uuid.New(),
+		ActivityID:       activityID,
+		TargetStatus:     req.Status,
+		ParticipationIDs: ids,
+		Status:           model.BulkOperationPending,
+		Total:            len(ids),
+		Results:          []model.BulkStatusResult{},
+	}
+	if req.Reason != "" {
+		operation.Reason = &req.Reason
+	}
+	if actor.UserID != nil {
+		operation.RequestedBy = *actor.UserID
+	}
+	err = s.uow.Do(ctx, func(repos repository.Repositories) error {
+		job := &model.Job{
+			JobType: jobs.TypeBulkStatus,
+			Payload: map[string]interface{}{"operation_id": operation.OperationID.String()},
+		}
+		if err := repos.Jobs.Enqueue(ctx, job); err != nil {
+			return err
+		}
+		operation.JobID = &job.JobID
+		return repos.BulkOperations.Create(ctx, operation)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, operation, nil
+}
+
+func (s *bulkStatusServiceImpl) GetOperation(ctx context.Context, id uuid.UUID) (*model.BulkOperation, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// RunOperation applies a queued bulk operation in chunks of bulkChunkSize,
+// each in one transaction together with the progress it makes, so a failed
+// run retried by its job resumes after the last committed chunk.
+func (s *bulkStatusServiceImpl) RunOperation(ctx context.Context, id uuid.UUID) error {
+	operation, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if operation.Status == model.BulkOperationSucceeded {
+		return nil
+	}
+	operation.Status = model.BulkOperationRunning
+	operation.LastError = nil
+	if err := s.repo.Save(ctx, operation); err != nil {
+		return err
+	}
+
+	actor := UserActor(ActorStaff, operation.RequestedBy)
+	reason := ""
+	if operation.Reason != nil {
+		reason = *operation.Reason
+	}
+	ids := operation.ParticipationIDs
+	for operation.Processed < len(ids) {
+		end := min(operation.Processed+bulkChunkSize, len(ids))
+		// Work on a copy so a rolled back chunk leaves operation as stored
+		progress := *operation
+		err := s.uow.Do(ctx, func(repos repository.Repositories) error {
+			results, err := applyBulkStatus(ctx, repos, operation.ActivityID, ids[operation.Processed:end], operation.TargetStatus, reason, actor)
+			if err != nil {
+				return err
+			}
+			succeeded, failed := tallyBulkResults(results)
+			progress.Results = append(append([]model.BulkStatusResult{}, operation.Results...), results...)
+			progress.Processed = end
+			progress.Succeeded += succeeded
+			progress.Failed += failed
+			if end == len(ids) {
+				now := time.Now()
+				progress.Status = model.BulkOperationSucceeded
+				progress.CompletedAt = &now
+			}
+			return repos.BulkOperations.Save(ctx, &progress)
+		})
+		if err != nil {
+			message := err.Error()
+			operation.Status = model.BulkOperationFailed
+			operation.LastError = &message
+			return errors.Join(err, s.repo.Save(ctx, operation))
+		}
+		operation = &progress
+	}
+	return nil
+}
+
+// selectParticipations returns the IDs picked by req, dropping duplicates.
+func (s *bulkStatusServiceImpl) selectParticipations(activityID uuid.UUID, req BulkStatusRequest) ([]uuid.UUID, error) {
+	if req.FromStatus == "" {
+		seen := make(map[uuid.UUID]bool, len(req.ParticipationIDs))
+		ids := make([]uuid.UUID, 0, len(req.ParticipationIDs))
+		for _, id := range req.ParticipationIDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		return ids, nil
+	}
+	participations, err := s.participationRepo.FindAll(repository.ParticipationFilter{ActivityID: &activityID, Statuses: []string{req.FromStatus}}, pagination.Params{Limit: maxBulkItems + 1})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, len(participations))
+	for i, p := range participations {
+		ids[i] = p.ParticipationID
+	}
+	return ids, nil
+}
+
+// applyBulkStatus moves each participation of the activity to status and
+// issues badges for completions. Participations that are missing, belong to
+// another activity or cannot make the transition are reported before anything
+// is written for them, so the rest of the batch goes on; any other error
+// aborts the batch.
+func applyBulkStatus(ctx context.Context, repos repository.Repositories, activityID uuid.UUID, ids []uuid.UUID, status, reason string, actor Actor) ([]model.BulkStatusResult, error) {
+	activity, err := repos.Activities.FindByIDForUpdate(activityID)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]model.BulkStatusResult, 0, len(ids))
+	freedPlaces := false
+	for _, id := range ids {
+		result := model.BulkStatusResult{ParticipationID: id}
+		participation, err := repos.Participations.FindByID(id)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			result.Error = "participation not found"
+		case err != nil:
+			return nil, err
+		case participation.ActivityID != activityID:
+			result.Error = "participation belongs to another activity"
+		}
+		if result.Error != "" {
+			results = append(results, result)
+			continue
+		}
+
+		result.FromStatus = participation.Status
+		if participation.Status == status {
+			result.Status = status
+			result.IssuedBadgeID = participation.IssuedBadgeID
+			results = append(results, result)
+			continue
+		}
+		if err := checkTransition(participation.Status, status, actor); err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+
+		var extra map[string]interface{}
+		if status == model.ParticipationWithdrawn {
+			extra = map[string]interface{}{"waitlist_position": nil}
+			freedPlaces = freedPlaces || participation.Status != model.ParticipationWaitlisted
+		}
+		updated, err := transitionParticipation(ctx, repos, participation, status, actor, reason, extra)
+		if err != nil {
+			return nil, err
+		}
+		if status == model.ParticipationCompleted {
+			if err := createBadgeForCompletion(ctx, repos, updated); err != nil {
+				return nil, err
+			}
+			if updated, err = repos.Participations.FindByID(id); err != nil {
+				return nil, err
+			}
+		}
+		result.Status = updated.Status
+		result.IssuedBadgeID = updated.IssuedBadgeID
+		results = append(results, result)
+	}
+	if freedPlaces {
+		if err := promoteFromWaitlist(ctx, repos, activity); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+func tallyBulkResults(results []model.BulkStatusResult) (succeeded, failed int) {
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+		} else {
+			succeeded++
+		}
+	}
+	return succeeded, failed
+}
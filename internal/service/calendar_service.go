@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"ping-badge-be/internal/constant"
+	"ping-badge-be/internal/ical"
+	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	calendarProdID = "-//PingBadge//Activities//EN"
+	// calendarHistory is how far back feeds reach, so recent past events and
+	// cancellations stay visible.
+	calendarHistory = 90 * 24 * time.Hour
+	// maxCalendarEvents caps the size of a feed.
+	maxCalendarEvents = 1000
+)
+
+// CalendarService renders activities as iCalendar feeds and attachments.
+type CalendarService interface {
+	OrganizationFeed(ctx context.Context, orgID uuid.UUID) ([]byte, error)
+	UserFeed(ctx context.Context, token string) ([]byte, error)
+	ActivityEvent(ctx context.Context, activityID uuid.UUID) ([]byte, error)
+	// FeedToken returns the user's private feed token, creating it on first
+	// use; ResetFeedToken replaces it, invalidating the old feed URL.
+	FeedToken(ctx context.Context, userID uuid.UUID) (string, error)
+	ResetFeedToken(ctx context.Context, userID uuid.UUID) (string, error)
+}
+
+type calendarServiceImpl struct {
+	activityRepo repository.ActivityRepository
+	orgRepo      *repository.OrganizationRepository
+	userRepo     repository.UserRepository
+	baseURL      string
+}
+
+func NewCalendarService(activityRepo repository.ActivityRepository, orgRepo *repository.OrganizationRepository, userRepo repository.UserRepository, baseURL string) CalendarService {
+	return &calendarServiceImpl{activityRepo: activityRepo, orgRepo: orgRepo, userRepo: userRepo, baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+func (s *calendarServiceImpl) OrganizationFeed(ctx context.Context, orgID uuid.UUID) ([]byte, error) {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	activities, err := s.activityRepo.FindForCalendar(repository.CalendarFilter{
+		OrgID: &orgID,
+		Since: time.Now().Add(-calendarHistory),
+	}, maxCalendarEvents)
+	if err != nil {
+		return nil, err
+	}
+	return s.encode(ctx, org.OrgName, "", activities)
+}
+
+func (s *calendarServiceImpl) UserFeed(ctx context.Context, token string) ([]byte, error) {
+	user, err := s.userRepo.FindByCalendarToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	activities, err := s.activityRepo.FindForCalendar(repository.CalendarFilter{
+		UserID: &user.UserID,
+		Since:  time.Now().Add(-calendarHistory),
+	}, maxCalendarEvents)
+	if err != nil {
+		return nil, err
+	}
+	return s.encode(ctx, "My PingBadge activities", "", activities)
+}
+
+// ActivityEvent renders a single activity for import as a file attachment.
+func (s *calendarServiceImpl) ActivityEvent(ctx context.Context, activityID uuid.UUID) ([]byte, error) {
+	activity, err := s.activityRepo.FindByID(activityID)
+	if err != nil {
+		return nil, err
+	}
+	return s.encode(ctx, "", "PUBLISH", []model.Activity{*activity})
+}
+
+func (s *calendarServiceImpl) FeedToken(ctx context.Context, userID uuid.UUID) (string, error) {
+	token, err := newCalendarToken()
+	if err != nil {
+		return "", err
+	}
+	return s.userRepo.EnsureCalendarToken(ctx, userID, token)
+}
+
+func (s *calendarServiceImpl) ResetFeedToken(ctx context.Context, userID uuid.UUID) (string, error) {
+	token, err := newCalendarToken()
+	if err != nil {
+		return "", err
+	}
+	if err := s.userRepo.SetCalendarToken(ctx, userID, token); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *calendarServiceImpl) encode(ctx context.Context, name, method string, activities []model.Activity) ([]byte, error) {
+	orgNames := make(map[uuid.UUID]string)
+	cal := ical.Calendar{ProdID: calendarProdID, Name: name, Method: method}
+	for _, activity := range activities {
+		if activity.StartDate == nil {
+			continue
+		}
+		orgName, ok := orgNames[activity.OrgID]
+		if !ok {
+			if org, err := s.orgRepo.GetByID(ctx, activity.OrgID); err == nil {
+				orgName = org.OrgName
+			}
+			orgNames[activity.OrgID] = orgName
+		}
+		cal.Events = append(cal.Events, s.activityEvent(activity, orgName))
+	}
+	return cal.Encode(time.Now()), nil
+}
+
+// ActivityPath is the path of the route serving the activity.
+func ActivityPath(activityID uuid.UUID) string {
+	return constant.APIPrefix + "/activities/" + activityID.String()
+}
+
+// CalendarFeedPath is the path of the private calendar feed for token.
+func CalendarFeedPath(token string) string {
+	return constant.APIPrefix + "/calendar/" + token + ".ics"
+}
+
+// activityEvent maps an activity to a VEVENT. The UID is derived from the
+// activity ID so it never changes; cancelled and deleted activities are
+// cancelled events.
+func (s *calendarServiceImpl) activityEvent(activity model.Activity, orgName string) ical.Event {
+	event := ical.Event{
+		UID:          activity.ActivityID.String() + "@pingbadge",
+		Sequence:     activity.Sequence,
+		Status:       ical.StatusConfirmed,
+		Summary:      activity.ActivityName,
+		URL:          s.baseURL + ActivityPath(activity.ActivityID),
+		Start:        *activity.StartDate,
+		ExDates:      activity.RecurrenceExceptions,
+		Created:      activity.CreatedAt,
+		LastModified: activity.UpdatedAt,
+	}
+	if activity.DeletedAt.Valid || activity.Status == model.ActivityCancelled {
+		event.Status = ical.StatusCancelled
+	}
+	if activity.EndDate != nil {
+		event.End = *activity.EndDate
+	}
+	if loc, err := time.LoadLocation(activity.Timezone); err == nil {
+		event.TimeZone = loc
+	}
+	if activity.RecurrenceRule != nil {
+		event.RRule = strings.TrimPrefix(*activity.RecurrenceRule, "RRULE:")
+	}
+	if activity.Location != nil {
+		event.Location = *activity.Location
+	}
+	var description []string
+	if activity.Description != nil && *activity.Description != "" {
+		description = append(description, *activity.Description)
+	}
+	if orgName != "" {
+		description = append(description, "Hosted by "+orgName)
+	}
+	event.Description = strings.Join(description, "\n\n")
+	return event
+}
+
+func newCalendarToken() (string, error) {
+	buf := make([]byte, 20)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
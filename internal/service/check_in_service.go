@@ -0,0 +1,339 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"ping-badge-be/internal/checkin"
+	"ping-badge-be/internal/geo"
+	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CheckInCode is the door code currently shown for an activity or session.
+type CheckInCode struct {
+	Code          string     `json:"code"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	PeriodSeconds int        `json:"period_seconds"`
+	ActivityID    uuid.UUID  `json:"activity_id"`
+	SessionID     *uuid.UUID `json:"session_id,omitempty"`
+	QRPayload     string     `json:"qr_payload"`
+}
+
+// CheckInPass is a participant's personal QR pass.
+type CheckInPass struct {
+	Token           string    `json:"token"`
+	ParticipationID uuid.UUID `json:"participation_id"`
+	ExpiresAt       time.Time `json:"expires_at"`
+}
+
+type CheckInService interface {
+	CurrentCode(ctx context.Context, activityID uuid.UUID, sessionID *uuid.UUID) (*CheckInCode, error)
+	CheckInWithCode(ctx context.Context, activityID uuid.UUID, sessionID *uuid.UUID, userID uuid.UUID, code string, location *geo.Point) (*model.ActivityParticipation, *model.CheckInRecord, error)
+	IssuePass(ctx context.Context, participationID uuid.UUID) (*CheckInPass, error)
+	CheckInWithPass(ctx context.Context, activityID uuid.UUID, sessionID *uuid.UUID, token string, actor Actor, location *geo.Point) (*model.ActivityParticipation, *model.CheckInRecord, error)
+	ListCheckIns(ctx context.Context, activityID uuid.UUID, flaggedOnly bool) ([]model.CheckInRecord, error)
+	GetCheckIn(ctx context.Context, id uuid.UUID) (*model.CheckInRecord, error)
+	ReviewCheckIn(ctx context.Context, id uuid.UUID, accept bool, actor Actor) (*model.ActivityParticipation, error)
+}
+
+var (
+	ErrInvalidCheckInCode   = errors.New("invalid or expired check-in code")
+	ErrSessionNotInActivity = errors.New("session does not belong to the activity")
+	ErrPassNotForActivity   = errors.New("check-in pass is for another activity")
+	ErrLocationRequired     = errors.New("this activity requires your location to check in")
+	ErrOutsideGeofence      = errors.New("you are too far from the activity location to check in")
+	ErrCheckInNotFlagged    = errors.New("check-in is not waiting for review")
+)
+
+type checkInServiceImpl struct {
+	activityRepo repository.ActivityRepository
+	repo         repository.ActivityParticipationRepository
+	sessionRepo  repository.ActivitySessionRepository
+	checkInRepo  repository.CheckInRepository
+	uow          repository.UnitOfWork
+	passKey      []byte
+}
+
+func NewCheckInService(activityRepo repository.ActivityRepository, repo repository.ActivityParticipationRepository, sessionRepo repository.ActivitySessionRepository, checkInRepo repository.CheckInRepository, uow repository.UnitOfWork, passKey string) CheckInService {
+	return &checkInServiceImpl{
+		activityRepo: activityRepo,
+		repo:         repo,
+		sessionRepo:  sessionRepo,
+		checkInRepo:  checkInRepo,
+		uow:          uow,
+		passKey:      []byte(passKey),
+	}
+}
+
+// CurrentCode returns the door code for the activity, or for one of its
+// sessions when sessionID is set. The secret is created on first use.
+func (s *checkInServiceImpl) CurrentCode(ctx context.Context, activityID uuid.UUID, sessionID *uuid.UUID) (*CheckInCode, error) {
+	activity, err := s.activityRepo.FindByID(activityID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkActivityOpen(activity); err != nil {
+		return nil, err
+	}
+	secret, err := s.secret(ctx, activity, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	code, err := checkin.Code(secret, now)
+	if err != nil {
+		return nil, err
+	}
+	return &CheckInCode{
+		Code:          code,
+		ExpiresAt:     checkin.ExpiresAt(now),
+		PeriodSeconds: int(checkin.Period / time.Second),
+		ActivityID:    activityID,
+		SessionID:     sessionID,
+		QRPayload:     checkin.Payload(activityID, sessionID, code),
+	}, nil
+}
+
+// CheckInWithCode checks the user in after they typed or scanned the door
+// code. The change is made by the system on the user's behalf, since
+// participants cannot check themselves in without a valid code. Activities
+// with a geofence also check the device location against it.
+func (s *checkInServiceImpl) CheckInWithCode(ctx context.Context, activityID uuid.UUID, sessionID *uuid.UUID, userID uuid.UUID, code string, location *geo.Point) (*model.ActivityParticipation, *model.CheckInRecord, error) {
+	activity, err := s.activityRepo.FindByID(activityID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkActivityOpen(activity); err != nil {
+		return nil, nil, err
+	}
+	secret, err := s.secret(ctx, activity, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !checkin.Verify(secret, code, time.Now()) {
+		return nil, nil, ErrInvalidCheckInCode
+	}
+	record := locateCheckIn(activity, location)
+	if err := checkGeofence(activity, record); err != nil {
+		return nil, nil, err
+	}
+	participation, err := s.repo.FindActive(activityID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, ErrNotJoined
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	record.Method = model.CheckInByCode
+	actor := Actor{Type: ActorSystem, UserID: &userID}
+	return s.checkIn(ctx, participation.ParticipationID, sessionID, actor, "check_in_code", record)
+}
+
+// locateCheckIn starts the check-in record with the device location and its
+// distance from the activity, when both are known.
+func locateCheckIn(activity *model.Activity, location *geo.Point) *model.CheckInRecord {
+	record := &model.CheckInRecord{ActivityID: activity.ActivityID}
+	if location == nil {
+		return record
+	}
+	record.Latitude = &location.Latitude
+	record.Longitude = &location.Longitude
+	if activity.Latitude != nil && activity.Longitude != nil {
+		distance := geo.Distance(geo.Point{Latitude: *activity.Latitude, Longitude: *activity.Longitude}, *location)
+		record.DistanceMeters = &distance
+	}
+	return record
+}
+
+// checkGeofence enforces the activity's geofence on a check-in. Outside the
+// radius, or without a location, the check-in is rejected or flagged for
+// review according to the activity's geofence policy.
+func checkGeofence(activity *model.Activity, record *model.CheckInRecord) error {
+	if activity.GeofenceRadiusMeters == nil {
+		return nil
+	}
+	var violation error
+	switch {
+	case record.DistanceMeters == nil:
+		violation = ErrLocationRequired
+	case *record.DistanceMeters > float64(*activity.GeofenceRadiusMeters):
+		violation = fmt.Errorf("%w (%.0f m away, limit %d m)", ErrOutsideGeofence, *record.DistanceMeters, *activity.GeofenceRadiusMeters)
+	default:
+		return nil
+	}
+	if activity.GeofencePolicy == model.GeofenceFlag {
+		record.Flagged = true
+		return nil
+	}
+	return violation
+}
+
+// IssuePass signs a short-lived personal pass for the participation.
+func (s *checkInServiceImpl) IssuePass(ctx context.Context, participationID uuid.UUID) (*CheckInPass, error) {
+	participation, err := s.repo.FindByID(participationID)
+	if err != nil {
+		return nil, err
+	}
+	if participation.Status == model.ParticipationWithdrawn {
+		return nil, ErrNotJoined
+	}
+	token, pass := checkin.IssuePass(s.passKey, participationID, time.Now())
+	return &CheckInPass{Token: token, ParticipationID: participationID, ExpiresAt: pass.ExpiresAt}, nil
+}
+
+// CheckInWithPass checks in the holder of a scanned personal pass on behalf
+// of a staff member. Staff are at the door, so the geofence is not enforced;
+// a location sent along is only recorded.
+func (s *checkInServiceImpl) CheckInWithPass(ctx context.Context, activityID uuid.UUID, sessionID *uuid.UUID, token string, actor Actor, location *geo.Point) (*model.ActivityParticipation, *model.CheckInRecord, error) {
+	pass, err := checkin.ParsePass(s.passKey, token, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+	participation, err := s.repo.FindByID(pass.ParticipationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if participation.ActivityID != activityID {
+		return nil, nil, ErrPassNotForActivity
+	}
+	activity, err := s.activityRepo.FindByID(activityID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkActivityOpen(activity); err != nil {
+		return nil, nil, err
+	}
+	if sessionID != nil {
+		session, err := s.sessionRepo.GetByID(ctx, *sessionID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if session.ActivityID != activityID {
+			return nil, nil, ErrSessionNotInActivity
+		}
+	}
+	record := locateCheckIn(activity, location)
+	record.Method = model.CheckInByPass
+	return s.checkIn(ctx, participation.ParticipationID, sessionID, actor, "check_in_pass", record)
+}
+
+// checkIn moves the participation to checked in, for a session records
+// attendance, and logs record. Checking in someone already past check-in
+// changes nothing but the log.
+func (s *checkInServiceImpl) checkIn(ctx context.Context, participationID uuid.UUID, sessionID *uuid.UUID, actor Actor, reason string, record *model.CheckInRecord) (*model.ActivityParticipation, *model.CheckInRecord, error) {
+	var participation *model.ActivityParticipation
+	err := s.uow.Do(ctx, func(repos repository.Repositories) error {
+		var err error
+		participation, err = repos.Participations.FindByID(participationID)
+		if err != nil {
+			return err
+		}
+		if CanTransition(participation.Status, model.ParticipationCheckedIn, actor.Type) {
+			participation, err = transitionParticipation(ctx, repos, participation, model.ParticipationCheckedIn, actor, reason, nil)
+			if err != nil {
+				return err
+			}
+		} else if err := checkInAllowed(participation.Status, actor); err != nil {
+			return err
+		}
+		if sessionID != nil {
+			session, err := repos.Sessions.GetByID(ctx, *sessionID)
+			if err != nil {
+				return err
+			}
+			_, participation, err = recordAttendance(ctx, repos, session, participation, actor)
+			if err != nil {
+				return err
+			}
+		}
+		record.ParticipationID = participation.ParticipationID
+		record.SessionID = sessionID
+		record.ActorID = actor.UserID
+		return repos.CheckIns.Create(ctx, record)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return participation, record, nil
+}
+
+func (s *checkInServiceImpl) ListCheckIns(ctx context.Context, activityID uuid.UUID, flaggedOnly bool) ([]model.CheckInRecord, error) {
+	return s.checkInRepo.ListByActivity(ctx, activityID, flaggedOnly)
+}
+
+func (s *checkInServiceImpl) GetCheckIn(ctx context.Context, id uuid.UUID) (*model.CheckInRecord, error) {
+	return s.checkInRepo.GetByID(ctx, id)
+}
+
+// ReviewCheckIn resolves a flagged check-in. Accepting it clears the flag;
+// rejecting it marks the participant as a no-show.
+func (s *checkInServiceImpl) ReviewCheckIn(ctx context.Context, id uuid.UUID, accept bool, actor Actor) (*model.ActivityParticipation, error) {
+	var participation *model.ActivityParticipation
+	err := s.uow.Do(ctx, func(repos repository.Repositories) error {
+		record, err := repos.CheckIns.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !record.Flagged || record.ReviewedAt != nil {
+			return ErrCheckInNotFlagged
+		}
+		if err := repos.CheckIns.MarkReviewed(ctx, id, *actor.UserID); err != nil {
+			return err
+		}
+		participation, err = repos.Participations.FindByID(record.ParticipationID)
+		if err != nil || accept {
+			return err
+		}
+		participation, err = transitionParticipation(ctx, repos, participation, model.ParticipationNoShow, actor, "check_in_rejected", nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return participation, nil
+}
+
+// checkInAllowed rejects participations that can neither be checked in nor
+// are already past check-in, such as waitlisted or withdrawn ones.
+func checkInAllowed(status string, actor Actor) error {
+	switch status {
+	case model.ParticipationCheckedIn, model.ParticipationEvidenceSubmitted, model.ParticipationApproved,
+		model.ParticipationRejected, model.ParticipationCompleted:
+		return nil
+	}
+	return checkTransition(status, model.ParticipationCheckedIn, actor)
+}
+
+func (s *checkInServiceImpl) secret(ctx context.Context, activity *model.Activity, sessionID *uuid.UUID) (string, error) {
+	if sessionID == nil {
+		if activity.CheckInSecret != nil {
+			return *activity.CheckInSecret, nil
+		}
+		secret, err := checkin.GenerateSecret()
+		if err != nil {
+			return "", err
+		}
+		return s.activityRepo.EnsureCheckInSecret(activity.ActivityID, secret)
+	}
+
+	session, err := s.sessionRepo.GetByID(ctx, *sessionID)
+	if err != nil {
+		return "", err
+	}
+	if session.ActivityID != activity.ActivityID {
+		return "", ErrSessionNotInActivity
+	}
+	if session.CheckInSecret != nil {
+		return *session.CheckInSecret, nil
+	}
+	secret, err := checkin.GenerateSecret()
+	if err != nil {
+		return "", err
+	}
+	return s.sessionRepo.EnsureCheckInSecret(ctx, *sessionID, secret)
+}
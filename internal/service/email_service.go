@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"ping-badge-be/internal/constant"
+	"ping-badge-be/internal/jobs"
+	"ping-badge-be/internal/mailer"
+	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UnsubscribeAll is the unsubscribe category that suppresses every email.
+const UnsubscribeAll = "all"
+
+type EmailService interface {
+	HandleEvent(ctx context.Context, repos repository.Repositories, event model.OutboxEvent) error
+	SendQueued(ctx context.Context, emailID uuid.UUID) error
+	UnsubscribeURL(userID uuid.UUID, category string) string
+	Unsubscribe(ctx context.Context, userID uuid.UUID, category, token string) error
+	HandleBounce(ctx context.Context, email, kind, providerMessageID string) error
+}
+
+var ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe token")
+
+type emailServiceImpl struct {
+	repo     repository.EmailRepository
+	uow      repository.UnitOfWork
+	mailer   mailer.Mailer
+	renderer *mailer.Renderer
+	from     string
+	baseURL  string
+	secret   string
+}
+
+func NewEmailService(repo repository.EmailRepository, uow repository.UnitOfWork, m mailer.Mailer, renderer *mailer.Renderer, from, baseURL, secret string) EmailService {
+	return &emailServiceImpl{
+		repo:     repo,
+		uow:      uow,
+		mailer:   m,
+		renderer: renderer,
+		from:     from,
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		secret:   secret,
+	}
+}
+
+// HandleEvent is the outbox consumer that turns domain events into queued
+// emails. The send log row and its delivery job commit with the delivery record.
+func (s *emailServiceImpl) HandleEvent(ctx context.Context, repos repository.Repositories, event model.OutboxEvent) error {
+	switch event.EventType {
+	case model.EventBadgeIssued:
+		return s.queueBadgeAwarded(ctx, repos, event)
+	case model.EventParticipationStatusChanged:
+		if payloadString(event, "to_status") != "APPROVED" {
+			return nil
+		}
+		return s.queueActivityEmail(ctx, repos, event, model.NotificationParticipationStatus, mailer.TemplateParticipationApproved)
+	case model.EventActivityStartingSoon:
+		return s.queueActivityEmail(ctx, repos, event, model.NotificationActivityReminder, mailer.TemplateActivityReminder)
+	case model.EventActivityCancelled:
+		return s.queueActivityEmail(ctx, repos, event, model.NotificationActivityChanged, mailer.TemplateActivityCancelled)
+	case model.EventActivityRescheduled:
+		return s.queueActivityEmail(ctx, repos, event, model.NotificationActivityChanged, mailer.TemplateActivityRescheduled)
+	}
+	return nil
+}
+
+func (s *emailServiceImpl) queueBadgeAwarded(ctx context.Context, repos repository.Repositories, event model.OutboxEvent) error {
+	badgeDefID, err := payloadUUID(event, "badge_def_id")
+	if err != nil {
+		return err
+	}
+	badge, err := repos.Badges.GetByID(ctx, badgeDefID)
+	if err != nil {
+		return err
+	}
+	data := mailer.TemplateData{
+		BadgeName:     badge.BadgeName,
+		BadgeImageURL: badge.ImageURL,
+		ActionURL:     s.baseURL + constant.APIPrefix + "/badges/" + badge.BadgeDefID.String(),
+	}
+	if activityID, err := payloadUUID(event, "activity_id"); err == nil {
+		if activity, err := repos.Activities.FindByID(activityID); err == nil {
+			data.ActivityName = activity.ActivityName
+		}
+	}
+	return s.queue(ctx, repos, event, model.NotificationBadgeIssued, mailer.TemplateBadgeAwarded, badge.OrgID, data)
+}
+
+func (s *emailServiceImpl) queueActivityEmail(ctx context.Context, repos repository.Repositories, event model.OutboxEvent, category, template string) error {
+	activityID, err := payloadUUID(event, "activity_id")
+	if err != nil {
+		return err
+	}
+	activity, err := repos.Activities.FindByID(activityID)
+	if err != nil {
+		return err
+	}
+	data := mailer.TemplateData{
+		ActivityName: activity.ActivityName,
+		Reason:       payloadString(event, "reason"),
+		ActionURL:    s.baseURL + ActivityPath(activity.ActivityID),
+	}
+	if activity.StartDate != nil {
+		data.StartsAt = activity.StartDate.Format(time.RFC1123)
+	}
+	if activity.Location != nil {
+		data.Location = *activity.Location
+	}
+	return s.queue(ctx, repos, event, category, template, activity.OrgID, data)
+}
+
+// queue renders the template for the event's user and stores it in the send
+// log with a delivery job, unless the user opted out or the address is suppressed.
+func (s *emailServiceImpl) queue(ctx context.Context, repos repository.Repositories, event model.OutboxEvent, category, template string, orgID uuid.UUID, data mailer.TemplateData) error {
+	userID, err := payloadUUID(event, "user_id")
+	if err != nil {
+		return err
+	}
+	enabled, err := repos.Notifications.IsEmailEnabled(ctx, userID, category)
+	if err != nil || !enabled {
+		return err
+	}
+	user, err := repos.Users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	suppressed, err := repos.Emails.IsSuppressed(ctx, user.Email)
+	if err != nil || suppressed {
+		return err
+	}
+
+	data.Locale = user.Locale
+	data.RecipientName = user.Username
+	if user.FullName != nil && *user.FullName != "" {
+		data.RecipientName = *user.FullName
+	}
+	if org, err := repos.Organizations.GetByID(ctx, orgID); err == nil {
+		data.OrgName = org.OrgName
+		if org.OrgLogoURL != nil {
+			data.OrgLogoURL = *org.OrgLogoURL
+		}
+	}
+	unsubscribeURL := s.UnsubscribeURL(userID, category)
+	data.UnsubscribeURL = unsubscribeURL
+
+	subject, html, text, err := s.renderer.Render(template, data)
+	if err != nil {
+		return err
+	}
+	emailLog := &model.EmailLog{
+		EmailID:        uuid.New(),
+		UserID:         &userID,
+		OrgID:          &orgID,
+		ToAddress:      user.Email,
+		Template:       template,
+		Locale:         data.Locale,
+		Subject:        subject,
+		HTMLBody:       html,
+		TextBody:       text,
+		UnsubscribeURL: &unsubscribeURL,
+		Status:         model.EmailStatusQueued,
+	}
+	if err := repos.Emails.CreateLog(ctx, emailLog); err != nil {
+		return err
+	}
+	return repos.Jobs.Enqueue(ctx, &model.Job{
+		JobType: jobs.TypeEmailSend,
+		Payload: map[string]interface{}{"email_id": emailLog.EmailID.String()},
+	})
+}
+
+// SendQueued delivers an email from the send log. Already sent emails are
+// skipped so a retried job never sends twice.
+func (s *emailServiceImpl) SendQueued(ctx context.Context, emailID uuid.UUID) error {
+	emailLog, err := s.repo.GetLog(ctx, emailID)
+	if err != nil {
+		return err
+	}
+	if emailLog.Status != model.EmailStatusQueued && emailLog.Status != model.EmailStatusFailed {
+		return nil
+	}
+	suppressed, err := s.repo.IsSuppressed(ctx, emailLog.ToAddress)
+	if err != nil {
+		return err
+	}
+	if suppressed {
+		return s.repo.UpdateLog(ctx, emailID, map[string]interface{}{"status": model.EmailStatusFailed, "error": "address suppressed"})
+	}
+
+	msg := mailer.Message{
+		From:    s.from,
+		To:      emailLog.ToAddress,
+		Subject: emailLog.Subject,
+		HTML:    emailLog.HTMLBody,
+		Text:    emailLog.TextBody,
+	}
+	if emailLog.UnsubscribeURL != nil {
+		msg.Headers = map[string]string{"List-Unsubscribe": "<" + *emailLog.UnsubscribeURL + ">"}
+	}
+	messageID, err := s.mailer.Send(ctx, msg)
+	if err != nil {
+		if updateErr := s.repo.UpdateLog(ctx, emailID, map[string]interface{}{"status": model.EmailStatusFailed, "error": err.Error()}); updateErr != nil {
+			return updateErr
+		}
+		return err
+	}
+	return s.repo.UpdateLog(ctx, emailID, map[string]interface{}{
+		"status":              model.EmailStatusSent,
+		"provider_message_id": messageID,
+		"sent_at":             time.Now(),
+		"error":               nil,
+	})
+}
+
+func (s *emailServiceImpl) UnsubscribeURL(userID uuid.UUID, category string) string {
+	query := url.Values{}
+	query.Set("user_id", userID.String())
+	query.Set("category", category)
+	query.Set("token", s.unsubscribeToken(userID, category))
+	return s.baseURL + constant.APIPrefix + "/email/unsubscribe?" + query.Encode()
+}
+
+// Unsubscribe turns off email for one notification category, or suppresses
+// the user's address entirely for UnsubscribeAll.
+func (s *emailServiceImpl) Unsubscribe(ctx context.Context, userID uuid.UUID, category, token string) error {
+	if !hmac.Equal([]byte(token), []byte(s.unsubscribeToken(userID, category))) {
+		return ErrInvalidUnsubscribeToken
+	}
+	return s.uow.Do(ctx, func(repos repository.Repositories) error {
+		if category == UnsubscribeAll {
+			user, err := repos.Users.GetByID(ctx, userID)
+			if err != nil {
+				return err
+			}
+			return repos.Emails.Suppress(ctx, user.Email, model.SuppressionUnsubscribe)
+		}
+		if !isNotificationType(category) {
+			return fmt.Errorf("%w: %s", ErrUnknownNotificationType, category)
+		}
+		inApp, err := repos.Notifications.IsEnabled(ctx, userID, category)
+		if err != nil {
+			return err
+		}
+		return repos.Notifications.SavePreference(ctx, &model.NotificationPreference{
+			UserID:       userID,
+			Type:         category,
+			InAppEnabled: inApp,
+			EmailEnabled: false,
+		})
+	})
+}
+
+// HandleBounce records a provider bounce or complaint. Hard bounces and
+// complaints suppress the address; soft bounces only mark the send log.
+func (s *emailServiceImpl) HandleBounce(ctx context.Context, email, kind, providerMessageID string) error {
+	return s.uow.Do(ctx, func(repos repository.Repositories) error {
+		if providerMessageID != "" {
+			if err := repos.Emails.MarkBounced(ctx, providerMessageID, kind); err != nil {
+				return err
+			}
+		}
+		switch kind {
+		case "hard_bounce", model.SuppressionBounce:
+			return repos.Emails.Suppress(ctx, email, model.SuppressionBounce)
+		case model.SuppressionComplaint:
+			return repos.Emails.Suppress(ctx, email, model.SuppressionComplaint)
+		}
+		return nil
+	})
+}
+
+func (s *emailServiceImpl) unsubscribeToken(userID uuid.UUID, category string) string {
+	mac := hmac.New(sha256.New, []byte(s.secret))
+	mac.Write([]byte("unsubscribe:" + userID.String() + ":" + category))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
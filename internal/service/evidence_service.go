@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"ping-badge-be/internal/constant"
+	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
+	"ping-badge-be/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// Review decisions.
+const (
+	DecisionApprove        = "approve"
+	DecisionReject         = "reject"
+	DecisionRequestChanges = "request_changes"
+)
+
+type EvidenceService interface {
+	SubmitEvidence(ctx context.Context, participationID uuid.UUID, link string, note *string, actor Actor) (*model.ParticipationEvidence, *model.ActivityParticipation, error)
+	SubmitEvidenceFile(ctx context.Context, participationID uuid.UUID, object *model.StoredObject, note *string, actor Actor) (*model.ParticipationEvidence, *model.ActivityParticipation, error)
+	GetEvidence(ctx context.Context, id uuid.UUID) (*model.ParticipationEvidence, error)
+	ListEvidence(ctx context.Context, participationID uuid.UUID) ([]model.ParticipationEvidence, error)
+	ReviewEvidence(ctx context.Context, id uuid.UUID, decision, comment string, reviewer Actor) (*model.ParticipationEvidence, *model.ActivityParticipation, error)
+	AddComment(ctx context.Context, id, authorID uuid.UUID, body string) (*model.EvidenceComment, error)
+	ReviewQueue(ctx context.Context, filter repository.EvidenceFilter, page pagination.Params) ([]model.ParticipationEvidence, int64, error)
+}
+
+var (
+	ErrInvalidDecision         = errors.New("decision must be approve, reject or request_changes")
+	ErrCommentRequired         = errors.New("a comment is required when rejecting or requesting changes")
+	ErrEvidenceAlreadyReviewed = errors.New("evidence has already been reviewed")
+	ErrInvalidEvidenceURL      = fmt.Errorf("proof URL must be an http or https URL of at most %d characters", maxEvidenceURLLength)
+)
+
+// maxEvidenceURLLength is the size of the columns proof URLs are stored in.
+const maxEvidenceURLLength = 255
+
+type evidenceServiceImpl struct {
+	repo repository.EvidenceRepository
+	uow  repository.UnitOfWork
+}
+
+func NewEvidenceService(repo repository.EvidenceRepository, uow repository.UnitOfWork) EvidenceService {
+	return &evidenceServiceImpl{repo: repo, uow: uow}
+}
+
+// SubmitEvidence adds an evidence item to the participation and puts it in
+// the organization's review queue. The participation moves to evidence
+// submitted unless it is already waiting for review; further items can be
+// added while it waits.
+func (s *evidenceServiceImpl) SubmitEvidence(ctx context.Context, participationID uuid.UUID, link string, note *string, actor Actor) (*model.ParticipationEvidence, *model.ActivityParticipation, error) {
+	if err := checkEvidenceURL(link); err != nil {
+		return nil, nil, err
+	}
+	return s.submit(ctx, participationID, &model.ParticipationEvidence{URL: &link, Note: note}, link, actor)
+}
+
+// checkEvidenceURL accepts absolute http and https links that fit the proof
+// URL columns, so links such as javascript: never reach reviewers.
+func checkEvidenceURL(link string) error {
+	if len(link) > maxEvidenceURLLength {
+		return ErrInvalidEvidenceURL
+	}
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidEvidenceURL
+	}
+	return nil
+}
+
+// SubmitEvidenceFile submits an uploaded file as evidence. The participation's
+// proof URL points at the evidence download route.
+func (s *evidenceServiceImpl) SubmitEvidenceFile(ctx context.Context, participationID uuid.UUID, object *model.StoredObject, note *string, actor Actor) (*model.ParticipationEvidence, *model.ActivityParticipation, error) {
+	evidence := &model.ParticipationEvidence{EvidenceID: uuid.New(), ObjectID: &object.ObjectID, Note: note}
+	return s.submit(ctx, participationID, evidence, EvidenceFilePath(evidence.EvidenceID), actor)
+}
+
+// EvidenceFilePath is the path of the route serving an evidence file.
+func EvidenceFilePath(evidenceID uuid.UUID) string {
+	return constant.APIPrefix + "/evidence/" + evidenceID.String() + "/file"
+}
+
+func (s *evidenceServiceImpl) submit(ctx context.Context, participationID uuid.UUID, evidence *model.ParticipationEvidence, proofURL string, actor Actor) (*model.ParticipationEvidence, *model.ActivityParticipation, error) {
+	var participation *model.ActivityParticipation
+	err := s.uow.Do(ctx, func(repos repository.Repositories) error {
+		var err error
+		participation, err = repos.Participations.FindByID(participationID)
+		if err != nil {
+			return err
+		}
+		activity, err := repos.Activities.FindByID(participation.ActivityID)
+		if err != nil {
+			return err
+		}
+
+		// The latest item stays visible as the participation's proof URL.
+		proof := map[string]interface{}{"proof_of_participation_url": proofURL}
+		if participation.Status == model.ParticipationEvidenceSubmitted {
+			participation, err = repos.Participations.Update(participationID, proof)
+		} else {
+			participation, err = transitionParticipation(ctx, repos, participation, model.ParticipationEvidenceSubmitted, actor, "", proof)
+		}
+		if err != nil {
+			return err
+		}
+
+		evidence.ParticipationID = participationID
+		evidence.ActivityID = activity.ActivityID
+		evidence.OrgID = activity.OrgID
+		evidence.UserID = participation.UserID
+		evidence.Status = model.EvidencePending
+		if err := repos.Evidence.Create(ctx, evidence); err != nil {
+			return err
+		}
+		return repos.Outbox.Append(ctx, evidenceEvent(evidence, model.EventEvidenceSubmitted, ""))
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return evidence, participation, nil
+}
+
+func (s *evidenceServiceImpl) GetEvidence(ctx context.Context, id uuid.UUID) (*model.ParticipationEvidence, error) {
+	evidence, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	items := []model.ParticipationEvidence{*evidence}
+	if err := s.attachComments(ctx, items); err != nil {
+		return nil, err
+	}
+	return &items[0], nil
+}
+
+func (s *evidenceServiceImpl) ListEvidence(ctx context.Context, participationID uuid.UUID) ([]model.ParticipationEvidence, error) {
+	items, err := s.repo.ListByParticipation(ctx, participationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachComments(ctx, items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ReviewEvidence records a reviewer's decision on a pending evidence item.
+// Approval approves and completes the participation and issues its badge.
+// Rejecting or requesting changes rejects the participation once none of its
+// evidence is pending any more; the participant may then submit again.
+func (s *evidenceServiceImpl) ReviewEvidence(ctx context.Context, id uuid.UUID, decision, comment string, reviewer Actor) (*model.ParticipationEvidence, *model.ActivityParticipation, error) {
+	var status, reason string
+	switch decision {
+	case DecisionApprove:
+		status, reason = model.EvidenceApproved, "evidence_approved"
+	case DecisionReject:
+		status, reason = model.EvidenceRejected, "evidence_rejected"
+	case DecisionRequestChanges:
+		status, reason = model.EvidenceChangesRequested, "changes_requested"
+	default:
+		return nil, nil, ErrInvalidDecision
+	}
+	if decision != DecisionApprove && comment == "" {
+		return nil, nil, ErrCommentRequired
+	}
+
+	var evidence *model.ParticipationEvidence
+	var participation *model.ActivityParticipation
+	err := s.uow.Do(ctx, func(repos repository.Repositories) error {
+		var err error
+		evidence, err = repos.Evidence.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if evidence.Status != model.EvidencePending {
+			return ErrEvidenceAlreadyReviewed
+		}
+		if err := repos.Evidence.SetStatus(ctx, id, status, *reviewer.UserID); err != nil {
+			return err
+		}
+		evidence, err = repos.Evidence.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		err = repos.Evidence.AddComment(ctx, &model.EvidenceComment{
+			EvidenceID: id,
+			AuthorID:   *reviewer.UserID,
+			Decision:   &decision,
+			Body:       comment,
+		})
+		if err != nil {
+			return err
+		}
+		if err := repos.Outbox.Append(ctx, evidenceEvent(evidence, model.EventEvidenceReviewed, decision)); err != nil {
+			return err
+		}
+
+		participation, err = repos.Participations.FindByID(evidence.ParticipationID)
+		if err != nil {
+			return err
+		}
+		if decision == DecisionApprove {
+			participation, err = approveParticipation(ctx, repos, participation, reviewer, reason)
+			return err
+		}
+		pending, err := repos.Evidence.CountPending(ctx, participation.ParticipationID)
+		if err != nil || pending > 0 || participation.Status != model.ParticipationEvidenceSubmitted {
+			return err
+		}
+		participation, err = transitionParticipation(ctx, repos, participation, model.ParticipationRejected, reviewer, reason, nil)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return evidence, participation, nil
+}
+
+// approveParticipation approves the participation on the reviewer's behalf,
+// then completes it and issues the badge. Participations already approved or
+// completed are left as they are.
+func approveParticipation(ctx context.Context, repos repository.Repositories, participation *model.ActivityParticipation, reviewer Actor, reason string) (*model.ActivityParticipation, error) {
+	var err error
+	if participation.Status == model.ParticipationCompleted {
+		return participation, nil
+	}
+	if participation.Status != model.ParticipationApproved {
+		participation, err = transitionParticipation(ctx, repos, participation, model.ParticipationApproved, reviewer, reason, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	participation, err = transitionParticipation(ctx, repos, participation, model.ParticipationCompleted, SystemActor(), reason, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := createBadgeForCompletion(ctx, repos, participation); err != nil {
+		return nil, err
+	}
+	return participation, nil
+}
+
+func (s *evidenceServiceImpl) AddComment(ctx context.Context, id, authorID uuid.UUID, body string) (*model.EvidenceComment, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	comment := &model.EvidenceComment{EvidenceID: id, AuthorID: authorID, Body: body}
+	if err := s.repo.AddComment(ctx, comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+func (s *evidenceServiceImpl) ReviewQueue(ctx context.Context, filter repository.EvidenceFilter, page pagination.Params) ([]model.ParticipationEvidence, int64, error) {
+	return s.repo.ListQueue(ctx, filter, page)
+}
+
+func (s *evidenceServiceImpl) attachComments(ctx context.Context, items []model.ParticipationEvidence) error {
+	ids := make([]uuid.UUID, len(items))
+	for i, item := range items {
+		ids[i] = item.EvidenceID
+	}
+	comments, err := s.repo.ListComments(ctx, ids)
+	if err != nil {
+		return err
+	}
+	byEvidence := make(map[uuid.UUID][]model.EvidenceComment)
+	for _, comment := range comments {
+		byEvidence[comment.EvidenceID] = append(byEvidence[comment.EvidenceID], comment)
+	}
+	for i := range items {
+		items[i].Comments = byEvidence[items[i].EvidenceID]
+	}
+	return nil
+}
+
+func evidenceEvent(evidence *model.ParticipationEvidence, eventType, decision string) *model.OutboxEvent {
+	payload := map[string]interface{}{
+		"evidence_id":      evidence.EvidenceID.String(),
+		"participation_id": evidence.ParticipationID.String(),
+		"activity_id":      evidence.ActivityID.String(),
+		"org_id":           evidence.OrgID.String(),
+		"user_id":          evidence.UserID.String(),
+		"status":           evidence.Status,
+	}
+	if decision != "" {
+		payload["decision"] = decision
+	}
+	return newOutboxEvent("evidence", evidence.EvidenceID, eventType, payload)
+}
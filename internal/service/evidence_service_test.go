@@ -0,0 +1,22 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckEvidenceURL(t *testing.T) {
+	assert.NoError(t, checkEvidenceURL("https://example.com/proof.jpg"))
+	assert.NoError(t, checkEvidenceURL("http://example.com/p?id=1"))
+	for _, link := range []string{
+		"javascript:alert(1)",
+		"ftp://example.com/proof.jpg",
+		"/relative/proof.jpg",
+		"https://",
+		"https://example.com/" + strings.Repeat("a", 250),
+	} {
+		assert.ErrorIs(t, checkEvidenceURL(link), ErrInvalidEvidenceURL, link)
+	}
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
+	"ping-badge-be/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type JobService interface {
+	ListJobs(ctx context.Context, status *string, jobType *string, page pagination.Params) ([]model.Job, int64, error)
+	GetJob(ctx context.Context, id uuid.UUID) (*model.Job, error)
+	RetryJob(ctx context.Context, id uuid.UUID) (*model.Job, error)
+}
+
+type jobServiceImpl struct {
+	repo repository.JobRepository
+}
+
+func NewJobService(repo repository.JobRepository) JobService {
+	return &jobServiceImpl{repo: repo}
+}
+
+func (s *jobServiceImpl) ListJobs(ctx context.Context, status *string, jobType *string, page pagination.Params) ([]model.Job, int64, error) {
+	total, err := s.repo.Count(ctx, status, jobType)
+	if err != nil {
+		return nil, 0, err
+	}
+	jobs, err := s.repo.List(ctx, status, jobType, page)
+	if err != nil {
+		return nil, 0, err
+	}
+	return jobs, total, nil
+}
+
+func (s *jobServiceImpl) GetJob(ctx context.Context, id uuid.UUID) (*model.Job, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *jobServiceImpl) RetryJob(ctx context.Context, id uuid.UUID) (*model.Job, error) {
+	return s.repo.Retry(ctx, id)
+}
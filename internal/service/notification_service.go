@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
+	"ping-badge-be/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NotificationService interface {
+	ListNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, page pagination.Params) ([]model.Notification, int64, error)
+	CountUnread(ctx context.Context, userID uuid.UUID) (int64, error)
+	MarkRead(ctx context.Context, userID, id uuid.UUID) error
+	MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error)
+	GetPreferences(ctx context.Context, userID uuid.UUID) ([]model.NotificationPreference, error)
+	UpdatePreferences(ctx context.Context, userID uuid.UUID, updates []NotificationPreferenceUpdate) ([]model.NotificationPreference, error)
+	HandleEvent(ctx context.Context, repos repository.Repositories, event model.OutboxEvent) error
+	QueueActivityReminders(ctx context.Context, window time.Duration) error
+}
+
+var ErrUnknownNotificationType = errors.New("unknown notification type")
+
+// NotificationPreferenceUpdate changes one type's channels. Nil fields keep
+// their current value.
+type NotificationPreferenceUpdate struct {
+	Type         string
+	InAppEnabled *bool
+	EmailEnabled *bool
+}
+
+type notificationServiceImpl struct {
+	repo repository.NotificationRepository
+	uow  repository.UnitOfWork
+}
+
+func NewNotificationService(repo repository.NotificationRepository, uow repository.UnitOfWork) NotificationService {
+	return &notificationServiceImpl{repo: repo, uow: uow}
+}
+
+func (s *notificationServiceImpl) ListNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, page pagination.Params) ([]model.Notification, int64, error) {
+	total, err := s.repo.Count(ctx, userID, unreadOnly)
+	if err != nil {
+		return nil, 0, err
+	}
+	notifications, err := s.repo.List(ctx, userID, unreadOnly, page)
+	if err != nil {
+		return nil, 0, err
+	}
+	return notifications, total, nil
+}
+
+func (s *notificationServiceImpl) CountUnread(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return s.repo.CountUnread(ctx, userID)
+}
+
+func (s *notificationServiceImpl) MarkRead(ctx context.Context, userID, id uuid.UUID) error {
+	return s.repo.MarkRead(ctx, userID, id)
+}
+
+func (s *notificationServiceImpl) MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return s.repo.MarkAllRead(ctx, userID)
+}
+
+// GetPreferences returns a preference for every notification type, filling in
+// the enabled default for types the user never changed.
+func (s *notificationServiceImpl) GetPreferences(ctx context.Context, userID uuid.UUID) ([]model.NotificationPreference, error) {
+	stored, err := s.repo.ListPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	byType := make(map[string]model.NotificationPreference, len(stored))
+	for _, p := range stored {
+		byType[p.Type] = p
+	}
+	preferences := make([]model.NotificationPreference, 0, len(model.NotificationTypes))
+	for _, t := range model.NotificationTypes {
+		p, ok := byType[t]
+		if !ok {
+			p = model.NotificationPreference{UserID: userID, Type: t, InAppEnabled: true, EmailEnabled: true}
+		}
+		preferences = append(preferences, p)
+	}
+	return preferences, nil
+}
+
+func (s *notificationServiceImpl) UpdatePreferences(ctx context.Context, userID uuid.UUID, updates []NotificationPreferenceUpdate) ([]model.NotificationPreference, error) {
+	for _, u := range updates {
+		if !isNotificationType(u.Type) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownNotificationType, u.Type)
+		}
+	}
+	current, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	byType := make(map[string]model.NotificationPreference, len(current))
+	for _, p := range current {
+		byType[p.Type] = p
+	}
+
+	err = s.uow.Do(ctx, func(repos repository.Repositories) error {
+		for _, u := range updates {
+			p := byType[u.Type]
+			if u.InAppEnabled != nil {
+				p.InAppEnabled = *u.InAppEnabled
+			}
+			if u.EmailEnabled != nil {
+				p.EmailEnabled = *u.EmailEnabled
+			}
+			if err := repos.Notifications.SavePreference(ctx, &p); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetPreferences(ctx, userID)
+}
+
+// HandleEvent is the outbox consumer that turns domain events into in-app
+// notifications. It writes through repos so the notification commits together
+// with the delivery record.
+func (s *notificationServiceImpl) HandleEvent(ctx context.Context, repos repository.Repositories, event model.OutboxEvent) error {
+	switch event.EventType {
+	case model.EventBadgeIssued:
+		return s.notifyBadgeIssued(ctx, repos, event)
+	case model.EventParticipationStatusChanged:
+		return s.notifyParticipationStatus(ctx, repos, event)
+	case model.EventActivityStartingSoon:
+		return s.notifyActivityReminder(ctx, repos, event)
+	case model.EventActivityCancelled, model.EventActivityRescheduled:
+		return s.notifyActivityChanged(ctx, repos, event)
+	case model.EventOrganizationAdminAdded:
+		return s.notifyOrganizationInvite(ctx, repos, event)
+	}
+	return nil
+}
+
+// QueueActivityReminders publishes a starting-soon event for every expected
+// participant of activities that start within window. Each activity is
+// reminded once.
+func (s *notificationServiceImpl) QueueActivityReminders(ctx context.Context, window time.Duration) error {
+	now := time.Now()
+	return s.uow.Do(ctx, func(repos repository.Repositories) error {
+		activities, err := repos.Activities.FindPendingReminders(now, now.Add(window))
+		if err != nil {
+			return err
+		}
+		for _, activity := range activities {
+			participations, err := repos.Participations.FindByActivity(activity.ActivityID)
+			if err != nil {
+				return err
+			}
+			for _, p := range remindedParticipations(participations) {
+				err := repos.Outbox.Append(ctx, newOutboxEvent("activity", activity.ActivityID, model.EventActivityStartingSoon, map[string]interface{}{
+					"activity_id":      activity.ActivityID.String(),
+					"participation_id": p.ParticipationID.String(),
+					"user_id":          p.UserID.String(),
+					"start_date":       activity.StartDate.Format(time.RFC3339),
+				}))
+				if err != nil {
+					return err
+				}
+			}
+			if err := repos.Activities.MarkReminderSent(activity.ActivityID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// remindedParticipations keeps the participants expected to attend: those
+// registered or already checked in. Withdrawn, waitlisted and other settled
+// participations are not reminded.
+func remindedParticipations(participations []model.ActivityParticipation) []model.ActivityParticipation {
+	var reminded []model.ActivityParticipation
+	for _, p := range participations {
+		if p.Status == model.ParticipationRegistered || p.Status == model.ParticipationCheckedIn {
+			reminded = append(reminded, p)
+		}
+	}
+	return reminded
+}
+
+func (s *notificationServiceImpl) notifyBadgeIssued(ctx context.Context, repos repository.Repositories, event model.OutboxEvent) error {
+	userID, err := payloadUUID(event, "user_id")
+	if err != nil {
+		return err
+	}
+	badgeDefID, err := payloadUUID(event, "badge_def_id")
+	if err != nil {
+		return err
+	}
+	badge, err := repos.Badges.GetByID(ctx, badgeDefID)
+	if err != nil {
+		return err
+	}
+	return s.notify(ctx, repos, &model.Notification{
+		UserID:  userID,
+		Type:    model.NotificationBadgeIssued,
+		Title:   "You earned a badge",
+		Content: fmt.Sprintf("You have been awarded the %s badge.", badge.BadgeName),
+		Data:    event.Payload,
+	}, "badge_issued:"+payloadString(event, "issued_badge_id"))
+}
+
+func (s *notificationServiceImpl) notifyParticipationStatus(ctx context.Context, repos repository.Repositories, event model.OutboxEvent) error {
+	userID, err := payloadUUID(event, "user_id")
+	if err != nil {
+		return err
+	}
+	activityID, err := payloadUUID(event, "activity_id")
+	if err != nil {
+		return err
+	}
+	activity, err := repos.Activities.FindByID(activityID)
+	if err != nil {
+		return err
+	}
+	title := "Participation updated"
+	status := strings.ToLower(strings.ReplaceAll(payloadString(event, "to_status"), "_", " "))
+	content := fmt.Sprintf("Your participation in %s is now %s.", activity.ActivityName, status)
+	switch payloadString(event, "reason") {
+	case "activity_cancelled":
+		// The cancellation notice already tells the participant.
+		return nil
+	case "waitlist_promotion":
+		title = "You're off the waitlist"
+		content = fmt.Sprintf("A place opened up in %s and you are now registered.", activity.ActivityName)
+	case "changes_requested":
+		title = "Changes requested"
+		content = fmt.Sprintf("A reviewer asked for changes to your evidence for %s.", activity.ActivityName)
+	}
+	return s.notify(ctx, repos, &model.Notification{
+		UserID:  userID,
+		Type:    model.NotificationParticipationStatus,
+		Title:   title,
+		Content: content,
+		Data:    event.Payload,
+	}, "participation_status:"+event.EventID.String())
+}
+
+func (s *notificationServiceImpl) notifyActivityReminder(ctx context.Context, repos repository.Repositories, event model.OutboxEvent) error {
+	userID, err := payloadUUID(event, "user_id")
+	if err != nil {
+		return err
+	}
+	activityID, err := payloadUUID(event, "activity_id")
+	if err != nil {
+		return err
+	}
+	activity, err := repos.Activities.FindByID(activityID)
+	if err != nil {
+		return err
+	}
+	content := fmt.Sprintf("%s is starting soon.", activity.ActivityName)
+	if activity.StartDate != nil {
+		content = fmt.Sprintf("%s starts at %s.", activity.ActivityName, activity.StartDate.Format(time.RFC1123))
+	}
+	return s.notify(ctx, repos, &model.Notification{
+		UserID:  userID,
+		Type:    model.NotificationActivityReminder,
+		Title:   "Upcoming activity",
+		Content: content,
+		Data:    event.Payload,
+	}, "activity_reminder:"+activityID.String()+":"+userID.String()+":"+payloadString(event, "start_date"))
+}
+
+func (s *notificationServiceImpl) notifyActivityChanged(ctx context.Context, repos repository.Repositories, event model.OutboxEvent) error {
+	userID, err := payloadUUID(event, "user_id")
+	if err != nil {
+		return err
+	}
+	activityID, err := payloadUUID(event, "activity_id")
+	if err != nil {
+		return err
+	}
+	activity, err := repos.Activities.FindByID(activityID)
+	if err != nil {
+		return err
+	}
+	title := "Activity cancelled"
+	content := fmt.Sprintf("%s has been cancelled.", activity.ActivityName)
+	if event.EventType == model.EventActivityRescheduled {
+		title = "Activity rescheduled"
+		content = fmt.Sprintf("%s has been rescheduled.", activity.ActivityName)
+		if activity.StartDate != nil {
+			content = fmt.Sprintf("%s has been rescheduled to %s.", activity.ActivityName, activity.StartDate.Format(time.RFC1123))
+		}
+	}
+	if reason := payloadString(event, "reason"); reason != "" {
+		content += " Reason: " + reason
+	}
+	return s.notify(ctx, repos, &model.Notification{
+		UserID:  userID,
+		Type:    model.NotificationActivityChanged,
+		Title:   title,
+		Content: content,
+		Data:    event.Payload,
+	}, "activity_changed:"+event.EventID.String())
+}
+
+func (s *notificationServiceImpl) notifyOrganizationInvite(ctx context.Context, repos repository.Repositories, event model.OutboxEvent) error {
+	userID, err := payloadUUID(event, "user_id")
+	if err != nil {
+		return err
+	}
+	orgID, err := payloadUUID(event, "org_id")
+	if err != nil {
+		return err
+	}
+	org, err := repos.Organizations.GetByID(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	return s.notify(ctx, repos, &model.Notification{
+		UserID:  userID,
+		Type:    model.NotificationOrganizationInvite,
+		Title:   "Organization invitation",
+		Content: fmt.Sprintf("You have been added to %s as %s.", org.OrgName, strings.ToLower(payloadString(event, "role"))),
+		Data:    event.Payload,
+	}, "organization_invite:"+payloadString(event, "admin_id"))
+}
+
+// notify stores the notification unless the user opted out of its type.
+func (s *notificationServiceImpl) notify(ctx context.Context, repos repository.Repositories, notification *model.Notification, dedupeKey string) error {
+	enabled, err := repos.Notifications.IsEnabled(ctx, notification.UserID, notification.Type)
+	if err != nil || !enabled {
+		return err
+	}
+	notification.DedupeKey = &dedupeKey
+	return repos.Notifications.Create(ctx, notification)
+}
+
+func isNotificationType(t string) bool {
+	for _, known := range model.NotificationTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
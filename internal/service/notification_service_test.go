@@ -0,0 +1,20 @@
+package service
+
+import (
+	"ping-badge-be/internal/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemindedParticipations(t *testing.T) {
+	participations := []model.ActivityParticipation{
+		{Status: model.ParticipationRegistered},
+		{Status: model.ParticipationWithdrawn},
+		{Status: model.ParticipationWaitlisted},
+		{Status: model.ParticipationCheckedIn},
+		{Status: model.ParticipationNoShow},
+	}
+	reminded := remindedParticipations(participations)
+	assert.Equal(t, []model.ActivityParticipation{participations[0], participations[3]}, reminded)
+}
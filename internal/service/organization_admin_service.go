@@ -2,31 +2,57 @@ package service
 
 import (
 	"context"
+	"errors"
 	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
 	"ping-badge-be/internal/repository"
+	"strings"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type OrganizationAdminService interface {
 	CreateAdmin(ctx context.Context, admin *model.OrganizationAdmin) error
 	GetAdmin(ctx context.Context, id uuid.UUID) (*model.OrganizationAdmin, error)
-	ListAdmins(ctx context.Context, offset, limit int) ([]model.OrganizationAdmin, error)
+	ListAdmins(ctx context.Context, orgID uuid.UUID, page pagination.Params) ([]model.OrganizationAdmin, int64, error)
 	UpdateAdmin(ctx context.Context, admin *model.OrganizationAdmin) error
 	DeleteAdmin(ctx context.Context, id uuid.UUID) error
+	IsStaff(ctx context.Context, orgID, userID uuid.UUID) (bool, error)
+	CanReview(ctx context.Context, orgID, userID uuid.UUID) (bool, error)
+	IsActivityStaff(ctx context.Context, activity *model.Activity, userID uuid.UUID, permission string) (bool, error)
 }
 
+var ErrInvalidOrgRole = errors.New("role must be owner, admin or reviewer")
+
 type organizationAdminServiceImpl struct {
-	repo repository.OrganizationAdminRepository
+	repo       repository.OrganizationAdminRepository
+	orgRepo    *repository.OrganizationRepository
+	coHostRepo repository.ActivityCoHostRepository
+	uow        repository.UnitOfWork
 }
 
-func NewOrganizationAdminService(repo repository.OrganizationAdminRepository) OrganizationAdminService {
-	return &organizationAdminServiceImpl{repo: repo}
+func NewOrganizationAdminService(repo repository.OrganizationAdminRepository, orgRepo *repository.OrganizationRepository, coHostRepo repository.ActivityCoHostRepository, uow repository.UnitOfWork) OrganizationAdminService {
+	return &organizationAdminServiceImpl{repo: repo, orgRepo: orgRepo, coHostRepo: coHostRepo, uow: uow}
 }
 
+// CreateAdmin adds the user to the organization with admin.Role, which is
+// stored in lower case.
 func (s *organizationAdminServiceImpl) CreateAdmin(ctx context.Context, admin *model.OrganizationAdmin) error {
-	// Add business logic, validation, authorization here
-	return s.repo.Create(ctx, admin)
+	if err := normalizeOrgRole(admin); err != nil {
+		return err
+	}
+	return s.uow.Do(ctx, func(repos repository.Repositories) error {
+		if err := repos.OrganizationAdmins.Create(ctx, admin); err != nil {
+			return err
+		}
+		return repos.Outbox.Append(ctx, newOutboxEvent("organization_admin", admin.AdminID, model.EventOrganizationAdminAdded, map[string]interface{}{
+			"admin_id": admin.AdminID.String(),
+			"org_id":   admin.OrgID.String(),
+			"user_id":  admin.UserID.String(),
+			"role":     admin.Role,
+		}))
+	})
 }
 
 func (s *organizationAdminServiceImpl) GetAdmin(ctx context.Context, id uuid.UUID) (*model.OrganizationAdmin, error) {
@@ -34,13 +60,23 @@ func (s *organizationAdminServiceImpl) GetAdmin(ctx context.Context, id uuid.UUI
 	return s.repo.GetByID(ctx, id)
 }
 
-func (s *organizationAdminServiceImpl) ListAdmins(ctx context.Context, offset, limit int) ([]model.OrganizationAdmin, error) {
-	// Add business logic, validation, authorization here
-	return s.repo.List(ctx, offset, limit)
+func (s *organizationAdminServiceImpl) ListAdmins(ctx context.Context, orgID uuid.UUID, page pagination.Params) ([]model.OrganizationAdmin, int64, error) {
+	total, err := s.repo.Count(ctx, orgID)
+	if err != nil {
+		return nil, 0, err
+	}
+	admins, err := s.repo.List(ctx, orgID, page)
+	if err != nil {
+		return nil, 0, err
+	}
+	return admins, total, nil
 }
 
+// UpdateAdmin stores the member's role, in lower case.
 func (s *organizationAdminServiceImpl) UpdateAdmin(ctx context.Context, admin *model.OrganizationAdmin) error {
-	// Add business logic, validation, authorization here
+	if err := normalizeOrgRole(admin); err != nil {
+		return err
+	}
 	return s.repo.Update(ctx, admin)
 }
 
@@ -48,3 +84,74 @@ func (s *organizationAdminServiceImpl) DeleteAdmin(ctx context.Context, id uuid.
 	// Add business logic, validation, authorization here
 	return s.repo.Delete(ctx, id)
 }
+
+// IsStaff reports whether the user owns the organization or is one of its
+// admins. Reviewers are not staff.
+func (s *organizationAdminServiceImpl) IsStaff(ctx context.Context, orgID, userID uuid.UUID) (bool, error) {
+	role, err := s.memberRole(ctx, orgID, userID)
+	if err != nil {
+		return false, err
+	}
+	return role != "" && role != model.OrgRoleReviewer, nil
+}
+
+// CanReview reports whether the user may review evidence submitted to the
+// organization: its staff and designated reviewers.
+func (s *organizationAdminServiceImpl) CanReview(ctx context.Context, orgID, userID uuid.UUID) (bool, error) {
+	role, err := s.memberRole(ctx, orgID, userID)
+	return role != "", err
+}
+
+// IsActivityStaff reports whether the user is staff of the activity's host
+// organization, or of an accepted co-host granted permission. An empty
+// permission admits host staff only.
+func (s *organizationAdminServiceImpl) IsActivityStaff(ctx context.Context, activity *model.Activity, userID uuid.UUID, permission string) (bool, error) {
+	staff, err := s.IsStaff(ctx, activity.OrgID, userID)
+	if err != nil || staff || permission == "" {
+		return staff, err
+	}
+	coHosts, err := s.coHostRepo.ListByActivity(ctx, activity.ActivityID, true)
+	if err != nil {
+		return false, err
+	}
+	for _, host := range coHosts {
+		if !host.Allows(permission) {
+			continue
+		}
+		staff, err := s.IsStaff(ctx, host.OrgID, userID)
+		if err != nil || staff {
+			return staff, err
+		}
+	}
+	return false, nil
+}
+
+// memberRole returns the user's role in the organization, "owner" for its
+// owner and "" for non-members.
+func (s *organizationAdminServiceImpl) memberRole(ctx context.Context, orgID, userID uuid.UUID) (string, error) {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		return "", err
+	}
+	if org.UserIDOwner == userID {
+		return model.OrgRoleOwner, nil
+	}
+	admin, err := s.repo.FindByOrgAndUser(ctx, orgID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.ToLower(admin.Role), nil
+}
+
+func normalizeOrgRole(admin *model.OrganizationAdmin) error {
+	admin.Role = strings.ToLower(strings.TrimSpace(admin.Role))
+	for _, role := range model.OrgRoles {
+		if admin.Role == role {
+			return nil
+		}
+	}
+	return ErrInvalidOrgRole
+}
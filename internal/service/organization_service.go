@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"ping-badge-be/internal/listquery"
 	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
 	"ping-badge-be/internal/repository"
 
 	"github.com/google/uuid"
@@ -24,8 +26,16 @@ func (s *OrganizationService) GetOrganization(ctx context.Context, id uuid.UUID)
 	return s.repo.GetByID(ctx, id)
 }
 
-func (s *OrganizationService) ListOrganizations(ctx context.Context, offset, limit int, userID *uuid.UUID) ([]model.Organization, error) {
-	return s.repo.List(ctx, offset, limit, userID)
+func (s *OrganizationService) ListOrganizations(ctx context.Context, criteria listquery.Query, page pagination.Params, userID *uuid.UUID) ([]model.Organization, int64, error) {
+	total, err := s.repo.Count(ctx, criteria, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	orgs, err := s.repo.List(ctx, criteria, page, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return orgs, total, nil
 }
 
 func (s *OrganizationService) UpdateOrganization(ctx context.Context, org *model.Organization) error {
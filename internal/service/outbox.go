@@ -0,0 +1,35 @@
+package service
+
+import (
+	"fmt"
+	"ping-badge-be/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// newOutboxEvent builds an event to be appended through repos.Outbox inside
+// the same unit of work as the state change it describes.
+func newOutboxEvent(aggregateType string, aggregateID uuid.UUID, eventType string, payload map[string]interface{}) *model.OutboxEvent {
+	return &model.OutboxEvent{
+		EventID:       uuid.New(),
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		Payload:       payload,
+	}
+}
+
+// payloadUUID reads a UUID written by newOutboxEvent back out of the payload.
+func payloadUUID(event model.OutboxEvent, key string) (uuid.UUID, error) {
+	value, _ := event.Payload[key].(string)
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("event %s: invalid %s in payload", event.EventID, key)
+	}
+	return id, nil
+}
+
+func payloadString(event model.OutboxEvent, key string) string {
+	value, _ := event.Payload[key].(string)
+	return value
+}
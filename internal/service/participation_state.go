@@ -0,0 +1,98 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"ping-badge-be/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// Actor types that may change a participation's status.
+const (
+	ActorParticipant = "participant"
+	ActorStaff       = "staff"
+	ActorSystem      = "system"
+)
+
+var ErrIllegalTransition = errors.New("illegal status transition")
+
+// Actor identifies who requested a status change. UserID is nil for the system.
+type Actor struct {
+	Type   string
+	UserID *uuid.UUID
+}
+
+func SystemActor() Actor {
+	return Actor{Type: ActorSystem}
+}
+
+func UserActor(actorType string, userID uuid.UUID) Actor {
+	return Actor{Type: actorType, UserID: &userID}
+}
+
+// participationTransitions maps each status to the statuses it may move to
+// and the actors allowed to make that move. Completed and withdrawn are final.
+var participationTransitions = map[string]map[string][]string{
+	model.ParticipationRegistered: {
+		model.ParticipationWaitlisted:        {ActorStaff},
+		model.ParticipationCheckedIn:         {ActorStaff, ActorSystem},
+		model.ParticipationEvidenceSubmitted: {ActorParticipant, ActorStaff},
+		model.ParticipationCompleted:         {ActorStaff, ActorSystem},
+		model.ParticipationNoShow:            {ActorStaff, ActorSystem},
+		model.ParticipationWithdrawn:         {ActorParticipant, ActorStaff},
+	},
+	model.ParticipationWaitlisted: {
+		model.ParticipationRegistered: {ActorStaff, ActorSystem},
+		model.ParticipationWithdrawn:  {ActorParticipant, ActorStaff},
+	},
+	model.ParticipationCheckedIn: {
+		model.ParticipationEvidenceSubmitted: {ActorParticipant, ActorStaff},
+		model.ParticipationApproved:          {ActorStaff},
+		model.ParticipationRejected:          {ActorStaff},
+		model.ParticipationCompleted:         {ActorStaff, ActorSystem},
+		model.ParticipationNoShow:            {ActorStaff},
+	},
+	model.ParticipationEvidenceSubmitted: {
+		model.ParticipationApproved:  {ActorStaff},
+		model.ParticipationRejected:  {ActorStaff},
+		model.ParticipationCompleted: {ActorSystem},
+	},
+	model.ParticipationApproved: {
+		model.ParticipationCompleted: {ActorStaff, ActorSystem},
+		model.ParticipationRejected:  {ActorStaff},
+	},
+	model.ParticipationRejected: {
+		model.ParticipationEvidenceSubmitted: {ActorParticipant, ActorStaff},
+		model.ParticipationApproved:          {ActorStaff},
+	},
+	model.ParticipationNoShow: {
+		model.ParticipationCheckedIn: {ActorStaff},
+	},
+	model.ParticipationCompleted: {},
+	model.ParticipationWithdrawn: {},
+}
+
+// IsParticipationStatus reports whether status is a known status.
+func IsParticipationStatus(status string) bool {
+	_, ok := participationTransitions[status]
+	return ok
+}
+
+// CanTransition reports whether actorType may move a participation from one
+// status to another.
+func CanTransition(from, to, actorType string) bool {
+	for _, allowed := range participationTransitions[from][to] {
+		if allowed == actorType {
+			return true
+		}
+	}
+	return false
+}
+
+func checkTransition(from, to string, actor Actor) error {
+	if !CanTransition(from, to, actor.Type) {
+		return fmt.Errorf("%w: %s cannot move participation from %s to %s", ErrIllegalTransition, actor.Type, from, to)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"ping-badge-be/internal/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanTransition(t *testing.T) {
+	cases := []struct {
+		from, to, actor string
+		want            bool
+	}{
+		{model.ParticipationRegistered, model.ParticipationWithdrawn, ActorParticipant, true},
+		{model.ParticipationWaitlisted, model.ParticipationRegistered, ActorSystem, true},
+		{model.ParticipationWaitlisted, model.ParticipationRegistered, ActorParticipant, false},
+		{model.ParticipationCheckedIn, model.ParticipationEvidenceSubmitted, ActorParticipant, true},
+		{model.ParticipationEvidenceSubmitted, model.ParticipationApproved, ActorStaff, true},
+		{model.ParticipationEvidenceSubmitted, model.ParticipationApproved, ActorParticipant, false},
+		{model.ParticipationRejected, model.ParticipationEvidenceSubmitted, ActorParticipant, true},
+		{model.ParticipationApproved, model.ParticipationCompleted, ActorStaff, true},
+		{model.ParticipationCompleted, model.ParticipationWithdrawn, ActorStaff, false},
+		{model.ParticipationWithdrawn, model.ParticipationRegistered, ActorStaff, false},
+		{model.ParticipationRegistered, "UNKNOWN", ActorStaff, false},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, CanTransition(tc.from, tc.to, tc.actor), "%s -> %s by %s", tc.from, tc.to, tc.actor)
+	}
+}
+
+func TestEveryStatusHasTransitions(t *testing.T) {
+	for _, status := range model.ParticipationStatuses {
+		assert.True(t, IsParticipationStatus(status), status)
+	}
+	for from, targets := range participationTransitions {
+		for to := range targets {
+			assert.True(t, IsParticipationStatus(to), "%s -> %s", from, to)
+		}
+	}
+}
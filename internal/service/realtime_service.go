@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"ping-badge-be/internal/constant"
+	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/realtime"
+	"ping-badge-be/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type RealtimeService interface {
+	Authorize(ctx context.Context, userID uuid.UUID, role, topic string) error
+	HandleEvent(ctx context.Context, repos repository.Repositories, event model.OutboxEvent) error
+}
+
+var ErrTopicForbidden = errors.New("not allowed to subscribe to topic")
+
+type realtimeServiceImpl struct {
+	activityRepo    repository.ActivityRepository
+	orgAdminService OrganizationAdminService
+}
+
+func NewRealtimeService(activityRepo repository.ActivityRepository, orgAdminService OrganizationAdminService) RealtimeService {
+	return &realtimeServiceImpl{activityRepo: activityRepo, orgAdminService: orgAdminService}
+}
+
+// Authorize checks that the user may subscribe to topic. Users may follow
+// their own topics; an activity's participations are visible to the staff of
+// its host and of co-hosts managing participations. Platform admins may
+// subscribe to anything.
+func (s *realtimeServiceImpl) Authorize(ctx context.Context, userID uuid.UUID, role, topic string) error {
+	kind, id, err := realtime.ParseTopic(topic)
+	if err != nil {
+		return err
+	}
+	if role == constant.RoleAdmin {
+		return nil
+	}
+	switch kind {
+	case realtime.KindUserParticipations, realtime.KindUserBadges:
+		if id != userID {
+			return ErrTopicForbidden
+		}
+		return nil
+	case realtime.KindActivityParticipations:
+		activity, err := s.activityRepo.FindByID(id)
+		if err != nil {
+			return ErrTopicForbidden
+		}
+		staff, err := s.orgAdminService.IsActivityStaff(ctx, activity, userID, model.CoHostManageParticipations)
+		if err != nil {
+			return err
+		}
+		if !staff {
+			return ErrTopicForbidden
+		}
+		return nil
+	}
+	return realtime.ErrInvalidTopic
+}
+
+// HandleEvent is the outbox consumer that publishes participation and badge
+// events to their topics with pg_notify. The notification is sent when the
+// delivery transaction commits and reaches every API instance.
+func (s *realtimeServiceImpl) HandleEvent(ctx context.Context, repos repository.Repositories, event model.OutboxEvent) error {
+	var topics []string
+	switch event.EventType {
+	case model.EventParticipationCreated, model.EventParticipationUpdated, model.EventParticipationStatusChanged:
+		activityID, err := payloadUUID(event, "activity_id")
+		if err != nil {
+			return err
+		}
+		userID, err := payloadUUID(event, "user_id")
+		if err != nil {
+			return err
+		}
+		topics = []string{realtime.ActivityParticipationsTopic(activityID), realtime.UserParticipationsTopic(userID)}
+	case model.EventBadgeIssued:
+		userID, err := payloadUUID(event, "user_id")
+		if err != nil {
+			return err
+		}
+		topics = []string{realtime.UserBadgesTopic(userID)}
+	default:
+		return nil
+	}
+
+	data, err := json.Marshal(event.Payload)
+	if err != nil {
+		return err
+	}
+	for _, topic := range topics {
+		payload, err := json.Marshal(realtime.Message{
+			ID:    event.EventID.String(),
+			Topic: topic,
+			Event: event.EventType,
+			Data:  data,
+		})
+		if err != nil {
+			return err
+		}
+		if err := repos.Realtime.Notify(ctx, realtime.Channel, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/repository"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RosterService interface {
+	Export(ctx context.Context, activityID uuid.UUID) ([][]string, error)
+	Import(ctx context.Context, activityID uuid.UUID, rows [][]string, dryRun bool, actor Actor) (*RosterImportReport, error)
+}
+
+// maxRosterRows caps the rows of an imported roster, header excluded.
+const maxRosterRows = 2000
+
+var (
+	ErrRosterEmpty         = errors.New("roster has no rows")
+	ErrRosterNoEmailColumn = errors.New("roster needs an email column")
+	ErrRosterTooLarge      = fmt.Errorf("roster has more than %d rows", maxRosterRows)
+
+	// errRosterDryRun rolls back a dry-run import.
+	errRosterDryRun = errors.New("roster dry run")
+)
+
+// RosterExportHeader lists the columns of an exported roster.
+var RosterExportHeader = []string{"participation_id", "email", "username", "full_name", "status", "waitlist_position", "registered_at"}
+
+// RosterImportRow is the outcome of one roster row. Row is the spreadsheet
+// row number, counting the header as row 1.
+type RosterImportRow struct {
+	Row             int        `json:"row"`
+	Email           string     `json:"email"`
+	Status          string     `json:"status,omitempty"`
+	ParticipationID *uuid.UUID `json:"participation_id,omitempty"`
+	Error           string     `json:"error,omitempty"`
+}
+
+// RosterImportReport summarizes an import. A dry run reports what would
+// happen without registering anyone.
+type RosterImportReport struct {
+	DryRun     bool              `json:"dry_run"`
+	Total      int               `json:"total"`
+	Registered int               `json:"registered"`
+	Waitlisted int               `json:"waitlisted"`
+	Failed     int               `json:"failed"`
+	Rows       []RosterImportRow `json:"rows"`
+}
+
+type rosterServiceImpl struct {
+	participationRepo repository.ActivityParticipationRepository
+	userRepo          repository.UserRepository
+	uow               repository.UnitOfWork
+}
+
+func NewRosterService(participationRepo repository.ActivityParticipationRepository, userRepo repository.UserRepository, uow repository.UnitOfWork) RosterService {
+	return &rosterServiceImpl{participationRepo: participationRepo, userRepo: userRepo, uow: uow}
+}
+
+// Export returns the activity's participants with their statuses, header
+// first, in registration order.
+func (s *rosterServiceImpl) Export(ctx context.Context, activityID uuid.UUID) ([][]string, error) {
+	participations, err := s.participationRepo.FindByActivity(activityID)
+	if err != nil {
+		return nil, err
+	}
+	userIDs := make([]uuid.UUID, 0, len(participations))
+	for _, p := range participations {
+		userIDs = append(userIDs, p.UserID)
+	}
+	users, err := s.userRepo.FindByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]model.User, len(users))
+	for _, user := range users {
+		byID[user.UserID] = user
+	}
+
+	rows := [][]string{RosterExportHeader}
+	for _, p := range participations {
+		user := byID[p.UserID]
+		row := []string{p.ParticipationID.String(), user.Email, user.Username, "", p.Status, "", p.CreatedAt.UTC().Format(time.RFC3339)}
+		if user.FullName != nil {
+			row[3] = *user.FullName
+		}
+		if p.WaitlistPosition != nil {
+			row[5] = strconv.Itoa(*p.WaitlistPosition)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// Import registers the users listed by email in the roster's email column on
+// behalf of actor, in row order, so rows past the activity's capacity are
+// waitlisted. Rows that cannot be registered are reported and skipped; blank
+// rows are ignored. The whole import commits or rolls back together.
+func (s *rosterServiceImpl) Import(ctx context.Context, activityID uuid.UUID, rows [][]string, dryRun bool, actor Actor) (*RosterImportReport, error) {
+	if len(rows) < 2 {
+		return nil, ErrRosterEmpty
+	}
+	if len(rows)-1 > maxRosterRows {
+		return nil, ErrRosterTooLarge
+	}
+	emailColumn := -1
+	for i, name := range rows[0] {
+		if strings.EqualFold(strings.TrimSpace(name), "email") {
+			emailColumn = i
+			break
+		}
+	}
+	if emailColumn < 0 {
+		return nil, ErrRosterNoEmailColumn
+	}
+
+	report := &RosterImportReport{DryRun: dryRun, Rows: []RosterImportRow{}}
+	var emails []string
+	for i, row := range rows[1:] {
+		if emailColumn >= len(row) || strings.TrimSpace(row[emailColumn]) == "" {
+			continue
+		}
+		email := strings.TrimSpace(row[emailColumn])
+		report.Rows = append(report.Rows, RosterImportRow{Row: i + 2, Email: email})
+		emails = append(emails, email)
+	}
+	report.Total = len(report.Rows)
+
+	users, err := s.userRepo.FindByEmails(ctx, emails)
+	if err != nil {
+		return nil, err
+	}
+	byEmail := make(map[string]model.User, len(users))
+	for _, user := range users {
+		byEmail[strings.ToLower(user.Email)] = user
+	}
+
+	err = s.uow.Do(ctx, func(repos repository.Repositories) error {
+		activity, err := repos.Activities.FindByIDForUpdate(activityID)
+		if err != nil {
+			return err
+		}
+		if err := checkActivityOpen(activity); err != nil {
+			return err
+		}
+		seen := make(map[string]bool, len(report.Rows))
+		for i := range report.Rows {
+			row := &report.Rows[i]
+			key := strings.ToLower(row.Email)
+			user, found := byEmail[key]
+			switch {
+			case !strings.Contains(row.Email, "@"):
+				row.Error = "invalid email"
+			case seen[key]:
+				row.Error = "duplicate of an earlier row"
+			case !found:
+				row.Error = "no user with this email"
+			}
+			seen[key] = true
+			if row.Error != "" {
+				continue
+			}
+			participation := &model.ActivityParticipation{
+				ActivityID: activityID,
+				UserID:     user.UserID,
+				CreatedAt:  time.Now(),
+			}
+			err := createParticipation(ctx, repos, participation, actor)
+			if isRosterRowError(err) {
+				row.Error = err.Error()
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			row.Status = participation.Status
+			if !dryRun {
+				row.ParticipationID = &participation.ParticipationID
+			}
+		}
+		if dryRun {
+			return errRosterDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errRosterDryRun) {
+		return nil, err
+	}
+
+	for _, row := range report.Rows {
+		switch {
+		case row.Error != "":
+			report.Failed++
+		case row.Status == model.ParticipationWaitlisted:
+			report.Waitlisted++
+		default:
+			report.Registered++
+		}
+	}
+	return report, nil
+}
+
+// isRosterRowError reports whether err only rejects the row. These errors are
+// returned before anything is written, so the import can go on.
+func isRosterRowError(err error) bool {
+	return errors.Is(err, ErrAlreadyJoined) ||
+		errors.Is(err, ErrRegistrationNotOpen) ||
+		errors.Is(err, ErrRegistrationClosed)
+}
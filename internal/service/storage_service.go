@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"ping-badge-be/internal/badgeimage"
+	"ping-badge-be/internal/constant"
+	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/repository"
+	"ping-badge-be/internal/storage"
+	"strings"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/google/uuid"
+)
+
+// UploadPolicy limits what may be stored for a purpose.
+type UploadPolicy struct {
+	MaxBytes     int64
+	ContentTypes []string
+	Public       bool
+}
+
+// StorageService validates, scans and stores uploads, and records them as
+// StoredObjects.
+type StorageService interface {
+	Store(ctx context.Context, purpose string, ownerID uuid.UUID, filename string, body io.Reader) (*model.StoredObject, error)
+	GetObject(ctx context.Context, id uuid.UUID) (*model.StoredObject, error)
+	GetObjectByKey(ctx context.Context, key string) (*model.StoredObject, error)
+	Open(ctx context.Context, object *model.StoredObject) (io.ReadCloser, error)
+	SignedURL(ctx context.Context, object *model.StoredObject, ttl time.Duration) (string, error)
+	// PublicURL is the permanent address of a public object, which redirects
+	// to a fresh signed URL.
+	PublicURL(object *model.StoredObject) string
+}
+
+var (
+	ErrFileTooLarge        = errors.New("file is too large")
+	ErrUnsupportedFileType = errors.New("file type is not allowed")
+	ErrEmptyFile           = errors.New("file is empty")
+	ErrUnknownPurpose      = errors.New("unknown upload purpose")
+)
+
+// DefaultUploadPolicies returns the policies for evidence, badge images and
+// organization logos, with maxBytes as the evidence size limit.
+func DefaultUploadPolicies(maxBytes int64) map[string]UploadPolicy {
+	images := []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+	return map[string]UploadPolicy{
+		model.ObjectEvidence: {
+			MaxBytes:     maxBytes,
+			ContentTypes: append(append([]string{}, images...), "application/pdf"),
+		},
+		model.ObjectBadgeImage: {
+			MaxBytes:     badgeimage.MaxFileBytes,
+			ContentTypes: []string{badgeimage.ContentTypePNG, badgeimage.ContentTypeSVG},
+			Public:       true,
+		},
+		model.ObjectOrgLogo: {MaxBytes: 2 << 20, ContentTypes: []string{"image/jpeg", "image/png", "image/webp"}, Public: true},
+	}
+}
+
+type storageServiceImpl struct {
+	store    storage.Storage
+	scanner  storage.Scanner
+	repo     repository.StoredObjectRepository
+	policies map[string]UploadPolicy
+	baseURL  string
+}
+
+func NewStorageService(store storage.Storage, scanner storage.Scanner, repo repository.StoredObjectRepository, policies map[string]UploadPolicy, baseURL string) StorageService {
+	if scanner == nil {
+		scanner = storage.NopScanner{}
+	}
+	return &storageServiceImpl{store: store, scanner: scanner, repo: repo, policies: policies, baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+// Store spools the upload to a temporary file to enforce the size limit,
+// detect its type from the content and hash it, then scans it and writes it
+// to the backend under purpose/yyyy/mm/<uuid><ext>.
+func (s *storageServiceImpl) Store(ctx context.Context, purpose string, ownerID uuid.UUID, filename string, body io.Reader) (*model.StoredObject, error) {
+	policy, ok := s.policies[purpose]
+	if !ok {
+		return nil, ErrUnknownPurpose
+	}
+
+	tmp, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(body, policy.MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if size == 0 {
+		return nil, ErrEmptyFile
+	}
+	if size > policy.MaxBytes {
+		return nil, ErrFileTooLarge
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	detected, err := mimetype.DetectReader(tmp)
+	if err != nil {
+		return nil, err
+	}
+	contentType := allowedType(detected, policy.ContentTypes)
+	if contentType == "" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFileType, detected.String())
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if err := s.scanner.Scan(ctx, tmp); err != nil {
+		return nil, err
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	object := &model.StoredObject{
+		ObjectID:    uuid.New(),
+		Purpose:     purpose,
+		ContentType: contentType,
+		Size:        size,
+		SHA256:      hex.EncodeToString(hash.Sum(nil)),
+		OwnerID:     ownerID,
+		Public:      policy.Public,
+	}
+	object.Key = path.Join(purpose, now.Format("2006/01"), object.ObjectID.String()+detected.Extension())
+	if name := strings.TrimSpace(path.Base(strings.ReplaceAll(filename, "\\", "/"))); name != "" && name != "." && name != "/" {
+		if len(name) > 255 {
+			name = name[:255]
+		}
+		object.OriginalName = &name
+	}
+
+	if err := s.store.Put(ctx, object.Key, tmp, size, contentType); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, object); err != nil {
+		_ = s.store.Delete(ctx, object.Key)
+		return nil, err
+	}
+	return object, nil
+}
+
+func (s *storageServiceImpl) GetObject(ctx context.Context, id uuid.UUID) (*model.StoredObject, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *storageServiceImpl) GetObjectByKey(ctx context.Context, key string) (*model.StoredObject, error) {
+	return s.repo.GetByKey(ctx, key)
+}
+
+func (s *storageServiceImpl) Open(ctx context.Context, object *model.StoredObject) (io.ReadCloser, error) {
+	return s.store.Get(ctx, object.Key)
+}
+
+func (s *storageServiceImpl) SignedURL(ctx context.Context, object *model.StoredObject, ttl time.Duration) (string, error) {
+	return s.store.SignedURL(ctx, object.Key, ttl)
+}
+
+func (s *storageServiceImpl) PublicURL(object *model.StoredObject) string {
+	return s.baseURL + constant.APIPrefix + "/files/" + object.ObjectID.String()
+}
+
+// allowedType returns the allowed content type the detected type is, or is
+// a subtype of, or "" if there is none.
+func allowedType(detected *mimetype.MIME, allowed []string) string {
+	for m := detected; m != nil; m = m.Parent() {
+		for _, contentType := range allowed {
+			if m.Is(contentType) {
+				return contentType
+			}
+		}
+	}
+	return ""
+}
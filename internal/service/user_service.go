@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"ping-badge-be/internal/listquery"
 	"ping-badge-be/internal/model"
+	"ping-badge-be/internal/pagination"
 	"ping-badge-be/internal/repository"
 
 	"github.com/google/uuid"
@@ -11,7 +13,7 @@ import (
 type UserService interface {
 	CreateUser(ctx context.Context, user *model.User) error
 	GetUser(ctx context.Context, id uuid.UUID) (*model.User, error)
-	ListUsers(ctx context.Context, offset, limit int) ([]model.User, error)
+	ListUsers(ctx context.Context, criteria listquery.Query, page pagination.Params) ([]model.User, int64, error)
 	UpdateUser(ctx context.Context, user *model.User) error
 	DeleteUser(ctx context.Context, id uuid.UUID) error
 }
@@ -34,9 +36,17 @@ func (s *userServiceImpl) GetUser(ctx context.Context, id uuid.UUID) (*model.Use
 	return s.repo.GetByID(ctx, id)
 }
 
-func (s *userServiceImpl) ListUsers(ctx context.Context, offset, limit int) ([]model.User, error) {
+func (s *userServiceImpl) ListUsers(ctx context.Context, criteria listquery.Query, page pagination.Params) ([]model.User, int64, error) {
 	// Add business logic, validation, authorization here
-	return s.repo.List(ctx, offset, limit)
+	total, err := s.repo.Count(ctx, criteria)
+	if err != nil {
+		return nil, 0, err
+	}
+	users, err := s.repo.List(ctx, criteria, page)
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
 }
 
 func (s *userServiceImpl) UpdateUser(ctx context.Context, user *model.User) error {
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+var ErrInfected = errors.New("file failed virus scan")
+
+// Scanner inspects an upload before it is stored. It returns an error
+// wrapping ErrInfected when the content is malicious.
+type Scanner interface {
+	Scan(ctx context.Context, r io.Reader) error
+}
+
+// NopScanner accepts everything; it is used when no scanner is configured.
+type NopScanner struct{}
+
+func (NopScanner) Scan(ctx context.Context, r io.Reader) error { return nil }
+
+// ClamdScanner streams content to a clamd daemon with the INSTREAM command.
+type ClamdScanner struct {
+	Address string
+	Timeout time.Duration
+}
+
+func NewClamdScanner(address string) *ClamdScanner {
+	return &ClamdScanner{Address: address, Timeout: time.Minute}
+}
+
+func (s *ClamdScanner) Scan(ctx context.Context, r io.Reader) error {
+	network := "tcp"
+	if strings.HasPrefix(s.Address, "/") {
+		network = "unix"
+	}
+	dialer := net.Dialer{Timeout: 10 * time.Second}
+	conn, err := dialer.DialContext(ctx, network, s.Address)
+	if err != nil {
+		return fmt.Errorf("connect to clamd: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else {
+		_ = conn.SetDeadline(time.Now().Add(s.Timeout))
+	}
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return err
+	}
+	buf := make([]byte, 32*1024)
+	size := make([]byte, 4)
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := conn.Write(size); err != nil {
+				return err
+			}
+			if _, err := conn.Write(buf[:n]); err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err := conn.Write(size); err != nil {
+		return err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("read clamd reply: %w", err)
+	}
+	reply = strings.TrimRight(reply, "\x00\n")
+	switch {
+	case strings.HasSuffix(reply, "FOUND"):
+		return fmt.Errorf("%w: %s", ErrInfected, strings.TrimSpace(strings.TrimPrefix(reply, "stream:")))
+	case strings.HasSuffix(reply, "OK"):
+		return nil
+	default:
+		return fmt.Errorf("clamd: %s", reply)
+	}
+}
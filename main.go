@@ -1,11 +1,23 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+	_ "time/tzdata" // activity timezones must resolve without system zoneinfo
 
 	"ping-badge-be/internal/config"
 	"ping-badge-be/internal/database"
+	"ping-badge-be/internal/events"
+	"ping-badge-be/internal/jobs"
+	"ping-badge-be/internal/realtime"
+	"ping-badge-be/internal/repository"
 	"ping-badge-be/internal/router"
 
 	"github.com/joho/godotenv"
@@ -26,8 +38,36 @@ func main() {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Outbox dispatcher publishes committed domain events to consumers
+	dispatcher := events.NewDispatcher(repository.NewOutboxRepository(db), repository.NewUnitOfWork(db))
+
+	// Job runner executes queued and scheduled background work
+	runner := jobs.NewRunner(repository.NewJobRepository(db), cfg.JobWorkers)
+
+	// Realtime hub relays Postgres notifications to stream subscribers
+	hub := realtime.NewHub()
+	listener := realtime.NewListener(cfg.DatabaseURL, hub)
+
 	// Setup router
-	r := router.Setup(db, cfg)
+	r := router.Setup(db, cfg, dispatcher, runner, hub)
+
+	var background sync.WaitGroup
+	background.Add(3)
+	go func() {
+		defer background.Done()
+		dispatcher.Run(ctx)
+	}()
+	go func() {
+		defer background.Done()
+		runner.Run(ctx)
+	}()
+	go func() {
+		defer background.Done()
+		listener.Run(ctx)
+	}()
 
 	// Start server
 	port := os.Getenv("PORT")
@@ -35,8 +75,26 @@ func main() {
 		port = "8080"
 	}
 
-	log.Printf("Server starting on port %s", port)
-	if err := r.Run(":" + port); err != nil {
-		log.Fatal("Failed to start server:", err)
+	srv := &http.Server{Addr: ":" + port, Handler: r}
+	// Close open event streams so Shutdown does not wait on them
+	srv.RegisterOnShutdown(hub.Close)
+	go func() {
+		log.Printf("Server starting on port %s", port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Failed to start server:", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Println("Server shutdown failed:", err)
 	}
-}
\ No newline at end of file
+
+	// Wait for the dispatcher and in-flight jobs to finish
+	background.Wait()
+	log.Println("Shutdown complete")
+}